@@ -35,7 +35,7 @@ func (b *Bot) registerOutreach() error {
 		return fmt.Errorf("OUTREACH_HOST not set in environment")
 	}
 
-	_, err := b.api.RegisterImplementation(context.Background(), &sdk.OutreachRegisterRequest{
+	resp, err := b.api.RegisterImplementation(context.Background(), &sdk.OutreachRegisterRequest{
 		ClientId:     clientID,
 		ClientSecret: clientSecret,
 		CallbackUrl:  fmt.Sprintf("%s%s%s", host, API_PREFIX, OUTREACH_ENDPOINT),
@@ -44,6 +44,9 @@ func (b *Bot) registerOutreach() error {
 	if err != nil {
 		return fmt.Errorf("failed to register outreach implementation: %v", err)
 	}
+
+	// Outreach requests are signed with the key issued for this registration
+	b.outreachSigningKey = resp.SigningKey
 	return nil
 }
 
@@ -105,6 +108,13 @@ var idempotencyStore = make(map[string]bool)
 
 // OnOutreachMessage handles POST requests to send outreach messages via Discord DM
 func (b *Bot) OnOutreachMessage(c *gin.Context) {
+	// Reject requests that weren't signed by the outreach service
+	if err := sdk.VerifyOutreachSignature(c.Request, b.outreachSigningKey); err != nil {
+		log.Printf("[DISCORD-OUTREACH]: Rejected outreach message: %v", err)
+		c.JSON(sdk.NewErrorResponse(http.StatusUnauthorized, "Invalid outreach signature", nil).AsGinResponse())
+		return
+	}
+
 	// Parse request body
 	var req sdk.OutreachRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -123,8 +133,11 @@ func (b *Bot) OnOutreachMessage(c *gin.Context) {
 	}
 	idempotencyStore[req.Id] = false
 
-	// Get the user ID from bot
+	// Send to the user the task is for, such as a reminder's owner, falling back to the configured user
 	userID := b.userID
+	if paramUserID, ok := req.Params["user_id"].(string); ok && paramUserID != "" {
+		userID = paramUserID
+	}
 	if userID == "" {
 		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "USER_ID not configured in environment", nil).AsGinResponse())
 		return
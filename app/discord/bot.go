@@ -41,7 +41,9 @@ type Bot struct {
 	threadChannelID           string // Channel ID where conversation threads are created
 	threadChannelContextLimit int    // Limit for thread context messages
 	guildID                   string // Guild ID for slash commands (empty for global)
-	userID                    string // User ID for outreach messages
+	userID                    string // User ID for outreach messages that aren't for a specific user
+
+	outreachSigningKey []byte // Key outreach requests are signed with, issued when registering
 }
 
 // Create a new Discord bot instance
@@ -2,14 +2,13 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/ethanbaker/assistant/internal/stores/database"
 	"github.com/ethanbaker/assistant/pkg/utils"
-	"github.com/go-sql-driver/mysql"
 )
 
 func main() {
@@ -22,20 +21,19 @@ func main() {
 	// Load global config
 	cfg := utils.NewConfigFromEnv(envFile)
 
-	// Construct mysql DSN
-	mysqlConfig := mysql.Config{
-		User:      cfg.Get("MYSQL_USERNAME"),
-		Passwd:    cfg.Get("MYSQL_ROOT_PASSWORD"),
-		Net:       "tcp",
-		Addr:      fmt.Sprintf("%s:%s", cfg.Get("MYSQL_HOST"), cfg.Get("MYSQL_PORT")),
-		DBName:    cfg.Get("MYSQL_DATABASE"),
-		ParseTime: true,
-	}
-
-	// Create SQL store
-	store, err := NewSqlStore(mysqlConfig.FormatDSN())
-	if err != nil {
-		log.Fatalf("[DISCORD]: failed to create SQL store: %v", err)
+	// Create the conversation store
+	var store ConversationStore
+	if databaseURL := database.URLFromConfig(cfg); databaseURL != "" {
+		// Create SQL store
+		sqlStore, err := NewSqlStore(databaseURL)
+		if err != nil {
+			log.Fatalf("[DISCORD]: failed to create SQL store: %v", err)
+		}
+		store = sqlStore
+	} else {
+		// Fallback to in-memory store
+		log.Println("[DISCORD]: Warning, DATABASE_URL not set, using in-memory store (conversations will not persist across restarts)")
+		store = NewInMemoryStore()
 	}
 
 	// Wait for interrupt signal to gracefully shut down the bot
@@ -3,7 +3,7 @@ package main
 import (
 	"log"
 
-	"gorm.io/driver/mysql"
+	"github.com/ethanbaker/assistant/internal/stores/database"
 	"gorm.io/gorm"
 )
 
@@ -51,9 +51,9 @@ type SqlStore struct {
 	db *gorm.DB
 }
 
-// NewSqlStore initializes a new SqlStore
-func NewSqlStore(dsn string) (*SqlStore, error) {
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+// NewSqlStore initializes a new SqlStore connected to the database URL (MySQL or SQLite)
+func NewSqlStore(databaseURL string) (*SqlStore, error) {
+	db, err := database.Open(databaseURL)
 	if err != nil {
 		return nil, err
 	}
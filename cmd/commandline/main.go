@@ -9,11 +9,12 @@ import (
 	"strings"
 
 	"github.com/ethanbaker/assistant/internal/agents/overseer"
+	"github.com/ethanbaker/assistant/internal/stores/database"
 	"github.com/ethanbaker/assistant/internal/stores/memory"
 	"github.com/ethanbaker/assistant/internal/stores/session"
+	"github.com/ethanbaker/assistant/internal/stores/vector"
 	"github.com/ethanbaker/assistant/pkg/agent"
 	"github.com/ethanbaker/assistant/pkg/utils"
-	"github.com/go-sql-driver/mysql"
 	"github.com/nlpodyssey/openai-agents-go/agents"
 )
 
@@ -36,27 +37,51 @@ func main() {
 	// Load global config
 	cfg := utils.NewConfigFromEnv(envFile)
 
-	// Create MySQL config
-	dbConfig := mysql.Config{
-		User:      cfg.Get("MYSQL_USERNAME"),
-		Passwd:    cfg.Get("MYSQL_ROOT_PASSWORD"),
-		Net:       "tcp",
-		Addr:      fmt.Sprintf("%s:%s", cfg.Get("MYSQL_HOST"), cfg.Get("MYSQL_PORT")),
-		DBName:    cfg.Get("MYSQL_DATABASE"),
-		ParseTime: true,
+	// Find the database that stores sessions and facts
+	databaseURL := database.URLFromConfig(cfg)
+	if databaseURL == "" {
+		log.Fatal("[COMMANDLINE]: DATABASE_URL not set in environment")
 	}
 
 	// Initialize database connections to create stores
-	memoryStore, err := memory.NewStore(dbConfig.FormatDSN())
+	memoryStore, err := memory.NewStore(databaseURL)
 	if err != nil {
 		log.Fatalf("[COMMANDLINE]: Failed to initialize memory store: %v", err)
 	}
 
-	sessionStore, err := session.NewMySqlStore(dbConfig.FormatDSN())
+	sessionStore, err := session.NewMySqlStore(databaseURL)
 	if err != nil {
 		log.Fatalf("[COMMANDLINE]: Failed to initialize session store: %v", err)
 	}
 
+	// Give facts stored before facts were scoped per user to the default user
+	if defaultUser := cfg.Get("MEMORY_DEFAULT_USER_ID"); defaultUser != "" {
+		if _, err := memoryStore.AssignUnownedFacts(context.Background(), defaultUser); err != nil {
+			log.Fatalf("[COMMANDLINE]: Failed to assign existing facts to default user: %v", err)
+		}
+	}
+
+	// Create the vector index used for semantic search
+	if kind := cfg.GetWithDefault("EMBEDDER", "openai"); kind != "none" {
+		embedder, err := vector.NewEmbedder(kind, cfg.Get("EMBEDDING_MODEL"))
+		if err != nil {
+			log.Fatalf("[COMMANDLINE]: Failed to initialize embedder: %v", err)
+		}
+
+		index, err := vector.NewSqlIndex(sessionStore.GetDB(), embedder)
+		if err != nil {
+			log.Fatalf("[COMMANDLINE]: Failed to initialize vector index: %v", err)
+		}
+
+		memoryStore.SetIndex(index)
+		sessionStore.SetIndex(index)
+
+		// Make sure facts stored before the index existed can be found
+		if err := memoryStore.IndexFacts(context.Background()); err != nil {
+			log.Printf("[COMMANDLINE]: Failed to index existing facts: %v", err)
+		}
+	}
+
 	// Create overseer agent
 	overseer, err := overseer.NewOverseerAgent(memoryStore, sessionStore, cfg)
 	if err != nil {
@@ -134,6 +159,10 @@ func executeAgentCall(ctx context.Context, sess session.Session, input string) (
 		},
 	}
 
+	// Let tools know which user and session the run is for
+	ctx = agent.WithUserID(ctx, sess.GetUserID())
+	ctx = agent.WithSessionID(ctx, sess.SessionID(ctx))
+
 	// Execute agent call
 	response, err := runner.Run(ctx, orchestrator.overseer.Agent(), input)
 	if err != nil {
@@ -31,8 +31,48 @@ curl -X POST http://localhost:8080/api/agent/sessions/c0fdd425-ff3d-4cbe-86c6-98
     "content": "What does my calendar look like tomorrow?"
   }'
 
+# Add message to session and stream the response
+: "
+curl -N -X POST http://localhost:8080/api/agent/sessions/c0fdd425-ff3d-4cbe-86c6-9885e1fdddbc/message/stream \
+  -H "Content-Type: application/json" \
+  -H "X-API-KEY: $API_KEY" \
+  -d '{
+    "content": "What does my calendar look like tomorrow?"
+  }'
+"
+
 # Delete session
 : "
 curl -X DELETE http://localhost:8080/api/agent/sessions/550e8400-e29b-41d4-a716-446655440000 \
   -H "X-API-KEY: $API_KEY"
-  "
\ No newline at end of file
+  "
+# Create a fact
+: "
+curl -X POST http://localhost:8080/api/memory/facts \
+  -H "Content-Type: application/json" \
+  -H "X-API-KEY: $API_KEY" \
+  -d '{
+    "user_id": "user-1",
+    "key": "favorite_color",
+    "value": "green",
+    "tags": ["preferences"]
+  }'
+"
+
+# List facts with a tag
+: "
+curl -X GET 'http://localhost:8080/api/memory/facts?user_id=user-1&tag=preferences' \
+  -H "X-API-KEY: $API_KEY"
+"
+
+# Export facts
+: "
+curl -X GET 'http://localhost:8080/api/memory/export?user_id=user-1' \
+  -H "X-API-KEY: $API_KEY"
+"
+
+# Show a fact's history
+: "
+curl -X GET 'http://localhost:8080/api/memory/facts/favorite_color/history?user_id=user-1' \
+  -H "X-API-KEY: $API_KEY"
+"
@@ -74,6 +74,20 @@ func (ma *MemoryAgent) ShouldDryRun(ctx context.Context) bool {
 	return ma.config.GetBool("DRY_RUN")
 }
 
+// userID returns the user whose facts the agent works with. It comes from the run context,
+// falling back to MEMORY_DEFAULT_USER_ID for runs that aren't tied to a user.
+func (ma *MemoryAgent) userID(ctx context.Context) (string, error) {
+	if userID, ok := agent.UserIDFromContext(ctx); ok {
+		return userID, nil
+	}
+
+	if userID := ma.config.Get("MEMORY_DEFAULT_USER_ID"); userID != "" {
+		return userID, nil
+	}
+
+	return "", errors.New("no user is associated with this conversation")
+}
+
 // getPrompt returns the prompt for the agent
 func (ma *MemoryAgent) getPrompt(ctx context.Context, a *agents.Agent) (string, error) {
 	now := time.Now()
@@ -4,8 +4,15 @@ package memory
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/ethanbaker/assistant/internal/stores/memory"
+	"github.com/ethanbaker/assistant/internal/stores/session"
+	"github.com/ethanbaker/assistant/internal/stores/vector"
+	"github.com/ethanbaker/assistant/pkg/agent"
 	"github.com/nlpodyssey/openai-agents-go/agents"
 	"github.com/openai/openai-go/v2/packages/param"
 )
@@ -15,7 +22,7 @@ func (ma *MemoryAgent) registerTools() {
 	// Search session transcripts tool
 	searchTool := agents.FunctionTool{
 		Name:        "search_sessions",
-		Description: "Search through past conversation transcripts",
+		Description: "Search through the user's past conversation transcripts",
 		ParamsJSONSchema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -59,7 +66,7 @@ func (ma *MemoryAgent) registerTools() {
 	// Set fact tool
 	setFactTool := agents.FunctionTool{
 		Name:        "set_fact",
-		Description: "Store or update a fact",
+		Description: "Store or update a fact, optionally with tags and an expiry time",
 		ParamsJSONSchema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -71,9 +78,18 @@ func (ma *MemoryAgent) registerTools() {
 					"type":        "string",
 					"description": "The value of the fact",
 				},
+				"tags": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Tags used to group the fact, such as 'preferences' or 'health' (optional, empty for none)",
+				},
+				"expires_at": map[string]any{
+					"type":        "string",
+					"description": "When the fact should be forgotten, in RFC3339 format (optional, empty to keep forever)",
+				},
 			},
 			"additionalProperties": false,
-			"required":             []string{"key", "value"},
+			"required":             []string{"key", "value", "tags", "expires_at"},
 		},
 		StrictJSONSchema: param.NewOpt(true),
 		OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
@@ -82,27 +98,176 @@ func (ma *MemoryAgent) registerTools() {
 		IsEnabled: agents.FunctionToolEnabled(),
 	}
 
+	// Delete fact tool
+	deleteFactTool := agents.FunctionTool{
+		Name:        "delete_fact",
+		Description: "Forget a stored fact by key",
+		ParamsJSONSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"key": map[string]any{
+					"type":        "string",
+					"description": "The key of the fact to forget",
+				},
+			},
+			"additionalProperties": false,
+			"required":             []string{"key"},
+		},
+		StrictJSONSchema: param.NewOpt(true),
+		OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
+			return ma.handleDeleteFact(ctx, arguments)
+		},
+		IsEnabled: agents.FunctionToolEnabled(),
+	}
+
+	// Search facts tool
+	searchFactsTool := agents.FunctionTool{
+		Name:        "search_facts",
+		Description: "Search stored facts whose key or value contains the query text",
+		ParamsJSONSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Text to look for in fact keys and values",
+				},
+			},
+			"additionalProperties": false,
+			"required":             []string{"query"},
+		},
+		StrictJSONSchema: param.NewOpt(true),
+		OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
+			return ma.handleSearchFacts(ctx, arguments)
+		},
+		IsEnabled: agents.FunctionToolEnabled(),
+	}
+
 	// List facts tool
 	listFactsTool := agents.FunctionTool{
 		Name:        "list_facts",
-		Description: "List all stored facts",
+		Description: "List all stored facts, optionally only those with a tag",
 		ParamsJSONSchema: map[string]any{
-			"type":                 "object",
-			"properties":           map[string]any{},
+			"type": "object",
+			"properties": map[string]any{
+				"tag": map[string]any{
+					"type":        "string",
+					"description": "Only list facts with this tag (optional, empty for all facts)",
+				},
+			},
 			"additionalProperties": false,
+			"required":             []string{"tag"},
 		},
+		StrictJSONSchema: param.NewOpt(true),
 		OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
 			return ma.handleListFacts(ctx, arguments)
 		},
 		IsEnabled: agents.FunctionToolEnabled(),
 	}
 
+	// Semantic fact search tool
+	searchFactsSemanticTool := agents.FunctionTool{
+		Name:        "search_facts_semantic",
+		Description: "Search stored facts by meaning, finding facts related to the query even if they are worded differently",
+		ParamsJSONSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Natural language description of the facts to find",
+				},
+			},
+			"additionalProperties": false,
+			"required":             []string{"query"},
+		},
+		StrictJSONSchema: param.NewOpt(true),
+		OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
+			return ma.handleSearchFactsSemantic(ctx, arguments)
+		},
+		IsEnabled: agents.FunctionToolEnabled(),
+	}
+
+	// Semantic session search tool
+	searchSessionsSemanticTool := agents.FunctionTool{
+		Name:        "search_sessions_semantic",
+		Description: "Search past conversation messages by meaning, finding messages related to the query even if they are worded differently",
+		ParamsJSONSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Natural language description of the conversations to find",
+				},
+			},
+			"additionalProperties": false,
+			"required":             []string{"query"},
+		},
+		StrictJSONSchema: param.NewOpt(true),
+		OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
+			return ma.handleSearchSessionsSemantic(ctx, arguments)
+		},
+		IsEnabled: agents.FunctionToolEnabled(),
+	}
+
+	// Fact history tool
+	factHistoryTool := agents.FunctionTool{
+		Name:        "get_fact_history",
+		Description: "Show how a fact has changed over time, including deleted values and revision IDs",
+		ParamsJSONSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"key": map[string]any{
+					"type":        "string",
+					"description": "The key of the fact",
+				},
+			},
+			"additionalProperties": false,
+			"required":             []string{"key"},
+		},
+		StrictJSONSchema: param.NewOpt(true),
+		OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
+			return ma.handleGetFactHistory(ctx, arguments)
+		},
+		IsEnabled: agents.FunctionToolEnabled(),
+	}
+
+	// Revert fact tool
+	revertFactTool := agents.FunctionTool{
+		Name:        "revert_fact",
+		Description: "Undo a change to a fact, restoring the value it had before the given revision",
+		ParamsJSONSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"key": map[string]any{
+					"type":        "string",
+					"description": "The key of the fact",
+				},
+				"revision_id": map[string]any{
+					"type":        "integer",
+					"description": "The ID of the revision to undo, from get_fact_history",
+				},
+			},
+			"additionalProperties": false,
+			"required":             []string{"key", "revision_id"},
+		},
+		StrictJSONSchema: param.NewOpt(true),
+		OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
+			return ma.handleRevertFact(ctx, arguments)
+		},
+		IsEnabled: agents.FunctionToolEnabled(),
+	}
+
 	// Add tools to agent
 	ma.agent.Tools = []agents.Tool{
 		searchTool,
 		getFactTool,
 		setFactTool,
+		deleteFactTool,
+		searchFactsTool,
 		listFactsTool,
+		factHistoryTool,
+		revertFactTool,
+		searchFactsSemanticTool,
+		searchSessionsSemanticTool,
 	}
 }
 
@@ -121,8 +286,13 @@ func (ma *MemoryAgent) handleSearchSessions(ctx context.Context, arguments strin
 		return "", fmt.Errorf("failed to parse arguments: %w", err)
 	}
 
-	// Search session transcripts with the provided query
-	transcripts, err := ma.sessionStore.SearchSessionTranscripts(ctx, args.Query)
+	userID, err := ma.userID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Search the user's session transcripts with the provided query
+	transcripts, err := ma.sessionStore.SearchSessionTranscripts(ctx, userID, args.Query)
 	if err != nil {
 		return "", fmt.Errorf("failed to search sessions: %w", err)
 	}
@@ -161,8 +331,13 @@ func (ma *MemoryAgent) handleGetFact(ctx context.Context, arguments string) (str
 		return "", fmt.Errorf("failed to parse arguments: %w", err)
 	}
 
+	userID, err := ma.userID(ctx)
+	if err != nil {
+		return "", err
+	}
+
 	// Retrieve the fact from memory store
-	fact, err := ma.memoryStore.GetFact(ctx, args.Key)
+	fact, err := ma.memoryStore.GetFact(ctx, userID, args.Key)
 	if err != nil {
 		return "", fmt.Errorf("failed to get fact: %w", err)
 	}
@@ -184,47 +359,351 @@ func (ma *MemoryAgent) handleSetFact(ctx context.Context, arguments string) (str
 
 	// Unmarshal the arguments
 	var args struct {
-		Key   string `json:"key"`
-		Value string `json:"value"`
+		Key       string   `json:"key"`
+		Value     string   `json:"value"`
+		Tags      []string `json:"tags"`
+		ExpiresAt string   `json:"expires_at"`
 	}
 
 	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
 		return "", fmt.Errorf("failed to parse arguments: %w", err)
 	}
 
+	userID, err := ma.userID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	fact := memory.NewKeyFact(userID, args.Key, args.Value)
+	fact.Tags = memory.NewFactTags(args.Tags...)
+	fact.SourceSessionID, _ = agent.SessionIDFromContext(ctx)
+
+	// Parse the optional expiry time
+	if args.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, args.ExpiresAt)
+		if err != nil {
+			return "", fmt.Errorf("invalid expires_at, expected RFC3339 format: %w", err)
+		}
+		if !expiresAt.After(time.Now()) {
+			return "", fmt.Errorf("expires_at must be in the future")
+		}
+
+		expiresAt = expiresAt.UTC()
+		fact.ExpiresAt = &expiresAt
+	}
+
 	// Store the fact in memory store
-	if err := ma.memoryStore.SetFact(ctx, args.Key, args.Value); err != nil {
+	if err := ma.memoryStore.SetFact(ctx, fact); err != nil {
 		return "", fmt.Errorf("failed to set fact: %w", err)
 	}
 
-	return fmt.Sprintf("Successfully stored fact '%s': %s", args.Key, args.Value), nil
+	return fmt.Sprintf("Successfully stored fact %s", formatFact(fact)), nil
+}
+
+// handleDeleteFact handles forgetting a fact
+func (ma *MemoryAgent) handleDeleteFact(ctx context.Context, arguments string) (string, error) {
+	if ma.ShouldDryRun(ctx) {
+		return fmt.Sprintf("DRY RUN: Would delete fact with arguments: %s", arguments), nil
+	}
+
+	// Unmarshal the arguments
+	var args struct {
+		Key string `json:"key"`
+	}
+
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	userID, err := ma.userID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Make sure the fact exists so the agent gets a clear answer
+	fact, err := ma.memoryStore.GetFact(ctx, userID, args.Key)
+	if err != nil {
+		return "", fmt.Errorf("failed to get fact: %w", err)
+	}
+	if fact == nil {
+		return fmt.Sprintf("No fact found for key: %s", args.Key), nil
+	}
+
+	// Delete the fact from memory store
+	sessionID, _ := agent.SessionIDFromContext(ctx)
+	if err := ma.memoryStore.DeleteFact(ctx, userID, args.Key, sessionID); err != nil {
+		return "", fmt.Errorf("failed to delete fact: %w", err)
+	}
+
+	return fmt.Sprintf("Successfully forgot fact '%s'", args.Key), nil
+}
+
+// handleSearchFacts handles searching facts by text
+func (ma *MemoryAgent) handleSearchFacts(ctx context.Context, arguments string) (string, error) {
+	if ma.ShouldDryRun(ctx) {
+		return fmt.Sprintf("DRY RUN: Would search facts with arguments: %s", arguments), nil
+	}
+
+	// Unmarshal the arguments
+	var args struct {
+		Query string `json:"query"`
+	}
+
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	userID, err := ma.userID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Search facts from memory store
+	facts, err := ma.memoryStore.SearchFacts(ctx, userID, args.Query)
+	if err != nil {
+		return "", fmt.Errorf("failed to search facts: %w", err)
+	}
+
+	if len(facts) == 0 {
+		return fmt.Sprintf("No facts found matching: %s", args.Query), nil
+	}
+
+	// Format the results
+	result := fmt.Sprintf("Found %d matching facts:\n", len(facts))
+	for _, fact := range facts {
+		result += fmt.Sprintf("- %s\n", formatFact(fact))
+	}
+
+	return result, nil
 }
 
 // handleListFacts handles listing all facts
-func (ma *MemoryAgent) handleListFacts(ctx context.Context, _ string) (string, error) {
+func (ma *MemoryAgent) handleListFacts(ctx context.Context, arguments string) (string, error) {
 	if ma.ShouldDryRun(ctx) {
-		return "DRY RUN: Would list all facts", nil
+		return fmt.Sprintf("DRY RUN: Would list facts with arguments: %s", arguments), nil
+	}
+
+	// Unmarshal the arguments
+	var args struct {
+		Tag string `json:"tag"`
+	}
+
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
 	}
 
-	// List all facts from memory store
-	facts, err := ma.memoryStore.ListAllFacts(ctx)
+	userID, err := ma.userID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// List facts from memory store
+	facts, err := ma.memoryStore.ListFactsByTag(ctx, userID, args.Tag)
 	if err != nil {
 		return "", fmt.Errorf("failed to list facts: %w", err)
 	}
 
 	if len(facts) == 0 {
+		if args.Tag != "" {
+			return fmt.Sprintf("No facts stored with tag '%s'.", args.Tag), nil
+		}
 		return "No facts stored yet.", nil
 	}
 
 	// Format the results
 	result := fmt.Sprintf("Stored facts (%d total):\n", len(facts))
 	for _, fact := range facts {
-		result += fmt.Sprintf("- %s: %s\n", fact.Key, fact.Value)
+		result += fmt.Sprintf("- %s\n", formatFact(fact))
+	}
+
+	return result, nil
+}
+
+// handleSearchFactsSemantic handles searching facts by meaning
+func (ma *MemoryAgent) handleSearchFactsSemantic(ctx context.Context, arguments string) (string, error) {
+	if ma.ShouldDryRun(ctx) {
+		return fmt.Sprintf("DRY RUN: Would semantically search facts with arguments: %s", arguments), nil
+	}
+
+	// Unmarshal the arguments
+	var args struct {
+		Query string `json:"query"`
+	}
+
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	userID, err := ma.userID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Search facts with the provided query
+	facts, err := ma.memoryStore.SearchFactsSemantic(ctx, userID, args.Query, vector.DEFAULT_SEARCH_LIMIT)
+	if errors.Is(err, memory.ErrSemanticSearchDisabled) {
+		return "Semantic search is not enabled. Use list_facts or get_fact instead.", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to search facts: %w", err)
+	}
+
+	if len(facts) == 0 {
+		return "No related facts found.", nil
+	}
+
+	// Format the results
+	result := fmt.Sprintf("Found %d related facts:\n", len(facts))
+	for _, fact := range facts {
+		result += fmt.Sprintf("- %s: %s (similarity %.2f)\n", fact.Key, fact.Value, fact.Score)
+	}
+
+	return result, nil
+}
+
+// handleSearchSessionsSemantic handles searching session messages by meaning
+func (ma *MemoryAgent) handleSearchSessionsSemantic(ctx context.Context, arguments string) (string, error) {
+	if ma.ShouldDryRun(ctx) {
+		return fmt.Sprintf("DRY RUN: Would semantically search sessions with arguments: %s", arguments), nil
+	}
+
+	// Unmarshal the arguments
+	var args struct {
+		Query string `json:"query"`
+	}
+
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	// Search session messages with the provided query
+	transcripts, err := ma.sessionStore.SearchSessionsSemantic(ctx, args.Query, vector.DEFAULT_SEARCH_LIMIT)
+	if errors.Is(err, session.ErrSemanticSearchDisabled) {
+		return "Semantic search is not enabled. Use search_sessions instead.", nil
+	} else if errors.Is(err, session.ErrSearchUserRequired) {
+		return "Semantic search needs to know which user's conversations to search, and no user is set for this conversation.", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to search sessions: %w", err)
+	}
+
+	if len(transcripts) == 0 {
+		return "No related conversations found.", nil
+	}
+
+	// Format the results
+	result := fmt.Sprintf("Found %d related conversation messages:\n", len(transcripts))
+	for i, transcript := range transcripts {
+		result += fmt.Sprintf("\n%d. [%s] %s", i+1, transcript.CreatedAt.Format("2006-01-02 15:04"), transcript.Data[:min(200, len(transcript.Data))])
+		if len(transcript.Data) > 200 {
+			result += "..."
+		}
+	}
+
+	return result, nil
+}
+
+// handleGetFactHistory handles listing the revisions of a fact
+func (ma *MemoryAgent) handleGetFactHistory(ctx context.Context, arguments string) (string, error) {
+	if ma.ShouldDryRun(ctx) {
+		return fmt.Sprintf("DRY RUN: Would get fact history with arguments: %s", arguments), nil
+	}
+
+	// Unmarshal the arguments
+	var args struct {
+		Key string `json:"key"`
+	}
+
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	userID, err := ma.userID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Get the history from memory store
+	revisions, err := ma.memoryStore.GetFactHistory(ctx, userID, args.Key)
+	if err != nil {
+		return "", fmt.Errorf("failed to get fact history: %w", err)
+	}
+
+	if len(revisions) == 0 {
+		return fmt.Sprintf("No history found for key: %s", args.Key), nil
+	}
+
+	// Format the results, newest first
+	result := fmt.Sprintf("History of '%s' (%d revisions, newest first):\n", args.Key, len(revisions))
+	for _, revision := range revisions {
+		result += fmt.Sprintf("- Revision %d (%s, %s): %s -> %s\n",
+			revision.ID,
+			revision.Operation,
+			revision.CreatedAt.Format(time.RFC3339),
+			formatRevisionValue(revision.PreviousValue),
+			formatRevisionValue(revision.NewValue),
+		)
 	}
 
 	return result, nil
 }
 
+// handleRevertFact handles undoing a revision of a fact
+func (ma *MemoryAgent) handleRevertFact(ctx context.Context, arguments string) (string, error) {
+	if ma.ShouldDryRun(ctx) {
+		return fmt.Sprintf("DRY RUN: Would revert fact with arguments: %s", arguments), nil
+	}
+
+	// Unmarshal the arguments
+	var args struct {
+		Key        string `json:"key"`
+		RevisionID uint   `json:"revision_id"`
+	}
+
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	userID, err := ma.userID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Revert the fact in memory store
+	sessionID, _ := agent.SessionIDFromContext(ctx)
+	fact, err := ma.memoryStore.RevertFact(ctx, userID, args.Key, args.RevisionID, sessionID)
+	if err != nil {
+		if errors.Is(err, memory.ErrFactNotFound) {
+			return fmt.Sprintf("No revision %d found for key: %s", args.RevisionID, args.Key), nil
+		}
+		return "", fmt.Errorf("failed to revert fact: %w", err)
+	}
+
+	if fact == nil {
+		return fmt.Sprintf("Reverted '%s': the fact did not exist before revision %d, so it was removed", args.Key, args.RevisionID), nil
+	}
+
+	return fmt.Sprintf("Reverted fact %s", formatFact(fact)), nil
+}
+
+// formatRevisionValue formats a value from a fact's history, which is nil when the fact didn't exist
+func formatRevisionValue(value *string) string {
+	if value == nil {
+		return "(none)"
+	}
+	return fmt.Sprintf("%q", *value)
+}
+
+// formatFact formats a fact with its tags and expiry time for the agent
+func formatFact(fact *memory.KeyFact) string {
+	result := fmt.Sprintf("'%s': %s", fact.Key, fact.Value)
+	if len(fact.Tags) > 0 {
+		result += fmt.Sprintf(" [tags: %s]", strings.Join(fact.Tags, ", "))
+	}
+	if fact.ExpiresAt != nil {
+		result += fmt.Sprintf(" (expires %s)", fact.ExpiresAt.Format(time.RFC3339))
+	}
+	return result
+}
+
 // min returns the minimum of two integers
 func min(a, b int) int {
 	if a < b {
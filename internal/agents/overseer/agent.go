@@ -6,11 +6,14 @@ import (
 
 	communicationagent "github.com/ethanbaker/assistant/internal/agents/communication"
 	memoryagent "github.com/ethanbaker/assistant/internal/agents/memory"
+	reminderagent "github.com/ethanbaker/assistant/internal/agents/reminder"
 	scheduleagent "github.com/ethanbaker/assistant/internal/agents/schedule"
 	searchagent "github.com/ethanbaker/assistant/internal/agents/search"
 	taskagent "github.com/ethanbaker/assistant/internal/agents/task"
 	"github.com/ethanbaker/assistant/internal/stores/memory"
 	"github.com/ethanbaker/assistant/internal/stores/session"
+	"github.com/ethanbaker/assistant/internal/telemetry"
+	"github.com/ethanbaker/assistant/pkg/agent"
 	"github.com/ethanbaker/assistant/pkg/utils"
 	"github.com/nlpodyssey/openai-agents-go/agents"
 )
@@ -51,6 +54,11 @@ func NewOverseerAgent(memoryStore *memory.Store, sessionStore session.Store, con
 		return nil, err
 	}
 
+	reminderAgent, err := reminderagent.NewReminderAgent(memoryStore, sessionStore, config)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create handoffs for each specialized agent
 	memoryHandoff := agents.HandoffFromAgent(agents.HandoffFromAgentParams{
 		Agent:                   memoryAgent.Agent(),
@@ -82,6 +90,12 @@ func NewOverseerAgent(memoryStore *memory.Store, sessionStore session.Store, con
 		ToolDescriptionOverride: "Hand off to the Schedule Agent for managing calendar events, scheduling meetings, or retrieving calendar information. Only hand off when specifically mentioning actions that involve calendars or scheduling",
 	})
 
+	reminderHandoff := agents.HandoffFromAgent(agents.HandoffFromAgentParams{
+		Agent:                   reminderAgent.Agent(),
+		ToolNameOverride:        "handoff_to_reminder_agent",
+		ToolDescriptionOverride: "Hand off to the Reminder Agent for creating, listing, or cancelling reminders that message the user at a set time (e.g. 'remind me at 5pm to call mom')",
+	})
+
 	// Get sysprompt path
 	path := config.Get("OVERSEER_SYSPROMPT_PATH")
 	if path == "" {
@@ -104,8 +118,15 @@ func NewOverseerAgent(memoryStore *memory.Store, sessionStore session.Store, con
 			searchHandoff,
 			taskHandoff,
 			scheduleHandoff,
+			reminderHandoff,
 		)
 
+	// Trace and measure the tools and handoffs of every agent the overseer can reach
+	for _, custom := range []agent.CustomAgent{memoryAgent, communicationAgent, searchAgent, taskAgent, scheduleAgent, reminderAgent} {
+		telemetry.InstrumentAgent(custom.Agent(), custom.ID())
+	}
+	telemetry.InstrumentAgent(agentInstance, "overseer-agent")
+
 	oa := &OverseerAgent{
 		agent:        agentInstance,
 		config:       config,
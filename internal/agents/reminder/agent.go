@@ -0,0 +1,95 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/memory"
+	"github.com/ethanbaker/assistant/internal/stores/session"
+	"github.com/ethanbaker/assistant/pkg/agent"
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/nlpodyssey/openai-agents-go/agents"
+)
+
+// DEFAULT_SYSPROMPT_PATH is used when REMINDER_SYSPROMPT_PATH isn't set
+const DEFAULT_SYSPROMPT_PATH = "resources/prompts/reminder-agent.txt"
+
+// ReminderAgent schedules one-time reminders that are delivered through outreach
+type ReminderAgent struct {
+	agent        *agents.Agent
+	config       *utils.Config
+	memoryStore  *memory.Store
+	sessionStore session.Store
+	basePrompt   string
+	timezone     *time.Location
+}
+
+// NewReminderAgent creates a new reminder agent
+func NewReminderAgent(memoryStore *memory.Store, sessionStore session.Store, config *utils.Config) (*ReminderAgent, error) {
+	var err error
+
+	ra := &ReminderAgent{
+		config:       config,
+		memoryStore:  memoryStore,
+		sessionStore: sessionStore,
+		timezone:     time.Local,
+	}
+
+	// Load timezone from config, used for reminder times without an offset
+	if tz := config.Get("TIMEZONE"); tz != "" {
+		ra.timezone, err = time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load timezone %s: %w", tz, err)
+		}
+	}
+
+	// Load instructions from file
+	path := config.GetWithDefault("REMINDER_SYSPROMPT_PATH", DEFAULT_SYSPROMPT_PATH)
+	ra.basePrompt, err = utils.LoadPrompt(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the underlying agent
+	ra.agent = agents.New("reminder-agent").
+		WithModel(config.Get("MODEL")).
+		WithInstructionsFunc(ra.getPrompt)
+
+	// Register tools
+	ra.registerTools()
+
+	return ra, nil
+}
+
+// Agent returns the underlying openai-agents-go instance
+func (ra *ReminderAgent) Agent() *agents.Agent {
+	return ra.agent
+}
+
+// ID returns the agent identifier
+func (ra *ReminderAgent) ID() string {
+	return "reminder-agent"
+}
+
+// Config returns the agent configuration
+func (ra *ReminderAgent) Config() *utils.Config {
+	return ra.config
+}
+
+// ShouldDryRun determines if the agent should run in dry-run mode
+func (ra *ReminderAgent) ShouldDryRun(ctx context.Context) bool {
+	return ra.config.GetBool("DRY_RUN")
+}
+
+// getPrompt returns the prompt for the agent
+func (ra *ReminderAgent) getPrompt(ctx context.Context, a *agents.Agent) (string, error) {
+	now := time.Now().In(ra.timezone)
+
+	builder := agent.NewPromptBuilder(ra.basePrompt)
+	builder.AddContext("Current time: " + now.Format("15:04:05 MST"))
+	builder.AddContext("Today's date: " + now.Format("Monday, 2006-01-02"))
+	builder.AddContext("Timezone: " + ra.timezone.String())
+
+	return builder.Build(), nil
+}
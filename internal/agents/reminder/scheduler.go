@@ -0,0 +1,37 @@
+package reminder
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethanbaker/assistant/pkg/outreach"
+)
+
+// Scheduler creates, lists, and cancels reminders. The outreach manager implements it.
+type Scheduler interface {
+	AddReminder(userID, clientID, message string, at time.Time) (*outreach.Reminder, error)
+	ListReminders(userID string) []outreach.Reminder
+	CancelReminder(userID, id string) error
+}
+
+var (
+	schedulerMutex sync.RWMutex
+	scheduler      Scheduler
+)
+
+// SetScheduler sets the scheduler reminder tools use. The outreach module starts after the agents,
+// so it provides the scheduler once it is running.
+func SetScheduler(s Scheduler) {
+	schedulerMutex.Lock()
+	defer schedulerMutex.Unlock()
+
+	scheduler = s
+}
+
+// getScheduler returns the current scheduler, or nil if outreach isn't running
+func getScheduler() Scheduler {
+	schedulerMutex.RLock()
+	defer schedulerMutex.RUnlock()
+
+	return scheduler
+}
@@ -0,0 +1,243 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethanbaker/assistant/pkg/agent"
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"github.com/openai/openai-go/v2/packages/param"
+)
+
+// LOCAL_TIME_FORMAT is accepted for reminder times given without a UTC offset
+const LOCAL_TIME_FORMAT = "2006-01-02T15:04"
+
+// registerTools registers all reminder tools
+func (ra *ReminderAgent) registerTools() {
+	ra.agent.WithTools(
+		ra.createCreateReminderTool(),
+		ra.createListRemindersTool(),
+		ra.createCancelReminderTool(),
+	)
+}
+
+/** ---- TOOL ARGUMENT STRUCTURES ---- **/
+
+type CreateReminderArgs struct {
+	Message string `json:"message"`
+	At      string `json:"at"` // RFC3339, or local time in LOCAL_TIME_FORMAT
+}
+
+type CancelReminderArgs struct {
+	ReminderID string `json:"reminder_id"`
+}
+
+/** ---- TOOL CREATORS ---- **/
+
+// createCreateReminderTool creates the create reminder tool
+func (ra *ReminderAgent) createCreateReminderTool() agents.FunctionTool {
+	return agents.FunctionTool{
+		Name:        "create_reminder",
+		Description: "Schedule a one-time reminder that is sent to the user at the given time",
+		ParamsJSONSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"message": map[string]any{
+					"type":        "string",
+					"description": "What to remind the user of, written as it should be sent (e.g. 'Call mom')",
+				},
+				"at": map[string]any{
+					"type":        "string",
+					"description": "When to send the reminder, as local time in YYYY-MM-DDTHH:MM format or as RFC3339",
+				},
+			},
+			"additionalProperties": false,
+			"required":             []string{"message", "at"},
+		},
+		StrictJSONSchema: param.NewOpt(true),
+		OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
+			return ra.handleCreateReminder(ctx, arguments)
+		},
+		IsEnabled: agents.FunctionToolEnabled(),
+	}
+}
+
+// createListRemindersTool creates the list reminders tool
+func (ra *ReminderAgent) createListRemindersTool() agents.FunctionTool {
+	return agents.FunctionTool{
+		Name:        "list_reminders",
+		Description: "List the user's pending reminders, soonest first",
+		ParamsJSONSchema: map[string]any{
+			"type":                 "object",
+			"properties":           map[string]any{},
+			"additionalProperties": false,
+		},
+		StrictJSONSchema: param.NewOpt(true),
+		OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
+			return ra.handleListReminders(ctx, arguments)
+		},
+		IsEnabled: agents.FunctionToolEnabled(),
+	}
+}
+
+// createCancelReminderTool creates the cancel reminder tool
+func (ra *ReminderAgent) createCancelReminderTool() agents.FunctionTool {
+	return agents.FunctionTool{
+		Name:        "cancel_reminder",
+		Description: "Cancel one of the user's pending reminders by its ID (from list_reminders)",
+		ParamsJSONSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"reminder_id": map[string]any{
+					"type":        "string",
+					"description": "ID of the reminder to cancel",
+				},
+			},
+			"additionalProperties": false,
+			"required":             []string{"reminder_id"},
+		},
+		StrictJSONSchema: param.NewOpt(true),
+		OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
+			return ra.handleCancelReminder(ctx, arguments)
+		},
+		IsEnabled: agents.FunctionToolEnabled(),
+	}
+}
+
+/** ---- TOOL HANDLERS ---- **/
+
+// handleCreateReminder processes the create reminder tool invocation
+func (ra *ReminderAgent) handleCreateReminder(ctx context.Context, arguments string) (string, error) {
+	var args CreateReminderArgs
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	message := strings.TrimSpace(args.Message)
+	if message == "" {
+		return "", fmt.Errorf("message is required")
+	}
+
+	at, err := ra.parseTime(args.At)
+	if err != nil {
+		return "", err
+	}
+
+	if ra.ShouldDryRun(ctx) {
+		return fmt.Sprintf("DRY RUN: Would create reminder '%s' at %s", message, at.Format(time.RFC1123)), nil
+	}
+
+	scheduler, userID, err := ra.scheduler(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	clientID, err := ra.clientID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	reminder, err := scheduler.AddReminder(userID, clientID, message, at)
+	if err != nil {
+		return "", fmt.Errorf("failed to create reminder: %w", err)
+	}
+
+	return fmt.Sprintf("Reminder '%s' set for %s (ID: %s)", reminder.Message, reminder.At.In(ra.timezone).Format(time.RFC1123), reminder.ID), nil
+}
+
+// handleListReminders processes the list reminders tool invocation
+func (ra *ReminderAgent) handleListReminders(ctx context.Context, arguments string) (string, error) {
+	scheduler, userID, err := ra.scheduler(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	reminders := scheduler.ListReminders(userID)
+	if len(reminders) == 0 {
+		return "No pending reminders.", nil
+	}
+
+	result := fmt.Sprintf("Found %d pending reminders:\n", len(reminders))
+	for _, reminder := range reminders {
+		result += fmt.Sprintf("- %s: '%s' (ID: %s)\n", reminder.At.In(ra.timezone).Format(time.RFC1123), reminder.Message, reminder.ID)
+	}
+
+	return result, nil
+}
+
+// handleCancelReminder processes the cancel reminder tool invocation
+func (ra *ReminderAgent) handleCancelReminder(ctx context.Context, arguments string) (string, error) {
+	var args CancelReminderArgs
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if args.ReminderID == "" {
+		return "", fmt.Errorf("reminder_id is required")
+	}
+
+	if ra.ShouldDryRun(ctx) {
+		return fmt.Sprintf("DRY RUN: Would cancel reminder %s", args.ReminderID), nil
+	}
+
+	scheduler, userID, err := ra.scheduler(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if err := scheduler.CancelReminder(userID, args.ReminderID); err != nil {
+		return "", fmt.Errorf("failed to cancel reminder: %w", err)
+	}
+
+	return fmt.Sprintf("Reminder %s cancelled", args.ReminderID), nil
+}
+
+/** ---- HELPERS ---- **/
+
+// scheduler returns the reminder scheduler and the user the run is for
+func (ra *ReminderAgent) scheduler(ctx context.Context) (Scheduler, string, error) {
+	scheduler := getScheduler()
+	if scheduler == nil {
+		return nil, "", fmt.Errorf("reminders are not available because outreach is not running")
+	}
+
+	userID, ok := agent.UserIDFromContext(ctx)
+	if !ok {
+		return nil, "", fmt.Errorf("no user to manage reminders for")
+	}
+
+	return scheduler, userID, nil
+}
+
+// clientID returns the implementation that delivers the user's reminders. It is the one the request's
+// API key is bound to, falling back to REMINDER_DEFAULT_CLIENT_ID
+func (ra *ReminderAgent) clientID(ctx context.Context) (string, error) {
+	if clientID, ok := agent.ClientIDFromContext(ctx); ok {
+		return clientID, nil
+	}
+
+	if clientID := ra.config.Get("REMINDER_DEFAULT_CLIENT_ID"); clientID != "" {
+		return clientID, nil
+	}
+
+	return "", fmt.Errorf("no implementation is set up to deliver reminders")
+}
+
+// parseTime reads a reminder time, treating times without an offset as local to the agent's timezone
+func (ra *ReminderAgent) parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+
+	at, err := time.ParseInLocation(LOCAL_TIME_FORMAT, value, ra.timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s', expected YYYY-MM-DDTHH:MM or RFC3339", value)
+	}
+
+	return at, nil
+}
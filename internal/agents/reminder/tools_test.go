@@ -0,0 +1,117 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/assistant/pkg/agent"
+	"github.com/ethanbaker/assistant/pkg/outreach"
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScheduler keeps reminders in memory
+type fakeScheduler struct {
+	reminders []outreach.Reminder
+}
+
+func (s *fakeScheduler) AddReminder(userID, clientID, message string, at time.Time) (*outreach.Reminder, error) {
+	reminder := outreach.Reminder{
+		ID:       fmt.Sprintf("reminder-%d", len(s.reminders)+1),
+		UserID:   userID,
+		ClientID: clientID,
+		Message:  message,
+		At:       at,
+	}
+	s.reminders = append(s.reminders, reminder)
+	return &reminder, nil
+}
+
+func (s *fakeScheduler) ListReminders(userID string) []outreach.Reminder {
+	reminders := []outreach.Reminder{}
+	for _, reminder := range s.reminders {
+		if reminder.UserID == userID {
+			reminders = append(reminders, reminder)
+		}
+	}
+	return reminders
+}
+
+func (s *fakeScheduler) CancelReminder(userID, id string) error {
+	for i, reminder := range s.reminders {
+		if reminder.ID == id && reminder.UserID == userID {
+			s.reminders = append(s.reminders[:i], s.reminders[i+1:]...)
+			return nil
+		}
+	}
+	return outreach.ErrReminderNotFound
+}
+
+func newTestReminderAgent(t *testing.T) (*ReminderAgent, *fakeScheduler) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	scheduler := &fakeScheduler{}
+	SetScheduler(scheduler)
+	t.Cleanup(func() { SetScheduler(nil) })
+
+	return &ReminderAgent{
+		config:   utils.NewConfig(map[string]string{}),
+		timezone: newYork,
+	}, scheduler
+}
+
+func TestReminderTools(t *testing.T) {
+	ra, scheduler := newTestReminderAgent(t)
+	ctx := agent.WithClientID(agent.WithUserID(context.Background(), "ethan"), "discord")
+
+	// Local times are read in the agent's timezone
+	result, err := ra.handleCreateReminder(ctx, `{"message": "Call mom", "at": "2030-05-01T17:00"}`)
+	require.NoError(t, err)
+	assert.Contains(t, result, "Call mom")
+
+	require.Len(t, scheduler.reminders, 1)
+	reminder := scheduler.reminders[0]
+	assert.Equal(t, "ethan", reminder.UserID)
+	assert.Equal(t, "discord", reminder.ClientID)
+	assert.Equal(t, time.Date(2030, time.May, 1, 21, 0, 0, 0, time.UTC), reminder.At.UTC())
+
+	result, err = ra.handleListReminders(ctx, `{}`)
+	require.NoError(t, err)
+	assert.Contains(t, result, reminder.ID)
+
+	// Other users don't see the reminder
+	result, err = ra.handleListReminders(agent.WithUserID(context.Background(), "sam"), `{}`)
+	require.NoError(t, err)
+	assert.Equal(t, "No pending reminders.", result)
+
+	_, err = ra.handleCancelReminder(ctx, fmt.Sprintf(`{"reminder_id": "%s"}`, reminder.ID))
+	require.NoError(t, err)
+	assert.Empty(t, scheduler.reminders)
+}
+
+func TestReminderToolsErrors(t *testing.T) {
+	ra, _ := newTestReminderAgent(t)
+	ctx := agent.WithUserID(context.Background(), "ethan")
+
+	// Reminders need an implementation to deliver them
+	_, err := ra.handleCreateReminder(ctx, `{"message": "Call mom", "at": "2030-05-01T17:00:00Z"}`)
+	assert.ErrorContains(t, err, "no implementation")
+
+	ctx = agent.WithClientID(ctx, "discord")
+	_, err = ra.handleCreateReminder(ctx, `{"message": "Call mom", "at": "5pm"}`)
+	assert.ErrorContains(t, err, "invalid time")
+	_, err = ra.handleCreateReminder(ctx, `{"message": " ", "at": "2030-05-01T17:00"}`)
+	assert.Error(t, err)
+
+	// Tools need a user and a running outreach service
+	_, err = ra.handleListReminders(context.Background(), `{}`)
+	assert.Error(t, err)
+
+	SetScheduler(nil)
+	_, err = ra.handleListReminders(ctx, `{}`)
+	assert.ErrorContains(t, err, "not available")
+}
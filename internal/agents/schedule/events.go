@@ -0,0 +1,38 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethanbaker/assistant/pkg/outreach"
+)
+
+// ListEvents returns the timed events in a range as outreach calendar events, so outreach tasks
+// can be scheduled relative to them. If calendarName is empty, all calendars are searched
+func (cs *CalendarService) ListEvents(ctx context.Context, start, end time.Time, calendarName string) ([]outreach.CalendarEvent, error) {
+	events, err := cs.GetEventsForTimeRange(ctx, start, end, calendarName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []outreach.CalendarEvent{}
+	for _, event := range events {
+		// All-day events have no start time to run before
+		if event.Start == nil || event.Start.DateTime == "" {
+			continue
+		}
+
+		eventStart, err := time.Parse(time.RFC3339, event.Start.DateTime)
+		if err != nil {
+			continue
+		}
+
+		result = append(result, outreach.CalendarEvent{
+			ID:    event.Id,
+			Title: event.Summary,
+			Start: eventStart,
+		})
+	}
+
+	return result, nil
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"log"
 	"strings"
 	"time"
@@ -11,14 +12,31 @@ import (
 	"github.com/gin-gonic/gin"
 
 	agent_module "github.com/ethanbaker/assistant/internal/api/modules/agent"
+	apikey_module "github.com/ethanbaker/assistant/internal/api/modules/apikey"
 	health_module "github.com/ethanbaker/assistant/internal/api/modules/health"
+	memory_module "github.com/ethanbaker/assistant/internal/api/modules/memory"
 	outreach_module "github.com/ethanbaker/assistant/internal/api/modules/outreach"
+	"github.com/ethanbaker/assistant/internal/stores/apikey"
+	"github.com/ethanbaker/assistant/internal/telemetry"
 )
 
 func Start(cfg *utils.Config) {
 	// Initialized configuration settings
 	port := cfg.GetWithDefault("API_PORT", "8080")
 
+	// Set up tracing before any module starts creating spans
+	shutdownTracing, err := telemetry.InitTracing(context.Background(), cfg)
+	if err != nil {
+		log.Fatal("[API-MAIN]: Failed to initialize tracing: ", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("[API-MAIN]: Failed to flush traces: %v", err)
+		}
+	}()
+
 	// Add app level settings/routes
 	engine := gin.Default()
 	engine.NoRoute(api_utils.NoRouteHandler)
@@ -36,17 +54,28 @@ func Start(cfg *utils.Config) {
 		MaxAge:           12 * time.Hour,
 	}))
 
+	// Prometheus metrics are served outside of the API group when turned on, to keys with the metrics:read scope
+	if cfg.GetBoolWithDefault("METRICS_ENABLED", false) {
+		engine.GET("/metrics", apikey_module.RequireScope(apikey.ScopeMetricsRead), gin.WrapH(telemetry.Handler()))
+	}
+
 	// Base group '/api' for all API routes
 	baseGroup := engine.Group("/api")
 
 	// Adding custom modules
 	health_module.RegisterRoutes(baseGroup)
 
+	apikey_module.RegisterRoutes(baseGroup)
+	apikey_module.Init(cfg)
+
 	agent_module.RegisterRoutes(baseGroup, cfg)
 	agent_module.Init(cfg)
 
+	memory_module.RegisterRoutes(baseGroup, cfg)
+	memory_module.Init(agent_module.GetOrchestrator().GetMemoryStore())
+
 	outreach_module.RegisterRoutes(baseGroup, cfg)
-	if err := outreach_module.Init(cfg); err != nil {
+	if err := outreach_module.Init(cfg, agent_module.GetOrchestrator().GetMemoryStore()); err != nil {
 		log.Fatal("[API-MAIN]: Failed to initialize outreach module: ", err)
 	}
 
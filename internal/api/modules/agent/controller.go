@@ -2,12 +2,18 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
+	apikey_module "github.com/ethanbaker/assistant/internal/api/modules/apikey"
+	runstore "github.com/ethanbaker/assistant/internal/stores/run"
 	"github.com/ethanbaker/assistant/internal/stores/session"
+	usagestore "github.com/ethanbaker/assistant/internal/stores/usage"
+	"github.com/ethanbaker/assistant/pkg/agent"
 	"github.com/ethanbaker/assistant/pkg/sdk"
 	"github.com/gin-gonic/gin"
+	"github.com/nlpodyssey/openai-agents-go/agents"
 )
 
 // CreateSession handles POST requests to create a new session
@@ -19,6 +25,15 @@ func CreateSession(c *gin.Context) {
 		return
 	}
 
+	// Sessions are always created for the acting user
+	if user := apikey_module.ActingUserID(c); user != "" {
+		if req.UserID != "" && req.UserID != user {
+			c.JSON(sdk.NewErrorResponse(http.StatusForbidden, "Can't create sessions for other users", nil).AsGinResponse())
+			return
+		}
+		req.UserID = user
+	}
+
 	// Create a new session using the orchestrator
 	orchestrator := GetOrchestrator()
 	session, err := orchestrator.NewSession(c.Request.Context(), req.UserID)
@@ -30,15 +45,58 @@ func CreateSession(c *gin.Context) {
 	c.JSON(sdk.NewSuccessResponse("Session created successfully", toSDKSession(session)).AsGinResponse())
 }
 
+// ListSessions handles GET requests to list sessions with optional filters and cursor pagination
+func ListSessions(c *gin.Context) {
+	// Parse query parameters
+	var req sdk.ListSessionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse query parameters", err).AsGinResponse())
+		return
+	}
+
+	// Only the acting user's sessions are listed
+	if user := apikey_module.ActingUserID(c); user != "" {
+		if req.UserID != "" && req.UserID != user {
+			c.JSON(sdk.NewErrorResponse(http.StatusForbidden, "Can't list sessions of other users", nil).AsGinResponse())
+			return
+		}
+		req.UserID = user
+	}
+
+	// List sessions using the orchestrator
+	orchestrator := GetOrchestrator()
+	page, err := orchestrator.ListSessions(c.Request.Context(), session.SessionFilter{
+		UserID:        req.UserID,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		UpdatedAfter:  req.UpdatedAfter,
+		UpdatedBefore: req.UpdatedBefore,
+		Cursor:        req.Cursor,
+		Limit:         req.Limit,
+	})
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Failed to list sessions", err).AsGinResponse())
+		return
+	}
+
+	resp := sdk.ListSessionsResponse{
+		Sessions:   []sdk.Session{},
+		NextCursor: page.NextCursor,
+	}
+	for _, sess := range page.Sessions {
+		resp.Sessions = append(resp.Sessions, toSDKSession(sess))
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Sessions retrieved successfully", resp).AsGinResponse())
+}
+
 // GetSession handles GET requests to retrieve an existing session by UUID
 func GetSession(c *gin.Context) {
 	uuid := c.Param("uuid")
 
 	// Retrieve the session using the orchestrator
-	orchestrator := GetOrchestrator()
-	session, err := orchestrator.FindSession(c.Request.Context(), uuid)
-	if err != nil {
-		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Session not found", err).AsGinResponse())
+	session, ok := findSession(c, uuid)
+	if !ok {
 		return
 	}
 
@@ -58,48 +116,25 @@ func PostMessage(c *gin.Context) {
 
 	orchestrator := GetOrchestrator()
 
-	// Validate session exists
-	_, err := orchestrator.FindSession(c.Request.Context(), uuid)
-	if err != nil {
-		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Session not found", err).AsGinResponse())
-		return
-	}
-
-	// Record current item count
-	count, err := orchestrator.GetItemCountBySessionID(c.Request.Context(), uuid)
-	if err != nil {
-		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to get item count", err).AsGinResponse())
+	// Validate session exists and its user has tokens left
+	sess, ok := findSession(c, uuid)
+	if !ok || !checkBudget(c, sess.GetUserID()) {
 		return
 	}
 
 	// Add the message to the session using the orchestrator
-	msg, err := orchestrator.AddMessage(c.Request.Context(), uuid, req)
+	msg, items, err := orchestrator.AddMessage(runContext(c), apikey_module.ActingUserID(c), uuid, req)
 	if err != nil {
 		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to add message", err).AsGinResponse())
 		return
 	}
 
-	// Get new item count
-	newCount, err := orchestrator.GetItemCountBySessionID(c.Request.Context(), uuid)
-	if err != nil {
-		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to get new item count", err).AsGinResponse())
-		return
-	}
-	newCount-- // Adjust for user message
-
 	// Handle case where no new item was added
-	if newCount == count {
+	if len(items) == 0 {
 		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Agent returned no response", nil).AsGinResponse())
 		return
 	}
 
-	// Items are stored in mysql, so fetch them to get the full data before returning
-	items, err := orchestrator.GetDBItemsBySessionID(c.Request.Context(), uuid, newCount-count)
-	if err != nil {
-		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to get added items", err).AsGinResponse())
-		return
-	}
-
 	var dbItems []sdk.Item
 	for _, item := range items {
 		dbItems = append(dbItems, toSDKItem(item))
@@ -114,21 +149,226 @@ func PostMessage(c *gin.Context) {
 	c.JSON(sdk.NewSuccessResponse("Message sent successfully", resp).AsGinResponse())
 }
 
+// PostMessageStream handles POST requests to add a message to an existing session, streaming the agent's progress as server-sent events
+func PostMessageStream(c *gin.Context) {
+	uuid := c.Param("uuid")
+
+	// Parse request body
+	var req sdk.PostMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse request body", err).AsGinResponse())
+		return
+	}
+
+	orchestrator := GetOrchestrator()
+
+	// Validate session exists and its user has tokens left
+	sess, ok := findSession(c, uuid)
+	if !ok || !checkBudget(c, sess.GetUserID()) {
+		return
+	}
+
+	// Set headers for server-sent events
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Run the agent, forwarding each event to the client as it arrives
+	stream, _, err := orchestrator.StreamMessage(runContext(c), apikey_module.ActingUserID(c), uuid, req, func(event agents.StreamEvent) error {
+		if sdkEvent, ok := toSDKStreamEvent(event); ok {
+			writeStreamEvent(c, sdkEvent)
+		}
+		return c.Request.Context().Err()
+	})
+	if err != nil {
+		writeStreamEvent(c, sdk.StreamEvent{Type: sdk.StreamEventError, Error: err.Error()})
+		return
+	}
+
+	// Send the final output once the run completes
+	final := sdk.StreamEvent{
+		Type:        sdk.StreamEventFinalOutput,
+		FinalOutput: fmt.Sprint(stream.FinalOutput()),
+	}
+	if agent := stream.LastAgent(); agent != nil {
+		final.Agent = agent.Name
+	}
+	writeStreamEvent(c, final)
+}
+
 // DeleteSession handles DELETE requests to remove an existing session
 func DeleteSession(c *gin.Context) {
 	uuid := c.Param("uuid")
 
 	// Delete the session using the orchestrator
 	orchestrator := GetOrchestrator()
-	sess, err := orchestrator.RemoveSession(c.Request.Context(), uuid)
+	sess, err := orchestrator.RemoveSession(c.Request.Context(), apikey_module.ActingUserID(c), uuid)
 	if err != nil {
-		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to delete session", err).AsGinResponse())
+		status := http.StatusInternalServerError
+		if errors.Is(err, session.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+
+		c.JSON(sdk.NewErrorResponse(status, "Failed to delete session", err).AsGinResponse())
 		return
 	}
 
 	c.JSON(sdk.NewSuccessResponse("Session deleted successfully", sess).AsGinResponse())
 }
 
+// ListRuns handles GET requests to list the recorded runs of a session, newest first
+func ListRuns(c *gin.Context) {
+	uuid := c.Param("uuid")
+
+	// Parse query parameters
+	var req sdk.ListRunsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse query parameters", err).AsGinResponse())
+		return
+	}
+
+	// Validate the session exists and belongs to the acting user
+	if _, ok := findSession(c, uuid); !ok {
+		return
+	}
+
+	records, err := GetOrchestrator().ListRuns(c.Request.Context(), uuid, req.Limit)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to list runs", err).AsGinResponse())
+		return
+	}
+
+	runs := []sdk.Run{}
+	for _, record := range records {
+		runs = append(runs, toSDKRun(record))
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Runs retrieved successfully", runs).AsGinResponse())
+}
+
+// GetRun handles GET requests to retrieve the record of a single run by ID
+func GetRun(c *gin.Context) {
+	id := c.Param("id")
+
+	record, err := GetOrchestrator().GetRun(c.Request.Context(), apikey_module.ActingUserID(c), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, runstore.ErrRunNotFound) {
+			status = http.StatusNotFound
+		}
+
+		c.JSON(sdk.NewErrorResponse(status, "Failed to get run", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Run retrieved successfully", toSDKRun(record)).AsGinResponse())
+}
+
+// GetUsage handles GET requests to report the tokens used over a period, per model
+func GetUsage(c *gin.Context) {
+	// Parse query parameters
+	var req sdk.UsageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse query parameters", err).AsGinResponse())
+		return
+	}
+
+	// Only the acting user's usage is reported
+	if user := apikey_module.ActingUserID(c); user != "" {
+		if req.UserID != "" && req.UserID != user {
+			c.JSON(sdk.NewErrorResponse(http.StatusForbidden, "Can't get usage of other users", nil).AsGinResponse())
+			return
+		}
+		req.UserID = user
+	}
+
+	orchestrator := GetOrchestrator()
+	totals, filter, err := orchestrator.GetUsage(c.Request.Context(), usagestore.Filter{
+		UserID: req.UserID,
+		Since:  req.Since,
+		Until:  req.Until,
+	})
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to get usage", err).AsGinResponse())
+		return
+	}
+
+	resp := sdk.UsageResponse{
+		UserID: req.UserID,
+		Since:  filter.Since,
+		Until:  filter.Until,
+		Models: []sdk.ModelUsage{},
+	}
+	for _, total := range totals {
+		resp.Models = append(resp.Models, sdk.ModelUsage(total))
+		resp.Total.Requests += total.Requests
+		resp.Total.InputTokens += total.InputTokens
+		resp.Total.OutputTokens += total.OutputTokens
+		resp.Total.TotalTokens += total.TotalTokens
+	}
+
+	// Budgets are per user
+	if req.UserID != "" {
+		if resp.Budgets, err = orchestrator.GetBudgets(c.Request.Context(), req.UserID); err != nil {
+			c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to get budgets", err).AsGinResponse())
+			return
+		}
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Usage retrieved successfully", resp).AsGinResponse())
+}
+
+// Helper method to check a user has tokens left in their budgets, responding with an error if they don't
+func checkBudget(c *gin.Context, userID string) bool {
+	err := GetOrchestrator().CheckBudget(c.Request.Context(), userID)
+	if err == nil {
+		return true
+	}
+
+	var exceeded *BudgetExceededError
+	if errors.As(err, &exceeded) {
+		abortLimitExceeded(c, fmt.Sprintf("The %s token budget has been used up", exceeded.Budget.Period), sdk.LimitError{
+			Limit:   exceeded.Budget.Period + "_budget",
+			Max:     exceeded.Budget.Limit,
+			Used:    exceeded.Budget.Used,
+			RetryAt: exceeded.Budget.ResetAt,
+		})
+		return false
+	}
+
+	c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to check token budget", err).AsGinResponse())
+	return false
+}
+
+// Helper method to find a session of the acting user, responding with an error if there isn't one.
+// Sessions of other users are reported as not found so they can't be told apart from missing ones
+func findSession(c *gin.Context, uuid string) (session.Session, bool) {
+	sess, err := GetOrchestrator().FindSession(c.Request.Context(), apikey_module.ActingUserID(c), uuid)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, session.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+
+		c.JSON(sdk.NewErrorResponse(status, "Session not found", err).AsGinResponse())
+		return nil, false
+	}
+
+	return sess, true
+}
+
+// Helper method to build the context for an agent run. Reminders set during the run are delivered
+// by the outreach implementation the request's API key is bound to
+func runContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if clientID := apikey_module.BoundClientID(c); clientID != "" {
+		ctx = agent.WithClientID(ctx, clientID)
+	}
+	return ctx
+}
+
 // Helper method to convert internal session to sdk session
 func toSDKSession(s session.Session) sdk.Session {
 	// Cast to concrete type to access fields
@@ -142,6 +382,20 @@ func toSDKSession(s session.Session) sdk.Session {
 			UserID:    s.UserID,
 		}
 
+		for _, item := range s.Items {
+			dbItem := toSDKItem(*item)
+			resp.Items = append(resp.Items, &dbItem)
+		}
+		return resp
+
+	case *session.InMemorySession:
+		resp := sdk.Session{
+			ID:        s.SessionID(context.Background()),
+			CreatedAt: s.CreatedAt,
+			UpdatedAt: s.UpdatedAt,
+			UserID:    s.UserID,
+		}
+
 		for _, item := range s.Items {
 			dbItem := toSDKItem(*item)
 			resp.Items = append(resp.Items, &dbItem)
@@ -153,6 +407,42 @@ func toSDKSession(s session.Session) sdk.Session {
 	return sdk.Session{}
 }
 
+// Helper method to convert a run record to an sdk run
+func toSDKRun(record *runstore.Record) sdk.Run {
+	run := sdk.Run{
+		ID:         record.ID,
+		CreatedAt:  record.CreatedAt,
+		SessionID:  record.SessionID,
+		UserID:     record.UserID,
+		AgentChain: record.AgentChain,
+		Model:      record.Model,
+		Usage: sdk.ModelUsage{
+			Requests:     record.Requests,
+			InputTokens:  record.InputTokens,
+			OutputTokens: record.OutputTokens,
+			TotalTokens:  record.TotalTokens,
+		},
+		DurationMs: record.Duration.Milliseconds(),
+		Error:      record.Error,
+		ToolCalls:  []sdk.RunToolCall{},
+	}
+
+	for _, call := range record.ToolCalls {
+		run.ToolCalls = append(run.ToolCalls, sdk.RunToolCall{
+			Sequence:   call.Sequence,
+			AgentID:    call.AgentID,
+			Tool:       call.Tool,
+			Arguments:  call.Arguments,
+			Result:     call.Result,
+			Error:      call.Error,
+			StartedAt:  call.StartedAt,
+			DurationMs: call.Duration.Milliseconds(),
+		})
+	}
+
+	return run
+}
+
 // Helper method to convert internal item to sdk item
 func toSDKItem(item session.Item) sdk.Item {
 	return sdk.Item{
@@ -164,3 +454,59 @@ func toSDKItem(item session.Item) sdk.Item {
 		Data:      sdk.ResponseItemData(item.ResponseItem),
 	}
 }
+
+// Helper method to write a single stream event and flush it to the client
+func writeStreamEvent(c *gin.Context, event sdk.StreamEvent) {
+	c.SSEvent(string(event.Type), event)
+	c.Writer.Flush()
+}
+
+// Helper method to convert an agent stream event to an sdk stream event. Events that clients don't need are skipped
+func toSDKStreamEvent(event agents.StreamEvent) (sdk.StreamEvent, bool) {
+	switch e := event.(type) {
+	case agents.RawResponsesStreamEvent:
+		// Only forward text deltas from the raw model stream
+		if e.Data.Type != "response.output_text.delta" {
+			return sdk.StreamEvent{}, false
+		}
+		return sdk.StreamEvent{Type: sdk.StreamEventTextDelta, Delta: e.Data.Delta}, true
+
+	case agents.RunItemStreamEvent:
+		switch item := e.Item.(type) {
+		case agents.ToolCallItem:
+			// Only function tools carry a name and arguments
+			call, ok := item.RawItem.(agents.ResponseFunctionToolCall)
+			if !ok {
+				return sdk.StreamEvent{}, false
+			}
+			return sdk.StreamEvent{
+				Type:      sdk.StreamEventToolCall,
+				Agent:     item.Agent.Name,
+				ToolName:  call.Name,
+				CallID:    call.CallID,
+				Arguments: call.Arguments,
+			}, true
+
+		case agents.ToolCallOutputItem:
+			resp := sdk.StreamEvent{
+				Type:   sdk.StreamEventToolOutput,
+				Agent:  item.Agent.Name,
+				Output: fmt.Sprint(item.Output),
+			}
+			if output, ok := item.RawItem.(agents.ResponseInputItemFunctionCallOutputParam); ok {
+				resp.CallID = output.CallID
+			}
+			return resp, true
+
+		case agents.HandoffOutputItem:
+			return sdk.StreamEvent{
+				Type:      sdk.StreamEventHandoff,
+				Agent:     item.Agent.Name,
+				FromAgent: item.SourceAgent.Name,
+				ToAgent:   item.TargetAgent.Name,
+			}, true
+		}
+	}
+
+	return sdk.StreamEvent{}, false
+}
@@ -1,42 +1,33 @@
 package agent
 
 import (
-	"fmt"
-	"log"
-
-	"github.com/ethanbaker/api/pkg/api_key"
+	apikey_module "github.com/ethanbaker/assistant/internal/api/modules/apikey"
+	"github.com/ethanbaker/assistant/internal/stores/apikey"
 	"github.com/ethanbaker/assistant/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
 // Register routes for the agent module
 func RegisterRoutes(g *gin.RouterGroup, cfg *utils.Config) {
-	// Make api key validator
-	validator, err := makeApiKeyValidator(cfg)
-	if err != nil {
-		log.Fatalf("failed to create API key validator: %v", err)
-	}
-
 	// Create base group for agent routes
 	group := g.Group("/agent")
-	group.Handlers = append(group.Handlers, api_key.APIKeyHeaderHandler(validator))
+	read := apikey_module.RequireScope(apikey.ScopeAgentRead)
+	write := apikey_module.RequireScope(apikey.ScopeAgentWrite)
+	user := apikey_module.RequireActingUser()
+	limit := RateLimitHandler(cfg)
 
 	// Session management routes
-	group.POST("/sessions", CreateSession)             // Create a new session
-	group.GET("/sessions/:uuid", GetSession)           // Get an existing session by UUID
-	group.POST("/sessions/:uuid/message", PostMessage) // Add a message to an existing session
-	group.DELETE("/sessions/:uuid", DeleteSession)     // Delete an existing session
-}
+	group.GET("/sessions", read, user, ListSessions)                                    // List sessions with optional filters and pagination
+	group.POST("/sessions", write, user, CreateSession)                                 // Create a new session
+	group.GET("/sessions/:uuid", read, user, GetSession)                                // Get an existing session by UUID
+	group.POST("/sessions/:uuid/message", write, user, limit, PostMessage)              // Add a message to an existing session
+	group.POST("/sessions/:uuid/message/stream", write, user, limit, PostMessageStream) // Add a message to an existing session and stream the response
+	group.DELETE("/sessions/:uuid", write, user, DeleteSession)                         // Delete an existing session
 
-// makeApiKeyValidator checks if the provided API key is valid
-func makeApiKeyValidator(cfg *utils.Config) (func(key string) bool, error) {
-	// Get api key from config
-	apiKey := cfg.Get("API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("API_KEY not set in environment")
-	}
+	// Run record routes
+	group.GET("/sessions/:uuid/runs", read, user, ListRuns) // List the recorded runs of a session
+	group.GET("/runs/:id", read, user, GetRun)              // Get the record of a single run
 
-	return func(key string) bool {
-		return apiKey == key
-	}, nil
+	// Usage routes
+	group.GET("/usage", read, user, GetUsage) // Report the tokens used per model and the acting user's budgets
 }
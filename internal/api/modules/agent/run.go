@@ -0,0 +1,322 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	runstore "github.com/ethanbaker/assistant/internal/stores/run"
+	"github.com/ethanbaker/assistant/internal/stores/session"
+	usagestore "github.com/ethanbaker/assistant/internal/stores/usage"
+	"github.com/ethanbaker/assistant/internal/telemetry"
+	"github.com/google/uuid"
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"github.com/nlpodyssey/openai-agents-go/memory"
+	"github.com/nlpodyssey/openai-agents-go/usage"
+)
+
+// sessionLocks serializes agent runs per session so concurrent messages never interleave
+type sessionLocks struct {
+	mu    sync.Mutex
+	locks map[uuid.UUID]*sessionLock
+}
+
+// sessionLock is a context-aware mutex shared by every run waiting on the same session
+type sessionLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// newSessionLocks creates an empty set of session locks
+func newSessionLocks() *sessionLocks {
+	return &sessionLocks{
+		locks: make(map[uuid.UUID]*sessionLock),
+	}
+}
+
+// acquire waits until no other run holds the session, or until the context is done.
+// The returned function must be called to release the session.
+func (l *sessionLocks) acquire(ctx context.Context, id uuid.UUID) (func(), error) {
+	// Find or create the lock, registering interest so it isn't removed while we wait
+	l.mu.Lock()
+	lock, exists := l.locks[id]
+	if !exists {
+		lock = &sessionLock{ch: make(chan struct{}, 1)}
+		l.locks[id] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	select {
+	case lock.ch <- struct{}{}:
+		return func() {
+			<-lock.ch
+			l.done(id, lock)
+		}, nil
+	case <-ctx.Done():
+		l.done(id, lock)
+		return nil, ctx.Err()
+	}
+}
+
+// done drops interest in a lock, removing it once no run is holding or waiting on it
+func (l *sessionLocks) done(id uuid.UUID, lock *sessionLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, id)
+	}
+}
+
+// runSession wraps a session to capture the items stored and tokens used during a single agent run
+type runSession struct {
+	session.Session
+
+	mu     sync.Mutex
+	items  []*session.Item
+	usage  *runUsage
+	record *runRecorder
+}
+
+// AddItems saves the items to the underlying session and records them for the run
+func (s *runSession) AddItems(ctx context.Context, responseItems []memory.TResponseInputItem) error {
+	items, err := s.Session.SaveItems(ctx, responseItems)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.items = append(s.items, items...)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Items returns the items stored during the run, skipping the first 'skip' items (the run's input)
+func (s *runSession) Items(skip int) []session.Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := []session.Item{}
+	for i, item := range s.items {
+		if i < skip {
+			continue
+		}
+		items = append(items, *item)
+	}
+
+	return items
+}
+
+// runUsage collects the tokens used on each model during a single agent run. It wraps the runner's model
+// provider, so only agents that name their model are counted
+type runUsage struct {
+	provider agents.ModelProvider
+
+	mu     sync.Mutex
+	models map[string]*usage.Usage
+}
+
+// newRunUsage creates an empty usage collector for models from the provider
+func newRunUsage(provider agents.ModelProvider) *runUsage {
+	return &runUsage{
+		provider: provider,
+		models:   make(map[string]*usage.Usage),
+	}
+}
+
+// GetModel returns the named model, wrapped to count the tokens it uses
+func (u *runUsage) GetModel(modelName string) (agents.Model, error) {
+	model, err := u.provider.GetModel(modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	if modelName == "" {
+		modelName = "default"
+	}
+	return &usageModel{Model: model, name: modelName, usage: u}, nil
+}
+
+// add is a helper to add the usage of a model response
+func (u *runUsage) add(modelName string, used *usage.Usage) {
+	if used == nil {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	total, exists := u.models[modelName]
+	if !exists {
+		total = usage.NewUsage()
+		u.models[modelName] = total
+	}
+	total.Add(used)
+}
+
+// Records returns the collected usage as usage records for a session
+func (u *runUsage) Records(userID, sessionID string) []*usagestore.Record {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	records := []*usagestore.Record{}
+	for model, total := range u.models {
+		records = append(records, &usagestore.Record{
+			UserID:       userID,
+			SessionID:    sessionID,
+			Model:        model,
+			Requests:     total.Requests,
+			InputTokens:  total.InputTokens,
+			OutputTokens: total.OutputTokens,
+			TotalTokens:  total.TotalTokens,
+		})
+	}
+
+	return records
+}
+
+// usageModel counts the tokens used by a model's responses
+type usageModel struct {
+	agents.Model
+
+	name  string
+	usage *runUsage
+}
+
+// GetResponse gets a response from the model and counts its tokens
+func (m *usageModel) GetResponse(ctx context.Context, params agents.ModelResponseParams) (*agents.ModelResponse, error) {
+	resp, err := m.Model.GetResponse(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	m.usage.add(m.name, resp.Usage)
+	return resp, nil
+}
+
+// StreamResponse streams a response from the model and counts its tokens once it completes
+func (m *usageModel) StreamResponse(ctx context.Context, params agents.ModelResponseParams, yield agents.ModelStreamResponseCallback) error {
+	return m.Model.StreamResponse(ctx, params, func(ctx context.Context, event agents.TResponseStreamEvent) error {
+		if event.Type == "response.completed" {
+			m.usage.add(m.name, &usage.Usage{
+				Requests:     1,
+				InputTokens:  uint64(event.Response.Usage.InputTokens),
+				OutputTokens: uint64(event.Response.Usage.OutputTokens),
+				TotalTokens:  uint64(event.Response.Usage.TotalTokens),
+			})
+		}
+		return yield(ctx, event)
+	})
+}
+
+// runRecorder records the agents a single run passed through and the tools they called. It is given to
+// instrumented agents as a telemetry.Observer through the run's context
+type runRecorder struct {
+	id        string
+	startedAt time.Time
+
+	mu    sync.Mutex
+	chain runstore.Chain
+	calls []runstore.ToolCall
+}
+
+// newRunRecorder creates a recorder for a run that starts with the given agent
+func newRunRecorder(agentID string) *runRecorder {
+	return &runRecorder{
+		id:        uuid.NewString(),
+		startedAt: time.Now(),
+		chain:     runstore.Chain{agentID},
+	}
+}
+
+// ObserveToolCall records a finished tool call
+func (r *runRecorder) ObserveToolCall(call telemetry.ToolCall) {
+	toolCall := runstore.ToolCall{
+		RunID:     r.id,
+		AgentID:   call.AgentID,
+		Tool:      call.Tool,
+		Arguments: truncateRecorded(call.Arguments),
+		Result:    truncateRecorded(formatToolResult(call.Result)),
+		StartedAt: call.StartedAt,
+		Duration:  call.Duration,
+	}
+	if call.Error != nil {
+		toolCall.Error = call.Error.Error()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	toolCall.Sequence = len(r.calls)
+	r.calls = append(r.calls, toolCall)
+}
+
+// ObserveHandoff records the agent a run was handed off to
+func (r *runRecorder) ObserveHandoff(fromAgentID, toAgentID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.chain = append(r.chain, toAgentID)
+}
+
+// Record builds the run's record from what was observed and the tokens the run used
+func (r *runRecorder) Record(userID, sessionID string, used []*usagestore.Record, err error) *runstore.Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record := &runstore.Record{
+		ID:         r.id,
+		CreatedAt:  r.startedAt,
+		SessionID:  sessionID,
+		UserID:     userID,
+		AgentChain: slices.Clone(r.chain),
+		Duration:   time.Since(r.startedAt),
+		ToolCalls:  slices.Clone(r.calls),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	models := []string{}
+	for _, u := range used {
+		models = append(models, u.Model)
+		record.Requests += u.Requests
+		record.InputTokens += u.InputTokens
+		record.OutputTokens += u.OutputTokens
+		record.TotalTokens += u.TotalTokens
+	}
+	slices.Sort(models)
+	record.Model = strings.Join(models, ",")
+
+	return record
+}
+
+// formatToolResult is a helper to turn a tool's output into the text stored in its run record
+func formatToolResult(result any) string {
+	switch v := result.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+
+	if data, err := json.Marshal(result); err == nil {
+		return string(data)
+	}
+	return fmt.Sprint(result)
+}
+
+// truncateRecorded is a helper to cut recorded text down to MAX_RECORDED_LENGTH bytes
+func truncateRecorded(text string) string {
+	if len(text) <= MAX_RECORDED_LENGTH {
+		return text
+	}
+	return strings.ToValidUTF8(text[:MAX_RECORDED_LENGTH], "")
+}
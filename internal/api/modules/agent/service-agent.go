@@ -4,61 +4,132 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	overseeragent "github.com/ethanbaker/assistant/internal/agents/overseer"
+	"github.com/ethanbaker/assistant/internal/stores/database"
 	"github.com/ethanbaker/assistant/internal/stores/memory"
+	runstore "github.com/ethanbaker/assistant/internal/stores/run"
 	"github.com/ethanbaker/assistant/internal/stores/session"
+	usagestore "github.com/ethanbaker/assistant/internal/stores/usage"
+	"github.com/ethanbaker/assistant/internal/stores/vector"
+	"github.com/ethanbaker/assistant/internal/telemetry"
 	"github.com/ethanbaker/assistant/pkg/agent"
 	"github.com/ethanbaker/assistant/pkg/sdk"
 	"github.com/ethanbaker/assistant/pkg/utils"
-	"github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 	"github.com/nlpodyssey/openai-agents-go/agents"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Orchestrator is a wrapper for managing the agent's memory and session stores
 type Orchestrator struct {
-	memory   *memory.Store
-	sessions session.Store
-	overseer agent.CustomAgent
+	memory    *memory.Store
+	sessions  session.Store
+	overseer  agent.CustomAgent
+	compactor *session.Compactor // nil when compaction is disabled
+	locks     *sessionLocks
+	usage     *usagestore.Store // nil when usage isn't recorded
+	runs      *runstore.Store   // nil when runs aren't recorded
+	budgets   *tokenBudgets
 }
 
 var orchestrator *Orchestrator
 
 // Create an assistant for the api to run off of
 func Init(cfg *utils.Config) {
-	// Create MySQL config
-	dbConfig := mysql.Config{
-		User:      cfg.Get("MYSQL_USERNAME"),
-		Passwd:    cfg.Get("MYSQL_ROOT_PASSWORD"),
-		Net:       "tcp",
-		Addr:      fmt.Sprintf("%s:%s", cfg.Get("MYSQL_HOST"), cfg.Get("MYSQL_PORT")),
-		DBName:    cfg.Get("MYSQL_DATABASE"),
-		ParseTime: true,
+	// Find the database that stores sessions and facts
+	databaseURL := database.URLFromConfig(cfg)
+	if databaseURL == "" {
+		log.Fatal("[AGENT]: DATABASE_URL not set in environment")
 	}
 
 	// Initialize database connections to create stores
-	memoryStore, err := memory.NewStore(dbConfig.FormatDSN())
+	memoryStore, err := memory.NewStore(databaseURL)
 	if err != nil {
 		log.Fatalf("[AGENT]: Failed to initialize memory store: %v", err)
 	}
 
-	sessionStore, err := session.NewMySqlStore(dbConfig.FormatDSN())
+	sessionStore, err := session.NewMySqlStore(databaseURL)
 	if err != nil {
 		log.Fatalf("[AGENT]: Failed to initialize session store: %v", err)
 	}
 
+	// Give facts stored before facts were scoped per user to the default user
+	if defaultUser := cfg.Get("MEMORY_DEFAULT_USER_ID"); defaultUser != "" {
+		if _, err := memoryStore.AssignUnownedFacts(context.Background(), defaultUser); err != nil {
+			log.Fatalf("[AGENT]: Failed to assign existing facts to default user: %v", err)
+		}
+	}
+
+	// Create the vector index used for semantic search
+	if kind := cfg.GetWithDefault("EMBEDDER", "openai"); kind != "none" {
+		embedder, err := vector.NewEmbedder(kind, cfg.Get("EMBEDDING_MODEL"))
+		if err != nil {
+			log.Fatalf("[AGENT]: Failed to initialize embedder: %v", err)
+		}
+
+		index, err := vector.NewSqlIndex(sessionStore.GetDB(), embedder)
+		if err != nil {
+			log.Fatalf("[AGENT]: Failed to initialize vector index: %v", err)
+		}
+
+		memoryStore.SetIndex(index)
+		sessionStore.SetIndex(index)
+
+		// Make sure facts stored before the index existed can be found
+		if err := memoryStore.IndexFacts(context.Background()); err != nil {
+			log.Printf("[AGENT]: Failed to index existing facts: %v", err)
+		}
+	}
+
+	// Periodically forget facts that have expired
+	if interval := cfg.GetIntWithDefault("MEMORY_SWEEP_INTERVAL_MINUTES", 10); interval > 0 {
+		go memoryStore.RunExpirySweeper(context.Background(), time.Duration(interval)*time.Minute)
+	}
+
 	// Create overseer agent
 	overseer, err := overseeragent.NewOverseerAgent(memoryStore, sessionStore, cfg)
 	if err != nil {
 		log.Fatalf("[AGENT]: Failed to initialize overseer agent: %v", err)
 	}
 
+	// Create the compactor that summarizes older items of long sessions
+	var compactor *session.Compactor
+	if cfg.GetBoolWithDefault("COMPACTION_ENABLED", true) {
+		threshold := cfg.GetIntWithDefault("COMPACTION_THRESHOLD", cfg.GetIntWithDefault("CONTEXT_LIMIT", 10))
+		keep := cfg.GetIntWithDefault("COMPACTION_KEEP_ITEMS", threshold/2)
+		summarizer := session.NewAgentSummarizer(cfg.GetWithDefault("COMPACTION_MODEL", cfg.Get("MODEL")))
+
+		compactor, err = session.NewCompactor(sessionStore, summarizer, threshold, keep)
+		if err != nil {
+			log.Fatalf("[AGENT]: Failed to initialize session compactor: %v", err)
+		}
+	}
+
+	// Record the tokens used by each run
+	usageStore, err := usagestore.NewStore(databaseURL)
+	if err != nil {
+		log.Fatalf("[AGENT]: Failed to initialize usage store: %v", err)
+	}
+
+	// Record the agents, tool calls and tokens of each run
+	runStore, err := runstore.NewStore(databaseURL)
+	if err != nil {
+		log.Fatalf("[AGENT]: Failed to initialize run store: %v", err)
+	}
+
 	// Create the orchestrator with memory and session stores
 	orchestrator = &Orchestrator{
-		memory:   memoryStore,
-		sessions: sessionStore,
-		overseer: overseer,
+		memory:    memoryStore,
+		sessions:  sessionStore,
+		overseer:  overseer,
+		compactor: compactor,
+		locks:     newSessionLocks(),
+		usage:     usageStore,
+		runs:      runStore,
+		budgets:   newTokenBudgets(cfg),
 	}
 }
 
@@ -70,34 +141,161 @@ func GetOrchestrator() *Orchestrator {
 	return orchestrator
 }
 
+// Return the memory store shared by the agents
+func (o *Orchestrator) GetMemoryStore() *memory.Store {
+	return o.memory
+}
+
 // Create a new session
 func (o *Orchestrator) NewSession(ctx context.Context, userID string) (session.Session, error) {
 	return o.sessions.CreateSession(ctx, userID)
 }
 
-// Find an existing session by UUID
-func (o *Orchestrator) FindSession(ctx context.Context, sessionID string) (session.Session, error) {
+// List sessions matching a filter
+func (o *Orchestrator) ListSessions(ctx context.Context, filter session.SessionFilter) (*session.SessionPage, error) {
+	return o.sessions.ListSessions(ctx, filter)
+}
+
+// Find an existing session by UUID. If a user is given, sessions of other users are reported as not found
+func (o *Orchestrator) FindSession(ctx context.Context, userID, sessionID string) (session.Session, error) {
 	// Validate the session ID format
 	guid, err := uuid.Parse(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid session ID format: %v", err)
 	}
 
-	return o.sessions.GetSessionWithItems(ctx, guid)
+	return o.getSessionWithItems(ctx, userID, guid)
+}
+
+// Add a message to an existing session, returning the run result and the items the run produced.
+// If a user is given, sessions of other users are reported as not found.
+// Runs on the same session are serialized, so concurrent messages wait for the previous run to finish.
+func (o *Orchestrator) AddMessage(ctx context.Context, userID, sessionID string, req sdk.PostMessageRequest) (_ *agents.RunResult, _ []session.Item, err error) {
+	ctx, span := startRunSpan(ctx, userID, sessionID, false)
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	guid, release, err := o.lockSession(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer o.finishRun(ctx, guid, release)
+
+	ctx, runner, sess, err := o.newRunner(ctx, userID, guid, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer o.recordUsage(ctx, sess)
+	defer func() { o.recordRun(ctx, sess, err) }()
+
+	// Execute agent call
+	start := time.Now()
+	resp, err := runner.Run(ctx, o.overseer.Agent(), req.Content)
+	telemetry.ObserveRun(o.overseer.ID(), time.Since(start), err)
+	if err != nil {
+		return nil, nil, fmt.Errorf("agent execution failed: %w", err)
+	}
+
+	// Return response, skipping the user message stored with the run
+	return resp, sess.Items(1), nil
+}
+
+// Add a message to an existing session, passing events to 'onEvent' as the agent runs.
+// If a user is given, sessions of other users are reported as not found.
+// The session stays locked until the stream has been fully consumed.
+func (o *Orchestrator) StreamMessage(ctx context.Context, userID, sessionID string, req sdk.PostMessageRequest, onEvent func(agents.StreamEvent) error) (_ *agents.RunResultStreaming, _ []session.Item, err error) {
+	ctx, span := startRunSpan(ctx, userID, sessionID, true)
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	guid, release, err := o.lockSession(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer o.finishRun(ctx, guid, release)
+
+	ctx, runner, sess, err := o.newRunner(ctx, userID, guid, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer o.recordUsage(ctx, sess)
+	defer func() { o.recordRun(ctx, sess, err) }()
+
+	// Start the streamed agent call
+	start := time.Now()
+	resp, err := runner.RunStreamed(ctx, o.overseer.Agent(), req.Content)
+	if err != nil {
+		telemetry.ObserveRun(o.overseer.ID(), time.Since(start), err)
+		return nil, nil, fmt.Errorf("agent execution failed: %w", err)
+	}
+
+	// Consume the stream; items are only saved once it completes
+	err = resp.StreamEvents(onEvent)
+	telemetry.ObserveRun(o.overseer.ID(), time.Since(start), err)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, sess.Items(1), nil
 }
 
-// Add a message to an existing session
-func (o *Orchestrator) AddMessage(ctx context.Context, sessionID string, req sdk.PostMessageRequest) (*agents.RunResult, error) {
+// startRunSpan is a helper to start the span covering a run on a session, including the wait for the session's lock
+func startRunSpan(ctx context.Context, userID, sessionID string, stream bool) (context.Context, trace.Span) {
+	return telemetry.StartSpan(ctx, "agent.run",
+		attribute.String("session.id", sessionID),
+		attribute.String("user.id", userID),
+		attribute.Bool("run.stream", stream),
+	)
+}
+
+// getSessionWithItems is a helper to find a session with its items, checking it belongs to the user if one is given
+func (o *Orchestrator) getSessionWithItems(ctx context.Context, userID string, guid uuid.UUID) (session.Session, error) {
+	if userID == "" {
+		return o.sessions.GetSessionWithItems(ctx, guid)
+	}
+	return o.sessions.GetUserSessionWithItems(ctx, guid, userID)
+}
+
+// lockSession is a helper to parse a session ID and wait for any other run on the session to finish
+func (o *Orchestrator) lockSession(ctx context.Context, sessionID string) (uuid.UUID, func(), error) {
 	// Parse the session ID
 	guid, err := uuid.Parse(sessionID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid session ID format: %v", err)
+		return uuid.Nil, nil, fmt.Errorf("invalid session ID format: %v", err)
 	}
 
+	release, err := o.locks.acquire(ctx, guid)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("failed waiting for session run: %w", err)
+	}
+
+	return guid, release, nil
+}
+
+// finishRun is a helper to compact a session after a run. Compaction happens in the background,
+// and the session stays locked until it is done so the next run sees the updated summary.
+func (o *Orchestrator) finishRun(ctx context.Context, guid uuid.UUID, release func()) {
+	if o.compactor == nil {
+		release()
+		return
+	}
+
+	go func() {
+		defer release()
+
+		if _, err := o.compactor.Compact(context.WithoutCancel(ctx), guid); err != nil {
+			log.Printf("[AGENT]: Failed to compact session %s: %v", guid, err)
+		}
+	}()
+}
+
+// newRunner is a helper to build the context and runner used for a session message
+func (o *Orchestrator) newRunner(ctx context.Context, userID string, guid uuid.UUID, req sdk.PostMessageRequest) (context.Context, *agents.Runner, *runSession, error) {
 	// Find the session
 	sess, err := o.sessions.GetSession(ctx, guid)
 	if err != nil {
-		return nil, err
+		return ctx, nil, nil, err
+	}
+	if userID != "" && sess.GetUserID() != userID {
+		return ctx, nil, nil, session.ErrSessionNotFound
 	}
 
 	// Add data to the context
@@ -105,28 +303,35 @@ func (o *Orchestrator) AddMessage(ctx context.Context, sessionID string, req sdk
 		ctx = context.WithValue(ctx, "data", req.Data)
 	}
 
+	// Let tools know which user and session the run is for
+	ctx = agent.WithUserID(ctx, sess.GetUserID())
+	ctx = agent.WithSessionID(ctx, sess.SessionID(ctx))
+
 	limit := o.overseer.Config().GetIntWithDefault("CONTEXT_LIMIT", 10)
 
+	// Wrap the session to capture the items produced, tokens used, and tools called by this run
+	run := &runSession{
+		Session: sess,
+		usage:   newRunUsage(agents.NewMultiProvider(agents.NewMultiProviderParams{})),
+		record:  newRunRecorder(o.overseer.ID()),
+	}
+	ctx = telemetry.WithObserver(ctx, run.record)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("run.id", run.record.id))
+
 	// Initialize OpenAI agents runner
-	runner := agents.Runner{
+	runner := &agents.Runner{
 		Config: agents.RunConfig{
-			Session:     sess,
-			LimitMemory: limit,
+			Session:       run,
+			LimitMemory:   limit,
+			ModelProvider: run.usage,
 		},
 	}
 
-	// Execute agent call
-	resp, err := runner.Run(ctx, o.overseer.Agent(), req.Content)
-	if err != nil {
-		return nil, fmt.Errorf("agent execution failed: %w", err)
-	}
-
-	// Return response
-	return resp, nil
+	return ctx, runner, run, nil
 }
 
-// Remove an existing session and return it
-func (o *Orchestrator) RemoveSession(ctx context.Context, sessionID string) (session.Session, error) {
+// Remove an existing session and return it. If a user is given, sessions of other users are reported as not found
+func (o *Orchestrator) RemoveSession(ctx context.Context, userID, sessionID string) (session.Session, error) {
 	// Parse session ID
 	guid, err := uuid.Parse(sessionID)
 	if err != nil {
@@ -134,7 +339,7 @@ func (o *Orchestrator) RemoveSession(ctx context.Context, sessionID string) (ses
 	}
 
 	// Get the session to return it
-	sess, err := o.sessions.GetSessionWithItems(ctx, guid)
+	sess, err := o.getSessionWithItems(ctx, userID, guid)
 	if err != nil {
 		return nil, err
 	}
@@ -146,27 +351,3 @@ func (o *Orchestrator) RemoveSession(ctx context.Context, sessionID string) (ses
 
 	return sess, nil
 }
-
-// Get the latest 'n' items from memory store by session id
-func (o *Orchestrator) GetDBItemsBySessionID(ctx context.Context, sessionID string, limit int) ([]session.Item, error) {
-	// Find the session
-	sess, err := o.FindSession(ctx, sessionID)
-	if err != nil {
-		return nil, err
-	}
-
-	return sess.GetLatestItems(ctx, limit), nil
-}
-
-// Find the item count for a session
-func (o *Orchestrator) GetItemCountBySessionID(ctx context.Context, sessionID string) (int, error) {
-	// Find the session
-	sess, err := o.FindSession(ctx, sessionID)
-	if err != nil {
-		return 0, err
-	}
-
-	// Get item count from the session
-	count := sess.GetItemCount()
-	return count, nil
-}
@@ -0,0 +1,192 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/session"
+	"github.com/ethanbaker/assistant/internal/stores/storetest"
+	"github.com/ethanbaker/assistant/pkg/sdk"
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"github.com/nlpodyssey/openai-agents-go/agentstesting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoModel replies to the latest user message and records how many runs overlap
+type echoModel struct {
+	active    atomic.Int32
+	maxActive atomic.Int32
+}
+
+func (m *echoModel) GetResponse(ctx context.Context, params agents.ModelResponseParams) (*agents.ModelResponse, error) {
+	active := m.active.Add(1)
+	defer m.active.Add(-1)
+
+	for {
+		max := m.maxActive.Load()
+		if active <= max || m.maxActive.CompareAndSwap(max, active) {
+			break
+		}
+	}
+
+	// Give other runs a chance to interleave
+	time.Sleep(10 * time.Millisecond)
+
+	// Find the latest user message
+	var content string
+	if items, ok := params.Input.(agents.InputItems); ok {
+		for _, item := range items {
+			if item.OfMessage != nil && item.OfMessage.Content.OfString.Valid() {
+				content = item.OfMessage.Content.OfString.Value
+			}
+		}
+	}
+
+	return &agents.ModelResponse{
+		Output: []agents.TResponseOutputItem{agentstesting.GetTextMessage("reply: " + content)},
+		Usage:  nil,
+	}, nil
+}
+
+func (m *echoModel) StreamResponse(ctx context.Context, params agents.ModelResponseParams, yield agents.ModelStreamResponseCallback) error {
+	return fmt.Errorf("streaming not supported")
+}
+
+// testAgent implements agent.CustomAgent for testing
+type testAgent struct {
+	agent  *agents.Agent
+	config *utils.Config
+}
+
+func (a *testAgent) Agent() *agents.Agent                  { return a.agent }
+func (a *testAgent) ID() string                            { return "test" }
+func (a *testAgent) Config() *utils.Config                 { return a.config }
+func (a *testAgent) ShouldDryRun(ctx context.Context) bool { return false }
+
+func TestOrchestratorAddMessageConcurrent(t *testing.T) {
+	ctx := context.Background()
+	model := &echoModel{}
+
+	o := &Orchestrator{
+		sessions: session.NewInMemoryStore(),
+		overseer: &testAgent{
+			agent:  agents.New("test").WithModelInstance(model),
+			config: utils.NewConfig(map[string]string{}),
+		},
+		locks: newSessionLocks(),
+	}
+
+	sess, err := o.NewSession(ctx, "user")
+	require.NoError(t, err)
+	sessionID := sess.SessionID(ctx)
+
+	const runs = 8
+
+	var wg sync.WaitGroup
+	outputs := make([][]session.Item, runs)
+	errs := make([]error, runs)
+	for i := range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, outputs[i], errs[i] = o.AddMessage(ctx, "user", sessionID, sdk.PostMessageRequest{Content: fmt.Sprintf("message %d", i)})
+		}()
+	}
+	wg.Wait()
+
+	// Runs on the same session never overlap
+	assert.Equal(t, int32(1), model.maxActive.Load())
+
+	// Each run returns only the reply to its own message
+	for i := range runs {
+		require.NoError(t, errs[i])
+		require.Len(t, outputs[i], 1)
+
+		item := outputs[i][0].ResponseItem.TResponseInputItem
+		require.NotNil(t, item.OfOutputMessage)
+		assert.Equal(t, fmt.Sprintf("reply: message %d", i), item.OfOutputMessage.Content[0].OfOutputText.Text)
+	}
+
+	// Every user message and reply is stored
+	full, err := o.FindSession(ctx, "user", sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, runs*2, full.GetItemCount())
+
+	// No locks are left behind
+	assert.Empty(t, o.locks.locks)
+}
+
+func TestOrchestratorSessionOwnership(t *testing.T) {
+	sqlStore := storetest.New(t, session.NewMySqlStore)
+
+	stores := map[string]session.Store{
+		"in-memory": session.NewInMemoryStore(),
+		"sql":       sqlStore,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			o := &Orchestrator{
+				sessions: store,
+				overseer: &testAgent{
+					agent:  agents.New("test").WithModelInstance(&echoModel{}),
+					config: utils.NewConfig(map[string]string{}),
+				},
+				locks: newSessionLocks(),
+			}
+
+			sess, err := o.NewSession(ctx, "user-a")
+			require.NoError(t, err)
+			sessionID := sess.SessionID(ctx)
+
+			// Other users get not found for every lookup
+			_, err = o.FindSession(ctx, "user-b", sessionID)
+			assert.ErrorIs(t, err, session.ErrSessionNotFound)
+
+			_, _, err = o.AddMessage(ctx, "user-b", sessionID, sdk.PostMessageRequest{Content: "hello"})
+			assert.ErrorIs(t, err, session.ErrSessionNotFound)
+
+			_, err = o.RemoveSession(ctx, "user-b", sessionID)
+			assert.ErrorIs(t, err, session.ErrSessionNotFound)
+
+			// The owner and unscoped lookups still find it
+			_, _, err = o.AddMessage(ctx, "user-a", sessionID, sdk.PostMessageRequest{Content: "hello"})
+			require.NoError(t, err)
+
+			found, err := o.FindSession(ctx, "", sessionID)
+			require.NoError(t, err)
+			assert.Equal(t, 2, found.GetItemCount())
+
+			_, err = o.RemoveSession(ctx, "user-a", sessionID)
+			require.NoError(t, err)
+
+			_, err = o.FindSession(ctx, "user-a", sessionID)
+			assert.ErrorIs(t, err, session.ErrSessionNotFound)
+		})
+	}
+}
+
+func TestSessionLocksContextCancel(t *testing.T) {
+	locks := newSessionLocks()
+	sess, err := session.NewInMemoryStore().CreateSession(context.Background(), "user")
+	require.NoError(t, err)
+	id := sess.(*session.InMemorySession).ID
+
+	release, err := locks.acquire(context.Background(), id)
+	require.NoError(t, err)
+
+	// A second run gives up once its context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = locks.acquire(ctx, id)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	assert.Empty(t, locks.locks)
+}
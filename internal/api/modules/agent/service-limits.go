@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	apikey_module "github.com/ethanbaker/assistant/internal/api/modules/apikey"
+	"github.com/ethanbaker/assistant/pkg/sdk"
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Kinds of limits a request can exceed
+const (
+	RATE_LIMIT_KEY  = "rate_limit_key"
+	RATE_LIMIT_USER = "rate_limit_user"
+)
+
+// MAX_IDLE_BUCKETS is how many buckets a rate limiter keeps before it forgets the ones that are full again
+const MAX_IDLE_BUCKETS = 1000
+
+// rateLimiter allows a number of requests per minute for each key, using a token bucket per key
+type rateLimiter struct {
+	perMinute int
+
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+}
+
+// rateBucket holds the requests a key has left
+type rateBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newRateLimiter creates a rate limiter. A limit of zero or less allows every request
+func newRateLimiter(perMinute int) *rateLimiter {
+	return &rateLimiter{
+		perMinute: perMinute,
+		buckets:   make(map[string]*rateBucket),
+	}
+}
+
+// allow takes a request from a key's bucket. If the bucket is empty, it returns when the next request is allowed
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Time) {
+	if l.perMinute <= 0 {
+		return true, time.Time{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	capacity := float64(l.perMinute)
+	rate := capacity / time.Minute.Seconds()
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		l.forgetFull(now)
+		bucket = &rateBucket{tokens: capacity, updated: now}
+		l.buckets[key] = bucket
+	}
+
+	// Refill the bucket for the time since it was last used
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.updated).Seconds()*rate)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+		return false, now.Add(wait)
+	}
+
+	bucket.tokens--
+	return true, time.Time{}
+}
+
+// forgetFull is a helper to drop buckets that have refilled, once there are too many of them
+func (l *rateLimiter) forgetFull(now time.Time) {
+	if len(l.buckets) < MAX_IDLE_BUCKETS {
+		return
+	}
+
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.updated) >= time.Minute {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimitHandler is a middleware that limits the requests per minute of each API key and each user. Requests
+// count against the acting user, or the owner of the session for admin keys acting for every user.
+// Limits are read from AGENT_RATE_LIMIT_PER_KEY and AGENT_RATE_LIMIT_PER_USER; zero turns a limit off
+func RateLimitHandler(cfg *utils.Config) gin.HandlerFunc {
+	perKey := newRateLimiter(cfg.GetIntWithDefault("AGENT_RATE_LIMIT_PER_KEY", 60))
+	perUser := newRateLimiter(cfg.GetIntWithDefault("AGENT_RATE_LIMIT_PER_USER", 10))
+
+	return func(c *gin.Context) {
+		now := time.Now()
+
+		// Keys are counted by ID; the root key has no ID
+		keyID := "root"
+		if key := apikey_module.KeyFromContext(c); key != nil && key.ID != 0 {
+			keyID = strconv.FormatUint(uint64(key.ID), 10)
+		}
+		if ok, retryAt := perKey.allow(keyID, now); !ok {
+			abortLimitExceeded(c, "API key rate limit exceeded", sdk.LimitError{Limit: RATE_LIMIT_KEY, Max: uint64(perKey.perMinute), RetryAt: retryAt})
+			return
+		}
+
+		if userID := rateLimitedUser(c); userID != "" {
+			if ok, retryAt := perUser.allow(userID, now); !ok {
+				abortLimitExceeded(c, "User rate limit exceeded", sdk.LimitError{Limit: RATE_LIMIT_USER, Max: uint64(perUser.perMinute), RetryAt: retryAt})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitedUser is a helper to find the user a request counts against. Admin keys acting for every user
+// are counted against the owner of the session, so they can't be used to get around the user's limit
+func rateLimitedUser(c *gin.Context) string {
+	if userID := apikey_module.ActingUserID(c); userID != "" {
+		return userID
+	}
+
+	// Unknown sessions aren't counted, since the request fails with not found
+	guid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return ""
+	}
+
+	sess, err := GetOrchestrator().sessions.GetSession(c.Request.Context(), guid)
+	if err != nil {
+		return ""
+	}
+	return sess.GetUserID()
+}
+
+// abortLimitExceeded is a helper to respond with a 429 describing the exceeded limit
+func abortLimitExceeded(c *gin.Context, message string, limit sdk.LimitError) {
+	retryAfter := max(int(math.Ceil(time.Until(limit.RetryAt).Seconds())), 1)
+	c.Header("Retry-After", fmt.Sprint(retryAfter))
+	c.JSON(sdk.NewErrorResponse(http.StatusTooManyRequests, message, limit).AsGinResponse())
+	c.Abort()
+}
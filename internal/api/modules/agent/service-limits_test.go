@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/session"
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(2)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// The bucket starts full
+	ok, _ := limiter.allow("a", now)
+	assert.True(t, ok)
+	ok, _ = limiter.allow("a", now)
+	assert.True(t, ok)
+
+	// Once empty, the next request is allowed after a refill
+	ok, retryAt := limiter.allow("a", now)
+	assert.False(t, ok)
+	assert.Equal(t, now.Add(30*time.Second), retryAt)
+
+	// Keys have separate buckets
+	ok, _ = limiter.allow("b", now)
+	assert.True(t, ok)
+
+	ok, _ = limiter.allow("a", now.Add(30*time.Second))
+	assert.True(t, ok)
+
+	// No limit allows everything
+	unlimited := newRateLimiter(0)
+	for i := range 100 {
+		ok, _ := unlimited.allow(fmt.Sprint(i%2), now)
+		assert.True(t, ok)
+	}
+}
+
+func TestRateLimitHandlerSessionOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	store := session.NewInMemoryStore()
+	orchestrator = &Orchestrator{sessions: store}
+	t.Cleanup(func() { orchestrator = nil })
+
+	sessA, err := store.CreateSession(ctx, "user-a")
+	require.NoError(t, err)
+	sessB, err := store.CreateSession(ctx, "user-b")
+	require.NoError(t, err)
+
+	// Requests without an API key act for every user, like the root key
+	engine := gin.New()
+	engine.POST("/sessions/:uuid/message", RateLimitHandler(utils.NewConfig(map[string]string{
+		"AGENT_RATE_LIMIT_PER_KEY":  "0",
+		"AGENT_RATE_LIMIT_PER_USER": "1",
+	})), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(sess session.Session) int {
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/sessions/"+sess.SessionID(ctx)+"/message", nil))
+		return rec.Code
+	}
+
+	// Requests count against the session's owner even without an acting user
+	assert.Equal(t, http.StatusOK, request(sessA))
+	assert.Equal(t, http.StatusTooManyRequests, request(sessA))
+	assert.Equal(t, http.StatusOK, request(sessB))
+}
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	runstore "github.com/ethanbaker/assistant/internal/stores/run"
+)
+
+// MAX_RECORDED_LENGTH is the most bytes of a tool call's arguments or result kept in a run record
+const MAX_RECORDED_LENGTH = 16 * 1024
+
+// ListRuns returns the recorded runs of a session, newest first. A limit of 0 returns every run
+func (o *Orchestrator) ListRuns(ctx context.Context, sessionID string, limit int) ([]*runstore.Record, error) {
+	if o.runs == nil {
+		return []*runstore.Record{}, nil
+	}
+	return o.runs.ListSessionRuns(ctx, sessionID, limit)
+}
+
+// GetRun returns a recorded run. If a user is given, runs on sessions of other users are reported as not found
+func (o *Orchestrator) GetRun(ctx context.Context, userID, runID string) (*runstore.Record, error) {
+	if o.runs == nil {
+		return nil, fmt.Errorf("run %s: %w", runID, runstore.ErrRunNotFound)
+	}
+
+	run, err := o.runs.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" && run.UserID != userID {
+		return nil, fmt.Errorf("run %s: %w", runID, runstore.ErrRunNotFound)
+	}
+
+	return run, nil
+}
+
+// recordRun is a helper to store the record of a run. Runs are recorded even if they failed
+func (o *Orchestrator) recordRun(ctx context.Context, run *runSession, runErr error) {
+	if o.runs == nil {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	userID, sessionID := run.GetUserID(), run.SessionID(ctx)
+
+	record := run.record.Record(userID, sessionID, run.usage.Records(userID, sessionID), runErr)
+	if err := o.runs.CreateRun(ctx, record); err != nil {
+		log.Printf("[AGENT]: Failed to record run %s of session %s: %v", record.ID, sessionID, err)
+	}
+}
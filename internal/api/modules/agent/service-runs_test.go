@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	runstore "github.com/ethanbaker/assistant/internal/stores/run"
+	"github.com/ethanbaker/assistant/internal/stores/session"
+	"github.com/ethanbaker/assistant/internal/stores/storetest"
+	"github.com/ethanbaker/assistant/internal/telemetry"
+	"github.com/ethanbaker/assistant/pkg/sdk"
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"github.com/nlpodyssey/openai-agents-go/agentstesting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrchestratorRecordRun(t *testing.T) {
+	ctx := context.Background()
+
+	store := storetest.New(t, runstore.NewStore)
+
+	// The overseer hands off to a sub-agent, which calls two tools before replying
+	model := agentstesting.NewFakeModel(false, nil)
+	subAgent := agents.New("sub-agent").
+		WithModelInstance(model).
+		WithTools(
+			agentstesting.GetFunctionTool("lookup", "found it"),
+			agentstesting.GetFunctionToolErr("broken", errors.New("tool failed")),
+		)
+	overseer := agents.New("test").
+		WithModelInstance(model).
+		WithHandoffs(agents.HandoffFromAgent(agents.HandoffFromAgentParams{Agent: subAgent}))
+
+	telemetry.InstrumentAgent(subAgent, "sub-agent")
+	telemetry.InstrumentAgent(overseer, "test")
+
+	model.AddMultipleTurnOutputs([]agentstesting.FakeModelTurnOutput{
+		{Value: []agents.TResponseOutputItem{agentstesting.GetHandoffToolCall(subAgent, "", "")}},
+		{Value: []agents.TResponseOutputItem{agentstesting.GetFunctionToolCall("lookup", `{"query":"cats"}`)}},
+		{Value: []agents.TResponseOutputItem{agentstesting.GetFunctionToolCall("broken", `{}`)}},
+		{Value: []agents.TResponseOutputItem{agentstesting.GetTextMessage("done")}},
+	})
+
+	o := &Orchestrator{
+		sessions: session.NewInMemoryStore(),
+		overseer: &testAgent{agent: overseer, config: utils.NewConfig(map[string]string{})},
+		locks:    newSessionLocks(),
+		runs:     store,
+	}
+
+	sess, err := o.NewSession(ctx, "user-a")
+	require.NoError(t, err)
+	sessionID := sess.SessionID(ctx)
+
+	_, _, err = o.AddMessage(ctx, "user-a", sessionID, sdk.PostMessageRequest{Content: "find cats"})
+	require.NoError(t, err)
+
+	// The run is recorded with its agent chain and tool calls in order
+	runs, err := o.ListRuns(ctx, sessionID, 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+
+	run := runs[0]
+	assert.Equal(t, sessionID, run.SessionID)
+	assert.Equal(t, "user-a", run.UserID)
+	assert.Equal(t, runstore.Chain{"test", "sub-agent"}, run.AgentChain)
+	assert.Empty(t, run.Error)
+
+	require.Len(t, run.ToolCalls, 2)
+	assert.Equal(t, "lookup", run.ToolCalls[0].Tool)
+	assert.Equal(t, "sub-agent", run.ToolCalls[0].AgentID)
+	assert.Equal(t, `{"query":"cats"}`, run.ToolCalls[0].Arguments)
+	assert.Equal(t, "found it", run.ToolCalls[0].Result)
+	assert.Empty(t, run.ToolCalls[0].Error)
+	assert.Equal(t, "broken", run.ToolCalls[1].Tool)
+	assert.Equal(t, "tool failed", run.ToolCalls[1].Error)
+
+	// Runs can only be read by the owner of their session, or unscoped
+	found, err := o.GetRun(ctx, "user-a", run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, run.ID, found.ID)
+
+	_, err = o.GetRun(ctx, "", run.ID)
+	require.NoError(t, err)
+
+	_, err = o.GetRun(ctx, "user-b", run.ID)
+	assert.ErrorIs(t, err, runstore.ErrRunNotFound)
+}
@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	usagestore "github.com/ethanbaker/assistant/internal/stores/usage"
+	"github.com/ethanbaker/assistant/pkg/sdk"
+	"github.com/ethanbaker/assistant/pkg/utils"
+)
+
+// Token budget periods
+const (
+	DAILY_BUDGET   = "daily"
+	MONTHLY_BUDGET = "monthly"
+)
+
+// BudgetExceededError is returned when a user has used up one of their token budgets
+type BudgetExceededError struct {
+	Budget sdk.BudgetUsage
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s token budget of %d exceeded (%d used), resets at %s", e.Budget.Period, e.Budget.Limit, e.Budget.Used, e.Budget.ResetAt.Format(time.RFC3339))
+}
+
+// tokenBudgets are the number of tokens each user can use per day and per month. Zero means unlimited
+type tokenBudgets struct {
+	daily    uint64
+	monthly  uint64
+	location *time.Location // Timezone the days and months start in
+}
+
+// newTokenBudgets reads the token budgets from the config
+func newTokenBudgets(cfg *utils.Config) *tokenBudgets {
+	budgets := &tokenBudgets{
+		daily:    uint64(max(cfg.GetIntWithDefault("AGENT_DAILY_TOKEN_BUDGET", 0), 0)),
+		monthly:  uint64(max(cfg.GetIntWithDefault("AGENT_MONTHLY_TOKEN_BUDGET", 0), 0)),
+		location: time.Local,
+	}
+
+	if tz := cfg.Get("TIMEZONE"); tz != "" {
+		location, err := time.LoadLocation(tz)
+		if err != nil {
+			log.Printf("[AGENT]: Invalid TIMEZONE '%s', token budgets use the local timezone: %v", tz, err)
+		} else {
+			budgets.location = location
+		}
+	}
+
+	return budgets
+}
+
+// budgetPeriod is the current period of a token budget
+type budgetPeriod struct {
+	start  time.Time
+	budget sdk.BudgetUsage
+}
+
+// periods returns the current period of each configured budget
+func (b *tokenBudgets) periods(now time.Time) []budgetPeriod {
+	periods := []budgetPeriod{}
+
+	if b.daily > 0 {
+		now := now.In(b.location)
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, b.location)
+		periods = append(periods, budgetPeriod{
+			start:  start,
+			budget: sdk.BudgetUsage{Period: DAILY_BUDGET, Limit: b.daily, ResetAt: start.AddDate(0, 0, 1)},
+		})
+	}
+	if b.monthly > 0 {
+		start := b.monthStart(now)
+		periods = append(periods, budgetPeriod{
+			start:  start,
+			budget: sdk.BudgetUsage{Period: MONTHLY_BUDGET, Limit: b.monthly, ResetAt: start.AddDate(0, 1, 0)},
+		})
+	}
+
+	return periods
+}
+
+// monthStart returns the start of the current month
+func (b *tokenBudgets) monthStart(now time.Time) time.Time {
+	now = now.In(b.location)
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, b.location)
+}
+
+// Return how much of each of their token budgets a user has used
+func (o *Orchestrator) GetBudgets(ctx context.Context, userID string) ([]sdk.BudgetUsage, error) {
+	budgets := []sdk.BudgetUsage{}
+	if o.usage == nil || o.budgets == nil {
+		return budgets, nil
+	}
+
+	for _, period := range o.budgets.periods(time.Now()) {
+		used, err := o.usage.TotalTokens(ctx, userID, period.start)
+		if err != nil {
+			return nil, err
+		}
+
+		period.budget.Used = used
+		budgets = append(budgets, period.budget)
+	}
+
+	return budgets, nil
+}
+
+// Check a user has tokens left in all of their budgets, returning a BudgetExceededError if they don't
+func (o *Orchestrator) CheckBudget(ctx context.Context, userID string) error {
+	budgets, err := o.GetBudgets(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, budget := range budgets {
+		if budget.Used >= budget.Limit {
+			return &BudgetExceededError{Budget: budget}
+		}
+	}
+	return nil
+}
+
+// Return the tokens used per model over a period. Without a start, the period starts at the beginning of the month
+func (o *Orchestrator) GetUsage(ctx context.Context, filter usagestore.Filter) ([]usagestore.Totals, usagestore.Filter, error) {
+	if filter.Since.IsZero() {
+		budgets := o.budgets
+		if budgets == nil {
+			budgets = &tokenBudgets{location: time.Local}
+		}
+		filter.Since = budgets.monthStart(time.Now())
+	}
+	if filter.Until.IsZero() {
+		filter.Until = time.Now()
+	}
+
+	if o.usage == nil {
+		return []usagestore.Totals{}, filter, nil
+	}
+
+	totals, err := o.usage.Summarize(ctx, filter)
+	return totals, filter, err
+}
+
+// recordUsage is a helper to store the tokens used during a run. Tokens are recorded even if the run failed
+func (o *Orchestrator) recordUsage(ctx context.Context, run *runSession) {
+	if o.usage == nil {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	records := run.usage.Records(run.GetUserID(), run.SessionID(ctx))
+	if err := o.usage.Record(ctx, records...); err != nil {
+		log.Printf("[AGENT]: Failed to record usage of session %s: %v", run.SessionID(ctx), err)
+	}
+}
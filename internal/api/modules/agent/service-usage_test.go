@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/storetest"
+	usagestore "github.com/ethanbaker/assistant/internal/stores/usage"
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"github.com/nlpodyssey/openai-agents-go/usage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usageTestModel returns a fixed usage for every response
+type usageTestModel struct {
+	echoModel
+}
+
+func (m *usageTestModel) GetResponse(ctx context.Context, params agents.ModelResponseParams) (*agents.ModelResponse, error) {
+	resp, err := m.echoModel.GetResponse(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	resp.Usage = &usage.Usage{Requests: 1, InputTokens: 40, OutputTokens: 10, TotalTokens: 50}
+	return resp, nil
+}
+
+// usageTestProvider returns the same model for every name
+type usageTestProvider struct {
+	model agents.Model
+}
+
+func (p usageTestProvider) GetModel(string) (agents.Model, error) { return p.model, nil }
+
+func TestRunUsage(t *testing.T) {
+	ctx := context.Background()
+	collected := newRunUsage(usageTestProvider{model: &usageTestModel{}})
+
+	for _, name := range []string{"gpt-4.1", "gpt-4.1", "gpt-4.1-mini"} {
+		model, err := collected.GetModel(name)
+		require.NoError(t, err)
+		_, err = model.GetResponse(ctx, agents.ModelResponseParams{})
+		require.NoError(t, err)
+	}
+
+	// Usage is summed per model
+	records := map[string]*usagestore.Record{}
+	for _, record := range collected.Records("user", "session") {
+		records[record.Model] = record
+	}
+	require.Len(t, records, 2)
+	assert.Equal(t, uint64(2), records["gpt-4.1"].Requests)
+	assert.Equal(t, uint64(100), records["gpt-4.1"].TotalTokens)
+	assert.Equal(t, uint64(40), records["gpt-4.1-mini"].InputTokens)
+	assert.Equal(t, "user", records["gpt-4.1-mini"].UserID)
+}
+
+func TestOrchestratorCheckBudget(t *testing.T) {
+	ctx := context.Background()
+
+	store := storetest.New(t, usagestore.NewStore)
+
+	o := &Orchestrator{
+		usage:   store,
+		budgets: &tokenBudgets{daily: 100, monthly: 1000, location: time.UTC},
+	}
+
+	require.NoError(t, store.Record(ctx, &usagestore.Record{UserID: "user-a", Model: "gpt-4.1", Requests: 1, TotalTokens: 99}))
+	require.NoError(t, o.CheckBudget(ctx, "user-a"))
+
+	// Using up the daily budget blocks the user until tomorrow
+	require.NoError(t, store.Record(ctx, &usagestore.Record{UserID: "user-a", Model: "gpt-4.1", Requests: 1, TotalTokens: 1}))
+
+	err := o.CheckBudget(ctx, "user-a")
+	var exceeded *BudgetExceededError
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, DAILY_BUDGET, exceeded.Budget.Period)
+	assert.Equal(t, uint64(100), exceeded.Budget.Used)
+	assert.True(t, exceeded.Budget.ResetAt.After(time.Now()))
+
+	// Other users have their own budgets
+	require.NoError(t, o.CheckBudget(ctx, "user-b"))
+
+	budgets, err := o.GetBudgets(ctx, "user-a")
+	require.NoError(t, err)
+	require.Len(t, budgets, 2)
+	assert.Equal(t, MONTHLY_BUDGET, budgets[1].Period)
+	assert.Equal(t, uint64(100), budgets[1].Used)
+}
@@ -0,0 +1,133 @@
+package apikey_module
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ethanbaker/assistant/internal/stores/apikey"
+	"github.com/ethanbaker/assistant/pkg/sdk"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateKey handles POST requests to mint a new API key
+func CreateKey(c *gin.Context) {
+	store := getStore()
+	if store == nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusServiceUnavailable, "API keys can't be stored without a database", nil).AsGinResponse())
+		return
+	}
+
+	// Parse request body
+	var req sdk.CreateApiKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse request body", err).AsGinResponse())
+		return
+	}
+
+	scopes, err := apikey.NewScopes(req.Scopes...)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Invalid scopes", err).AsGinResponse())
+		return
+	}
+
+	// Keys bound to a user can't mint keys for other users
+	if bound := BoundUserID(c); bound != "" && req.UserID != bound {
+		c.JSON(sdk.NewErrorResponse(http.StatusForbidden, "Keys can only be created for the bound user", nil).AsGinResponse())
+		return
+	}
+
+	key, raw, err := store.CreateKey(c.Request.Context(), req.Name, scopes, req.UserID, req.ClientID)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to create API key", err).AsGinResponse())
+		return
+	}
+
+	resp := sdk.CreateApiKeyResponse{
+		ApiKey: toSDKApiKey(key),
+		Key:    raw,
+	}
+	c.JSON(sdk.NewSuccessResponse("API key created successfully", resp).AsGinResponse())
+}
+
+// ListKeys handles GET requests to list API keys
+func ListKeys(c *gin.Context) {
+	store := getStore()
+	if store == nil {
+		c.JSON(sdk.NewSuccessResponse("API keys retrieved successfully", []sdk.ApiKey{}).AsGinResponse())
+		return
+	}
+
+	includeRevoked, _ := strconv.ParseBool(c.Query("include_revoked"))
+	keys, err := store.ListKeys(c.Request.Context(), includeRevoked)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to list API keys", err).AsGinResponse())
+		return
+	}
+
+	// Keys bound to a user only see that user's keys
+	bound := BoundUserID(c)
+	resp := []sdk.ApiKey{}
+	for _, key := range keys {
+		if bound == "" || key.UserID == bound {
+			resp = append(resp, toSDKApiKey(key))
+		}
+	}
+
+	c.JSON(sdk.NewSuccessResponse("API keys retrieved successfully", resp).AsGinResponse())
+}
+
+// RevokeKey handles DELETE requests to revoke an API key
+func RevokeKey(c *gin.Context) {
+	store := getStore()
+	if store == nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusNotFound, "API key not found", nil).AsGinResponse())
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Invalid API key ID", err).AsGinResponse())
+		return
+	}
+
+	key, err := store.GetKey(c.Request.Context(), uint(id))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, apikey.ErrKeyNotFound) {
+			status = http.StatusNotFound
+		}
+
+		c.JSON(sdk.NewErrorResponse(status, "Failed to revoke API key", err).AsGinResponse())
+		return
+	}
+
+	// Keys bound to a user can only revoke that user's keys
+	if bound := BoundUserID(c); bound != "" && key.UserID != bound {
+		c.JSON(sdk.NewErrorResponse(http.StatusNotFound, "Failed to revoke API key", apikey.ErrKeyNotFound).AsGinResponse())
+		return
+	}
+
+	key, err = store.RevokeKey(c.Request.Context(), key.ID)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to revoke API key", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("API key revoked successfully", toSDKApiKey(key)).AsGinResponse())
+}
+
+// Helper method to convert a stored key to an sdk key
+func toSDKApiKey(key *apikey.Key) sdk.ApiKey {
+	return sdk.ApiKey{
+		ID:         key.ID,
+		CreatedAt:  key.CreatedAt,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		UserID:     key.UserID,
+		ClientID:   key.ClientID,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
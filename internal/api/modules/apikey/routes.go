@@ -0,0 +1,18 @@
+package apikey_module
+
+import (
+	"github.com/ethanbaker/assistant/internal/stores/apikey"
+	"github.com/gin-gonic/gin"
+)
+
+// Register routes for the API key module
+func RegisterRoutes(g *gin.RouterGroup) {
+	// Create base group for key routes
+	group := g.Group("/keys")
+	group.Use(RequireScope(apikey.ScopeKeysAdmin))
+
+	// Key management routes
+	group.GET("", ListKeys)         // List API keys
+	group.POST("", CreateKey)       // Mint a new API key
+	group.DELETE("/:id", RevokeKey) // Revoke an API key
+}
@@ -0,0 +1,158 @@
+package apikey_module
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/ethanbaker/api/pkg/api_types"
+	"github.com/ethanbaker/assistant/internal/stores/apikey"
+	"github.com/ethanbaker/assistant/internal/stores/database"
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// API_KEY_HEADER is the header clients send their API key in
+const API_KEY_HEADER = "X-API-KEY"
+
+// USER_ID_HEADER is the header clients send the user they are acting for in
+const USER_ID_HEADER = "X-USER-ID"
+
+// contextKey is where the authenticated key is kept on the gin context
+const contextKey = "api_key"
+
+// actingUserKey is where the acting user is kept on the gin context
+const actingUserKey = "acting_user_id"
+
+// keyStore holds the stored API keys. It is nil when no database is configured
+var keyStore *apikey.Store
+
+// rootKey is the key from API_KEY, which is granted every scope so stored keys can be minted
+var rootKey string
+
+// Init opens the API key store and loads the root key
+func Init(cfg *utils.Config) {
+	rootKey = cfg.Get("API_KEY")
+
+	if databaseURL := database.URLFromConfig(cfg); databaseURL != "" {
+		store, err := apikey.NewStore(databaseURL)
+		if err != nil {
+			log.Fatalf("[API-KEY]: Failed to initialize API key store: %v", err)
+		}
+		keyStore = store
+	} else {
+		log.Println("[API-KEY]: Warning, DATABASE_URL not set, only API_KEY will be accepted")
+	}
+
+	if rootKey == "" {
+		if keyStore == nil {
+			log.Fatal("[API-KEY]: API_KEY not set in environment")
+		}
+		log.Println("[API-KEY]: Warning, API_KEY not set, only stored keys will be accepted")
+	}
+}
+
+// RequireScope is a middleware that checks the request's API key is valid and granted the given scope
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := authenticate(c, c.GetHeader(API_KEY_HEADER))
+		if err != nil {
+			if !errors.Is(err, apikey.ErrInvalidKey) {
+				log.Printf("[API-KEY]: Failed to authenticate key: %v", err)
+			}
+			c.JSON(api_types.NewFailResponse(http.StatusForbidden, "Invalid API Key").AsGinResponse())
+			c.Abort()
+			return
+		}
+
+		if !key.HasScope(scope) {
+			c.JSON(api_types.NewFailResponse(http.StatusForbidden, "API key is missing the '"+scope+"' scope").AsGinResponse())
+			c.Abort()
+			return
+		}
+
+		c.Set(contextKey, key)
+		c.Next()
+	}
+}
+
+// RequireActingUser is a middleware that finds the user a request acts for. Keys bound to a user always act for
+// that user; other keys must name one in the X-USER-ID header, unless they are admin keys acting for every user
+func RequireActingUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader(USER_ID_HEADER)
+
+		if bound := BoundUserID(c); bound != "" {
+			if userID != "" && userID != bound {
+				c.JSON(api_types.NewFailResponse(http.StatusForbidden, "API key can't act for other users").AsGinResponse())
+				c.Abort()
+				return
+			}
+			userID = bound
+		}
+
+		if userID == "" {
+			if key := KeyFromContext(c); key == nil || !key.HasScope(apikey.ScopeKeysAdmin) {
+				c.JSON(api_types.NewFailResponse(http.StatusBadRequest, "Missing "+USER_ID_HEADER+" header").AsGinResponse())
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set(actingUserKey, userID)
+		c.Next()
+	}
+}
+
+// ActingUserID returns the user the request acts for, or an empty string if an admin key is acting for every user
+func ActingUserID(c *gin.Context) string {
+	return c.GetString(actingUserKey)
+}
+
+// KeyFromContext returns the API key that authenticated the request, or nil if there wasn't one
+func KeyFromContext(c *gin.Context) *apikey.Key {
+	if value, ok := c.Get(contextKey); ok {
+		if key, ok := value.(*apikey.Key); ok {
+			return key
+		}
+	}
+	return nil
+}
+
+// BoundUserID returns the user the request's API key is bound to, or an empty string if it can act for any user
+func BoundUserID(c *gin.Context) string {
+	if key := KeyFromContext(c); key != nil {
+		return key.UserID
+	}
+	return ""
+}
+
+// BoundClientID returns the outreach implementation the request's API key delivers reminders with, or an empty string if there isn't one
+func BoundClientID(c *gin.Context) string {
+	if key := KeyFromContext(c); key != nil {
+		return key.ClientID
+	}
+	return ""
+}
+
+// authenticate finds the key matching a raw key, checking the root key before the store
+func authenticate(c *gin.Context, raw string) (*apikey.Key, error) {
+	if raw == "" {
+		return nil, apikey.ErrInvalidKey
+	}
+
+	if rootKey != "" && subtle.ConstantTimeCompare([]byte(raw), []byte(rootKey)) == 1 {
+		return &apikey.Key{Name: "API_KEY", Scopes: apikey.AllScopes}, nil
+	}
+
+	if keyStore == nil {
+		return nil, apikey.ErrInvalidKey
+	}
+	return keyStore.Authenticate(c.Request.Context(), raw)
+}
+
+// getStore returns the API key store instance
+func getStore() *apikey.Store {
+	return keyStore
+}
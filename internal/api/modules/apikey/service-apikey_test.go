@@ -0,0 +1,134 @@
+package apikey_module
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethanbaker/assistant/internal/stores/apikey"
+	"github.com/ethanbaker/assistant/internal/stores/storetest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := storetest.New(t, apikey.NewStore)
+
+	keyStore, rootKey = store, "root-key"
+	t.Cleanup(func() { keyStore, rootKey = nil, "" })
+
+	_, userKey, err := store.CreateKey(context.Background(), "discord", apikey.Scopes{apikey.ScopeAgentWrite}, "user-a", "")
+	require.NoError(t, err)
+
+	engine := gin.New()
+	engine.GET("/agent", RequireScope(apikey.ScopeAgentRead), func(c *gin.Context) {
+		c.String(http.StatusOK, BoundUserID(c))
+	})
+	engine.GET("/memory", RequireScope(apikey.ScopeMemoryRead), func(c *gin.Context) {
+		c.String(http.StatusOK, BoundUserID(c))
+	})
+
+	request := func(path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if key != "" {
+			req.Header.Set(API_KEY_HEADER, key)
+		}
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The root key has every scope and isn't bound to a user
+	rec := request("/memory", "root-key")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// Stored keys are limited to their scopes and report their user
+	rec = request("/agent", userKey)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "user-a", rec.Body.String())
+
+	assert.Equal(t, http.StatusForbidden, request("/memory", userKey).Code)
+	assert.Equal(t, http.StatusForbidden, request("/agent", "wrong-key").Code)
+	assert.Equal(t, http.StatusForbidden, request("/agent", "").Code)
+}
+
+func TestRequireActingUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := storetest.New(t, apikey.NewStore)
+
+	keyStore, rootKey = store, "root-key"
+	t.Cleanup(func() { keyStore, rootKey = nil, "" })
+
+	_, boundKey, err := store.CreateKey(context.Background(), "discord", apikey.Scopes{apikey.ScopeAgentWrite}, "user-a", "")
+	require.NoError(t, err)
+	_, clientKey, err := store.CreateKey(context.Background(), "listen", apikey.Scopes{apikey.ScopeAgentWrite}, "", "")
+	require.NoError(t, err)
+
+	engine := gin.New()
+	engine.GET("/agent", RequireScope(apikey.ScopeAgentRead), RequireActingUser(), func(c *gin.Context) {
+		c.String(http.StatusOK, ActingUserID(c))
+	})
+
+	request := func(key, userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/agent", nil)
+		req.Header.Set(API_KEY_HEADER, key)
+		if userID != "" {
+			req.Header.Set(USER_ID_HEADER, userID)
+		}
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Bound keys always act for their user
+	rec := request(boundKey, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "user-a", rec.Body.String())
+	assert.Equal(t, http.StatusForbidden, request(boundKey, "user-b").Code)
+
+	// Other keys must name a user, unless they are admin keys
+	rec = request(clientKey, "user-b")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "user-b", rec.Body.String())
+	assert.Equal(t, http.StatusBadRequest, request(clientKey, "").Code)
+
+	rec = request("root-key", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestBoundClientID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := storetest.New(t, apikey.NewStore)
+
+	keyStore, rootKey = store, "root-key"
+	t.Cleanup(func() { keyStore, rootKey = nil, "" })
+
+	_, discordKey, err := store.CreateKey(context.Background(), "discord", apikey.Scopes{apikey.ScopeAgentWrite}, "", "discord")
+	require.NoError(t, err)
+
+	engine := gin.New()
+	engine.GET("/agent", RequireScope(apikey.ScopeAgentRead), func(c *gin.Context) {
+		c.String(http.StatusOK, BoundClientID(c))
+	})
+
+	request := func(key string) string {
+		req := httptest.NewRequest(http.MethodGet, "/agent", nil)
+		req.Header.Set(API_KEY_HEADER, key)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.String()
+	}
+
+	// Only keys bound to an implementation deliver reminders through it
+	assert.Equal(t, "discord", request(discordKey))
+	assert.Empty(t, request("root-key"))
+}
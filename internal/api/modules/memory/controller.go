@@ -0,0 +1,402 @@
+package memory_module
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	apikey_module "github.com/ethanbaker/assistant/internal/api/modules/apikey"
+	"github.com/ethanbaker/assistant/internal/stores/memory"
+	"github.com/ethanbaker/assistant/internal/stores/vector"
+	"github.com/ethanbaker/assistant/pkg/sdk"
+	"github.com/gin-gonic/gin"
+)
+
+// ListFacts handles GET requests to list a user's facts
+func ListFacts(c *gin.Context) {
+	// Parse query parameters
+	var req sdk.ListFactsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse query parameters", err).AsGinResponse())
+		return
+	}
+	if !canAccessUser(c, req.UserID) {
+		return
+	}
+
+	facts, err := getStore().ListFactsByTag(c.Request.Context(), req.UserID, req.Tag)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to list facts", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Facts retrieved successfully", toSDKFacts(facts)).AsGinResponse())
+}
+
+// CreateFact handles POST requests to store a new fact
+func CreateFact(c *gin.Context) {
+	// Parse request body
+	var req sdk.CreateFactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse request body", err).AsGinResponse())
+		return
+	}
+	if !canAccessUser(c, req.UserID) {
+		return
+	}
+	if err := validateExpiry(req.ExpiresAt); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Invalid expiry time", err).AsGinResponse())
+		return
+	}
+
+	// Make sure the fact doesn't exist yet
+	store := getStore()
+	existing, err := store.GetFact(c.Request.Context(), req.UserID, req.Key)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to check existing fact", err).AsGinResponse())
+		return
+	}
+	if existing != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusConflict, "Fact already exists", nil).AsGinResponse())
+		return
+	}
+
+	fact := newKeyFact(req.UserID, req.Key, req.Value, req.Tags, req.ExpiresAt)
+	if err := store.SetFact(c.Request.Context(), fact); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to create fact", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Fact created successfully", toSDKFact(fact)).AsGinResponse())
+}
+
+// GetFact handles GET requests to retrieve a user's fact by key
+func GetFact(c *gin.Context) {
+	key := c.Param("key")
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Missing user_id query parameter", nil).AsGinResponse())
+		return
+	}
+	if !canAccessUser(c, userID) {
+		return
+	}
+
+	fact, err := getStore().GetFact(c.Request.Context(), userID, key)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to get fact", err).AsGinResponse())
+		return
+	}
+	if fact == nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusNotFound, "Fact not found", nil).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Fact retrieved successfully", toSDKFact(fact)).AsGinResponse())
+}
+
+// UpdateFact handles PUT requests to replace an existing fact's value, tags and expiry
+func UpdateFact(c *gin.Context) {
+	key := c.Param("key")
+
+	// Parse request body
+	var req sdk.UpdateFactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse request body", err).AsGinResponse())
+		return
+	}
+	if !canAccessUser(c, req.UserID) {
+		return
+	}
+	if err := validateExpiry(req.ExpiresAt); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Invalid expiry time", err).AsGinResponse())
+		return
+	}
+
+	// Make sure the fact exists
+	store := getStore()
+	existing, err := store.GetFact(c.Request.Context(), req.UserID, key)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to get fact", err).AsGinResponse())
+		return
+	}
+	if existing == nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusNotFound, "Fact not found", nil).AsGinResponse())
+		return
+	}
+
+	fact := newKeyFact(req.UserID, key, req.Value, req.Tags, req.ExpiresAt)
+	if err := store.SetFact(c.Request.Context(), fact); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to update fact", err).AsGinResponse())
+		return
+	}
+	fact.CreatedAt = existing.CreatedAt
+
+	c.JSON(sdk.NewSuccessResponse("Fact updated successfully", toSDKFact(fact)).AsGinResponse())
+}
+
+// DeleteFact handles DELETE requests to delete a user's fact by key
+func DeleteFact(c *gin.Context) {
+	key := c.Param("key")
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Missing user_id query parameter", nil).AsGinResponse())
+		return
+	}
+	if !canAccessUser(c, userID) {
+		return
+	}
+
+	if err := getStore().DeleteFact(c.Request.Context(), userID, key, ""); err != nil {
+		if errors.Is(err, memory.ErrFactNotFound) {
+			c.JSON(sdk.NewErrorResponse(http.StatusNotFound, "Fact not found", err).AsGinResponse())
+			return
+		}
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to delete fact", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccess("Fact deleted successfully").AsGinResponse())
+}
+
+// SearchFacts handles GET requests to search a user's facts by text or by meaning
+func SearchFacts(c *gin.Context) {
+	// Parse query parameters
+	var req sdk.SearchFactsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse query parameters", err).AsGinResponse())
+		return
+	}
+	if !canAccessUser(c, req.UserID) {
+		return
+	}
+
+	store := getStore()
+
+	// Text search
+	if !req.Semantic {
+		facts, err := store.SearchFacts(c.Request.Context(), req.UserID, req.Query)
+		if err != nil {
+			c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to search facts", err).AsGinResponse())
+			return
+		}
+
+		c.JSON(sdk.NewSuccessResponse("Facts retrieved successfully", toSDKFacts(facts)).AsGinResponse())
+		return
+	}
+
+	// Semantic search
+	limit := req.Limit
+	if limit <= 0 {
+		limit = vector.DEFAULT_SEARCH_LIMIT
+	}
+
+	scored, err := store.SearchFactsSemantic(c.Request.Context(), req.UserID, req.Query, limit)
+	if err != nil {
+		if errors.Is(err, memory.ErrSemanticSearchDisabled) {
+			c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Semantic search is not enabled", err).AsGinResponse())
+			return
+		}
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to search facts", err).AsGinResponse())
+		return
+	}
+
+	resp := []sdk.Fact{}
+	for _, match := range scored {
+		fact := toSDKFact(match.KeyFact)
+		fact.Score = match.Score
+		resp = append(resp, fact)
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Facts retrieved successfully", resp).AsGinResponse())
+}
+
+// ExportFacts handles GET requests to export all of a user's facts as JSON
+func ExportFacts(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Missing user_id query parameter", nil).AsGinResponse())
+		return
+	}
+	if !canAccessUser(c, userID) {
+		return
+	}
+
+	facts, err := getStore().ListAllFacts(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to export facts", err).AsGinResponse())
+		return
+	}
+
+	resp := sdk.FactExport{
+		UserID:     userID,
+		ExportedAt: time.Now().UTC(),
+		Facts:      toSDKFacts(facts),
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Facts exported successfully", resp).AsGinResponse())
+}
+
+// ImportFacts handles POST requests to import facts in bulk
+func ImportFacts(c *gin.Context) {
+	// Parse request body
+	var req sdk.ImportFactsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse request body", err).AsGinResponse())
+		return
+	}
+	if !canAccessUser(c, req.UserID) {
+		return
+	}
+
+	// Build the facts for the requested user
+	facts := make([]*memory.KeyFact, 0, len(req.Facts))
+	for i, f := range req.Facts {
+		if f.Key == "" {
+			c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, fmt.Sprintf("Fact %d is missing a key", i), nil).AsGinResponse())
+			return
+		}
+		facts = append(facts, newKeyFact(req.UserID, f.Key, f.Value, f.Tags, f.ExpiresAt))
+	}
+
+	result, err := getStore().ImportFacts(c.Request.Context(), facts, req.Overwrite)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to import facts", err).AsGinResponse())
+		return
+	}
+
+	resp := sdk.ImportFactsResponse{
+		Created: result.Created,
+		Updated: result.Updated,
+		Skipped: result.Skipped,
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Facts imported successfully", resp).AsGinResponse())
+}
+
+// GetFactHistory handles GET requests to list the revisions of a user's fact, newest first
+func GetFactHistory(c *gin.Context) {
+	key := c.Param("key")
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Missing user_id query parameter", nil).AsGinResponse())
+		return
+	}
+	if !canAccessUser(c, userID) {
+		return
+	}
+
+	revisions, err := getStore().GetFactHistory(c.Request.Context(), userID, key)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to get fact history", err).AsGinResponse())
+		return
+	}
+
+	resp := []sdk.FactRevision{}
+	for _, revision := range revisions {
+		resp = append(resp, toSDKFactRevision(revision))
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Fact history retrieved successfully", resp).AsGinResponse())
+}
+
+// RevertFact handles POST requests to undo a revision of a user's fact
+func RevertFact(c *gin.Context) {
+	key := c.Param("key")
+
+	// Parse request body
+	var req sdk.RevertFactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse request body", err).AsGinResponse())
+		return
+	}
+	if !canAccessUser(c, req.UserID) {
+		return
+	}
+
+	fact, err := getStore().RevertFact(c.Request.Context(), req.UserID, key, req.RevisionID, "")
+	if err != nil {
+		if errors.Is(err, memory.ErrFactNotFound) {
+			c.JSON(sdk.NewErrorResponse(http.StatusNotFound, "Fact revision not found", err).AsGinResponse())
+			return
+		}
+		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to revert fact", err).AsGinResponse())
+		return
+	}
+
+	// Reverting the revision that created a fact removes it
+	if fact == nil {
+		c.JSON(sdk.NewSuccess("Fact reverted and removed successfully").AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Fact reverted successfully", toSDKFact(fact)).AsGinResponse())
+}
+
+// Helper function to check the request's API key can access a user's facts, responding with an error if it can't
+func canAccessUser(c *gin.Context, userID string) bool {
+	if bound := apikey_module.BoundUserID(c); bound != "" && userID != bound {
+		c.JSON(sdk.NewErrorResponse(http.StatusForbidden, "API key can't access facts of other users", nil).AsGinResponse())
+		return false
+	}
+	return true
+}
+
+// Helper function to build a fact from request fields
+func newKeyFact(userID, key, value string, tags []string, expiresAt *time.Time) *memory.KeyFact {
+	fact := memory.NewKeyFact(userID, key, value)
+	fact.Tags = memory.NewFactTags(tags...)
+	if expiresAt != nil {
+		utc := expiresAt.UTC()
+		fact.ExpiresAt = &utc
+	}
+	return fact
+}
+
+// Helper function to check that an optional expiry time is in the future
+func validateExpiry(expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+	return nil
+}
+
+// Helper function to convert facts to their SDK representation
+func toSDKFacts(facts []*memory.KeyFact) []sdk.Fact {
+	resp := []sdk.Fact{}
+	for _, fact := range facts {
+		resp = append(resp, toSDKFact(fact))
+	}
+	return resp
+}
+
+// Helper function to convert a fact to its SDK representation
+func toSDKFact(fact *memory.KeyFact) sdk.Fact {
+	return sdk.Fact{
+		ID:              fact.ID,
+		CreatedAt:       fact.CreatedAt,
+		UpdatedAt:       fact.UpdatedAt,
+		UserID:          fact.UserID,
+		Key:             fact.Key,
+		Value:           fact.Value,
+		Tags:            fact.Tags,
+		SourceSessionID: fact.SourceSessionID,
+		ExpiresAt:       fact.ExpiresAt,
+	}
+}
+
+// Helper function to convert a fact revision to its SDK representation
+func toSDKFactRevision(revision *memory.FactRevision) sdk.FactRevision {
+	return sdk.FactRevision{
+		ID:            revision.ID,
+		CreatedAt:     revision.CreatedAt,
+		FactID:        revision.FactID,
+		UserID:        revision.UserID,
+		Key:           revision.Key,
+		Operation:     revision.Operation,
+		PreviousValue: revision.PreviousValue,
+		NewValue:      revision.NewValue,
+		SessionID:     revision.SessionID,
+	}
+}
@@ -0,0 +1,140 @@
+package memory_module
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	apikey_module "github.com/ethanbaker/assistant/internal/api/modules/apikey"
+	"github.com/ethanbaker/assistant/internal/stores/apikey"
+	"github.com/ethanbaker/assistant/internal/stores/memory"
+	"github.com/ethanbaker/assistant/internal/stores/storetest"
+	"github.com/ethanbaker/assistant/pkg/sdk"
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKeys are the raw API keys the test server accepts
+type testKeys struct {
+	root, reader, boundWriter string
+}
+
+// newTestServer creates an engine with the memory routes, backed by SQLite stores
+func newTestServer(t *testing.T) (*gin.Engine, testKeys) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	// Keys are shared through a database file so they can be created outside of the API key module
+	databaseURL := "sqlite://" + filepath.Join(t.TempDir(), "keys.db")
+	cfg := utils.NewConfig(map[string]string{
+		"API_KEY":      "root-key",
+		"DATABASE_URL": databaseURL,
+	})
+	apikey_module.Init(cfg)
+
+	keyStore, err := apikey.NewStore(databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(func() { keyStore.Close() })
+
+	_, reader, err := keyStore.CreateKey(ctx, "reader", apikey.Scopes{apikey.ScopeMemoryRead}, "", "")
+	require.NoError(t, err)
+	_, boundWriter, err := keyStore.CreateKey(ctx, "discord", apikey.Scopes{apikey.ScopeMemoryWrite}, "user-a", "")
+	require.NoError(t, err)
+
+	store := storetest.New(t, memory.NewStore)
+	Init(store)
+
+	engine := gin.New()
+	RegisterRoutes(engine.Group("/api"), cfg)
+
+	return engine, testKeys{root: "root-key", reader: reader, boundWriter: boundWriter}
+}
+
+// Helper function to send a request to the test server
+func doRequest(engine *gin.Engine, method, path, key string, body any) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(apikey_module.API_KEY_HEADER, key)
+	}
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMemoryRoutesScopes(t *testing.T) {
+	engine, keys := newTestServer(t)
+	fact := sdk.CreateFactRequest{UserID: "user-a", Key: "coffee", Value: "latte"}
+
+	// Requests without a valid key are rejected
+	assert.Equal(t, http.StatusForbidden, doRequest(engine, http.MethodGet, "/api/memory/facts?user_id=user-a", "", nil).Code)
+	assert.Equal(t, http.StatusForbidden, doRequest(engine, http.MethodGet, "/api/memory/facts?user_id=user-a", "wrong-key", nil).Code)
+
+	// Read keys can't write
+	assert.Equal(t, http.StatusForbidden, doRequest(engine, http.MethodPost, "/api/memory/facts", keys.reader, fact).Code)
+	assert.Equal(t, http.StatusForbidden, doRequest(engine, http.MethodDelete, "/api/memory/facts/coffee?user_id=user-a", keys.reader, nil).Code)
+
+	// Write keys can read and write
+	assert.Equal(t, http.StatusOK, doRequest(engine, http.MethodPost, "/api/memory/facts", keys.boundWriter, fact).Code)
+	assert.Equal(t, http.StatusOK, doRequest(engine, http.MethodGet, "/api/memory/facts/coffee?user_id=user-a", keys.boundWriter, nil).Code)
+
+	// Unbound read keys can read any user's facts
+	assert.Equal(t, http.StatusOK, doRequest(engine, http.MethodGet, "/api/memory/facts/coffee?user_id=user-a", keys.reader, nil).Code)
+}
+
+func TestMemoryRoutesOtherUsers(t *testing.T) {
+	engine, keys := newTestServer(t)
+
+	other := sdk.CreateFactRequest{UserID: "user-b", Key: "coffee", Value: "espresso"}
+	require.Equal(t, http.StatusOK, doRequest(engine, http.MethodPost, "/api/memory/facts", keys.root, other).Code)
+
+	// A key bound to a user can't touch another user's facts
+	requests := []struct {
+		method, path string
+		body         any
+	}{
+		{http.MethodGet, "/api/memory/facts?user_id=user-b", nil},
+		{http.MethodPost, "/api/memory/facts", sdk.CreateFactRequest{UserID: "user-b", Key: "tea", Value: "green"}},
+		{http.MethodGet, "/api/memory/facts/coffee?user_id=user-b", nil},
+		{http.MethodPut, "/api/memory/facts/coffee", sdk.UpdateFactRequest{UserID: "user-b", Value: "mocha"}},
+		{http.MethodDelete, "/api/memory/facts/coffee?user_id=user-b", nil},
+		{http.MethodGet, "/api/memory/search?user_id=user-b&query=coffee", nil},
+		{http.MethodGet, "/api/memory/export?user_id=user-b", nil},
+		{http.MethodPost, "/api/memory/import", sdk.ImportFactsRequest{UserID: "user-b", Facts: []sdk.Fact{}}},
+		{http.MethodGet, "/api/memory/facts/coffee/history?user_id=user-b", nil},
+		{http.MethodPost, "/api/memory/facts/coffee/revert", sdk.RevertFactRequest{UserID: "user-b", RevisionID: 1}},
+	}
+	for _, r := range requests {
+		rec := doRequest(engine, r.method, r.path, keys.boundWriter, r.body)
+		assert.Equal(t, http.StatusForbidden, rec.Code, "%s %s", r.method, r.path)
+	}
+
+	// The other user's fact is untouched
+	rec := doRequest(engine, http.MethodGet, "/api/memory/facts/coffee?user_id=user-b", keys.root, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "espresso")
+}
+
+func TestMemoryRoutesNotFound(t *testing.T) {
+	engine, keys := newTestServer(t)
+
+	assert.Equal(t, http.StatusNotFound, doRequest(engine, http.MethodGet, "/api/memory/facts/missing?user_id=user-a", keys.boundWriter, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(engine, http.MethodDelete, "/api/memory/facts/missing?user_id=user-a", keys.boundWriter, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(engine, http.MethodPut, "/api/memory/facts/missing", keys.boundWriter, sdk.UpdateFactRequest{UserID: "user-a", Value: "x"}).Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(engine, http.MethodPost, "/api/memory/facts/missing/revert", keys.boundWriter, sdk.RevertFactRequest{UserID: "user-a", RevisionID: 42}).Code)
+}
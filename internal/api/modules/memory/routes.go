@@ -0,0 +1,32 @@
+package memory_module
+
+import (
+	apikey_module "github.com/ethanbaker/assistant/internal/api/modules/apikey"
+	"github.com/ethanbaker/assistant/internal/stores/apikey"
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Register routes for the memory module
+func RegisterRoutes(g *gin.RouterGroup, cfg *utils.Config) {
+	// Create base group for memory routes
+	group := g.Group("/memory")
+	read := apikey_module.RequireScope(apikey.ScopeMemoryRead)
+	write := apikey_module.RequireScope(apikey.ScopeMemoryWrite)
+
+	// Fact management routes
+	group.GET("/facts", read, ListFacts)           // List a user's facts, optionally filtered by tag
+	group.POST("/facts", write, CreateFact)        // Store a new fact
+	group.GET("/facts/:key", read, GetFact)        // Get a user's fact by key
+	group.PUT("/facts/:key", write, UpdateFact)    // Replace an existing fact
+	group.DELETE("/facts/:key", write, DeleteFact) // Delete a user's fact
+
+	// Search and bulk routes
+	group.GET("/search", read, SearchFacts)   // Search a user's facts by text or meaning
+	group.GET("/export", read, ExportFacts)   // Export all of a user's facts as JSON
+	group.POST("/import", write, ImportFacts) // Import facts from JSON
+
+	// Fact history routes
+	group.GET("/facts/:key/history", read, GetFactHistory) // List the revisions of a user's fact
+	group.POST("/facts/:key/revert", write, RevertFact)    // Undo a revision of a user's fact
+}
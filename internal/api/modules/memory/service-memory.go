@@ -0,0 +1,23 @@
+package memory_module
+
+import (
+	"log"
+
+	"github.com/ethanbaker/assistant/internal/stores/memory"
+)
+
+// memoryStore is the fact store shared with the agents
+var memoryStore *memory.Store
+
+// Init sets the memory store used by the memory routes
+func Init(store *memory.Store) {
+	memoryStore = store
+}
+
+// getStore returns the memory store instance
+func getStore() *memory.Store {
+	if memoryStore == nil {
+		log.Fatal("[MEMORY]: Memory store is not initialized")
+	}
+	return memoryStore
+}
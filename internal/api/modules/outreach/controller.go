@@ -1,8 +1,12 @@
 package outreach_module
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/ethanbaker/assistant/pkg/outreach"
 	"github.com/ethanbaker/assistant/pkg/sdk"
 	"github.com/gin-gonic/gin"
 )
@@ -17,13 +21,13 @@ func RegisterImplementation(c *gin.Context) {
 	}
 
 	// Get service and register implementation
-	if err := outreachService.RegisterImplementation(&req); err != nil {
+	resp, err := outreachService.RegisterImplementation(&req)
+	if err != nil {
 		c.JSON(sdk.NewErrorResponse(http.StatusInternalServerError, "Failed to register implementation", err).AsGinResponse())
 		return
 	}
 
 	// Return success response
-	resp := &sdk.OutreachRegisterResponse{ClientId: req.ClientId}
 	c.JSON(sdk.NewSuccessResponse("Implementation registered successfully", resp).AsGinResponse())
 }
 
@@ -45,6 +49,40 @@ func UnregisterImplementation(c *gin.Context) {
 	c.JSON(sdk.NewSuccess("Implementation unregistered successfully").AsGinResponse())
 }
 
+// RotateImplementationSecret handles POST requests to rotate the authenticated implementation's client secret
+func RotateImplementationSecret(c *gin.Context) {
+	clientID, ok := GetAuthenticatedClient(c)
+	if !ok {
+		c.JSON(sdk.NewErrorResponse(http.StatusUnauthorized, "Client not authenticated", nil).AsGinResponse())
+		return
+	}
+
+	// Parse request body
+	var req sdk.OutreachRotateSecretRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse request body", err).AsGinResponse())
+		return
+	}
+
+	gracePeriod := outreach.DEFAULT_SECRET_GRACE_PERIOD
+	if req.GracePeriodSeconds != nil {
+		if *req.GracePeriodSeconds < 0 {
+			c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "grace_period_seconds cannot be negative", nil).AsGinResponse())
+			return
+		}
+		gracePeriod = time.Duration(*req.GracePeriodSeconds) * time.Second
+	}
+
+	// Get service and rotate secret
+	resp, err := outreachService.RotateSecret(clientID, req.NewClientSecret, gracePeriod)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Failed to rotate client secret", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Client secret rotated successfully", resp).AsGinResponse())
+}
+
 // GetImplementations handles GET requests to list all registered implementations
 func GetImplementations(c *gin.Context) {
 	// Get service and list implementations
@@ -64,3 +102,221 @@ func GetStatus(c *gin.Context) {
 
 	c.JSON(sdk.NewSuccessResponse("Status retrieved successfully", status).AsGinResponse())
 }
+
+// ListDeliveries handles GET requests to list the stored responses sent to the authenticated client and their delivery attempts
+func ListDeliveries(c *gin.Context) {
+	clientID, ok := GetAuthenticatedClient(c)
+	if !ok {
+		c.JSON(sdk.NewErrorResponse(http.StatusUnauthorized, "Client not authenticated", nil).AsGinResponse())
+		return
+	}
+
+	// Parse query parameters
+	var req sdk.ListOutreachDeliveriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse query parameters", err).AsGinResponse())
+		return
+	}
+
+	deliveries, err := outreachService.ListDeliveries(clientID, &req)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Failed to list deliveries", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Deliveries retrieved successfully", deliveries).AsGinResponse())
+}
+
+// ReplayDelivery handles POST requests to send a delivered or dead-lettered response again
+func ReplayDelivery(c *gin.Context) {
+	clientID, ok := GetAuthenticatedClient(c)
+	if !ok {
+		c.JSON(sdk.NewErrorResponse(http.StatusUnauthorized, "Client not authenticated", nil).AsGinResponse())
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Invalid delivery ID", err).AsGinResponse())
+		return
+	}
+
+	delivery, err := outreachService.ReplayDelivery(clientID, uint(id))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, outreach.ErrDeliveryNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, outreach.ErrDeliveryInProgress):
+			status = http.StatusConflict
+		}
+
+		c.JSON(sdk.NewErrorResponse(status, "Failed to replay delivery", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Delivery queued for replay", delivery).AsGinResponse())
+}
+
+// ListTasks handles GET requests to list all loaded tasks
+func ListTasks(c *gin.Context) {
+	c.JSON(sdk.NewSuccessResponse("Tasks retrieved successfully", outreachService.ListTasks()).AsGinResponse())
+}
+
+// CreateTask handles POST requests to add and schedule a new task
+func CreateTask(c *gin.Context) {
+	clientID, ok := GetAuthenticatedClient(c)
+	if !ok {
+		c.JSON(sdk.NewErrorResponse(http.StatusUnauthorized, "Client not authenticated", nil).AsGinResponse())
+		return
+	}
+
+	// Parse request body
+	var req sdk.CreateOutreachTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse request body", err).AsGinResponse())
+		return
+	}
+
+	task, err := outreachService.CreateTask(clientID, &req)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(taskErrorStatus(err), "Failed to create task", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Task created successfully", task).AsGinResponse())
+}
+
+// GetTask handles GET requests to retrieve a single task
+func GetTask(c *gin.Context) {
+	task, err := outreachService.GetTask(c.Param("key"))
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(taskErrorStatus(err), "Failed to get task", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Task retrieved successfully", task).AsGinResponse())
+}
+
+// UpdateTask handles PUT requests to replace and reschedule a task
+func UpdateTask(c *gin.Context) {
+	clientID, ok := GetAuthenticatedClient(c)
+	if !ok {
+		c.JSON(sdk.NewErrorResponse(http.StatusUnauthorized, "Client not authenticated", nil).AsGinResponse())
+		return
+	}
+
+	// Parse request body
+	var req sdk.UpdateOutreachTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse request body", err).AsGinResponse())
+		return
+	}
+
+	task, err := outreachService.UpdateTask(clientID, c.Param("key"), &req)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(taskErrorStatus(err), "Failed to update task", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Task updated successfully", task).AsGinResponse())
+}
+
+// RemoveTask handles DELETE requests to unschedule and delete a task
+func RemoveTask(c *gin.Context) {
+	clientID, ok := GetAuthenticatedClient(c)
+	if !ok {
+		c.JSON(sdk.NewErrorResponse(http.StatusUnauthorized, "Client not authenticated", nil).AsGinResponse())
+		return
+	}
+
+	if err := outreachService.RemoveTask(clientID, c.Param("key")); err != nil {
+		c.JSON(sdk.NewErrorResponse(taskErrorStatus(err), "Failed to remove task", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccess("Task removed successfully").AsGinResponse())
+}
+
+// PauseTask handles POST requests to stop a task from running
+func PauseTask(c *gin.Context) {
+	clientID, ok := GetAuthenticatedClient(c)
+	if !ok {
+		c.JSON(sdk.NewErrorResponse(http.StatusUnauthorized, "Client not authenticated", nil).AsGinResponse())
+		return
+	}
+
+	task, err := outreachService.PauseTask(clientID, c.Param("key"))
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(taskErrorStatus(err), "Failed to pause task", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Task paused successfully", task).AsGinResponse())
+}
+
+// ResumeTask handles POST requests to schedule a paused task again
+func ResumeTask(c *gin.Context) {
+	clientID, ok := GetAuthenticatedClient(c)
+	if !ok {
+		c.JSON(sdk.NewErrorResponse(http.StatusUnauthorized, "Client not authenticated", nil).AsGinResponse())
+		return
+	}
+
+	task, err := outreachService.ResumeTask(clientID, c.Param("key"))
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(taskErrorStatus(err), "Failed to resume task", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Task resumed successfully", task).AsGinResponse())
+}
+
+// RunTask handles POST requests to run a task immediately, optionally as a preview that isn't delivered
+func RunTask(c *gin.Context) {
+	clientID, ok := GetAuthenticatedClient(c)
+	if !ok {
+		c.JSON(sdk.NewErrorResponse(http.StatusUnauthorized, "Client not authenticated", nil).AsGinResponse())
+		return
+	}
+
+	// Parse query parameters
+	var req sdk.RunOutreachTaskRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(sdk.NewErrorResponse(http.StatusBadRequest, "Could not parse query parameters", err).AsGinResponse())
+		return
+	}
+
+	resp, err := outreachService.RunTask(clientID, c.Param("key"), req.Preview)
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(taskErrorStatus(err), "Failed to run task", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Task ran successfully", resp).AsGinResponse())
+}
+
+// GetTaskRuns handles GET requests to list a task's most recent runs
+func GetTaskRuns(c *gin.Context) {
+	runs, err := outreachService.GetTaskRuns(c.Param("key"))
+	if err != nil {
+		c.JSON(sdk.NewErrorResponse(taskErrorStatus(err), "Failed to get task runs", err).AsGinResponse())
+		return
+	}
+
+	c.JSON(sdk.NewSuccessResponse("Task runs retrieved successfully", runs).AsGinResponse())
+}
+
+// taskErrorStatus picks the HTTP status for an error from a task operation
+func taskErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, outreach.ErrTaskNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, outreach.ErrTaskExists):
+		return http.StatusConflict
+	case errors.Is(err, errTaskNotOwned):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
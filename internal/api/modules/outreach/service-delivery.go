@@ -0,0 +1,268 @@
+package outreach_module
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/telemetry"
+	"github.com/ethanbaker/assistant/pkg/outreach"
+	"github.com/ethanbaker/assistant/pkg/sdk"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// MAX_SEND_OUTREACH_RETRIES is the number of rounds through a response's clients before it is dead-lettered
+const MAX_SEND_OUTREACH_RETRIES = 5
+
+// Backoff between delivery rounds, doubled each round up to the maximum
+const (
+	OUTREACH_RETRY_BASE_DELAY = 5 * time.Second
+	OUTREACH_RETRY_MAX_DELAY  = 10 * time.Minute
+)
+
+// DEFAULT_DELIVERIES_LIMIT is the number of deliveries listed when no limit is given
+const DEFAULT_DELIVERIES_LIMIT = 50
+
+// resumeDeliveries restarts deliveries that were pending or retrying when the service last stopped
+func (s *OutreachService) resumeDeliveries() {
+	for _, status := range []outreach.DeliveryStatus{outreach.DeliveryPending, outreach.DeliveryRetrying} {
+		deliveries, err := s.store.ListDeliveries(outreach.DeliveryFilter{Status: status})
+		if err != nil {
+			log.Printf("[OUTREACH]: Failed to load %s deliveries: %v", status, err)
+			continue
+		}
+
+		for _, delivery := range deliveries {
+			go s.deliver(delivery)
+		}
+	}
+}
+
+// deliver sends a response to its clients in priority order, retrying with backoff until a client accepts it.
+// Deliveries that run out of retries are moved to the dead-letter table.
+func (s *OutreachService) deliver(delivery *outreach.Delivery) {
+	ctx, span := telemetry.StartSpan(s.ctx, "outreach.delivery",
+		attribute.Int64("delivery.id", int64(delivery.ID)),
+		attribute.String("task.key", delivery.Response.Key),
+	)
+	defer span.End()
+
+	for {
+		// Wait until the next round is due
+		if delivery.NextAttemptAt != nil {
+			if wait := time.Until(*delivery.NextAttemptAt); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-s.ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+		}
+
+		if s.deliverRound(ctx, delivery) {
+			return
+		}
+
+		delivery.Rounds++
+		if delivery.Rounds >= MAX_SEND_OUTREACH_RETRIES {
+			reason := fmt.Sprintf("gave up after %d rounds: %s", delivery.Rounds, delivery.LastError)
+			if err := s.store.DeadLetterDelivery(delivery.ID, reason); err != nil {
+				log.Printf("[OUTREACH]: Failed to dead-letter delivery %d: %v", delivery.ID, err)
+			}
+
+			span.SetStatus(codes.Error, reason)
+			log.Printf("[OUTREACH]: Delivery %d for task '%s' moved to the dead-letter table: %s", delivery.ID, delivery.Response.Key, reason)
+			s.manager.RecordDeliveryOutcome(delivery.Response.IdempotencyId, false, reason)
+			return
+		}
+
+		next := time.Now().Add(retryDelay(delivery.Rounds))
+		delivery.Status = outreach.DeliveryRetrying
+		delivery.NextAttemptAt = &next
+		if err := s.store.UpdateDelivery(delivery); err != nil {
+			log.Printf("[OUTREACH]: Failed to update delivery %d: %v", delivery.ID, err)
+		}
+	}
+}
+
+// deliverRound tries each of a response's clients once, in priority order, and returns whether one accepted it
+func (s *OutreachService) deliverRound(ctx context.Context, delivery *outreach.Delivery) bool {
+	for _, client := range delivery.Response.Clients {
+		attemptCtx, span := telemetry.StartSpan(ctx, "outreach.callback",
+			attribute.String("client.id", client.Id),
+			attribute.Int("delivery.round", delivery.Rounds),
+		)
+
+		start := time.Now()
+		statusCode, err := s.forwardResponseToClient(attemptCtx, client.Id, client.CallbackUrl, delivery.Response)
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+		telemetry.EndSpan(span, err)
+
+		// Record the attempt
+		attempt := &outreach.DeliveryAttempt{
+			DeliveryID: delivery.ID,
+			ClientID:   client.Id,
+			StatusCode: statusCode,
+			Latency:    time.Since(start),
+		}
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+		if recordErr := s.store.RecordDeliveryAttempt(attempt); recordErr != nil {
+			log.Printf("[OUTREACH]: Failed to record attempt for delivery %d: %v", delivery.ID, recordErr)
+		}
+		delivery.Attempts++
+
+		if err != nil {
+			log.Printf("[OUTREACH]: Failed to forward response to %s: %v", client.CallbackUrl, err)
+			delivery.LastError = err.Error()
+			continue
+		}
+
+		// Delivered
+		now := time.Now()
+		delivery.Status = outreach.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.DeliveredTo = client.Id
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		if err := s.store.UpdateDelivery(delivery); err != nil {
+			log.Printf("[OUTREACH]: Failed to update delivery %d: %v", delivery.ID, err)
+		}
+		s.manager.RecordDeliveryOutcome(delivery.Response.IdempotencyId, true, "")
+
+		return true
+	}
+
+	if len(delivery.Response.Clients) == 0 {
+		delivery.LastError = "response has no clients"
+	}
+
+	return false
+}
+
+// ListDeliveries returns stored responses with their delivery attempts and dead letters
+func (s *OutreachService) ListDeliveries(clientID string, req *sdk.ListOutreachDeliveriesRequest) ([]sdk.OutreachDelivery, error) {
+	filter := outreach.DeliveryFilter{
+		Status:   outreach.DeliveryStatus(req.Status),
+		ClientID: clientID,
+		Limit:    req.Limit,
+		Offset:   req.Offset,
+	}
+	if filter.Status != "" && !outreach.ValidateDeliveryStatus(filter.Status) {
+		return nil, fmt.Errorf("invalid delivery status '%s'", req.Status)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DEFAULT_DELIVERIES_LIMIT
+	}
+
+	deliveries, err := s.store.ListDeliveries(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sdkDeliveries := make([]sdk.OutreachDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		sdkDelivery, err := s.toSDKDelivery(delivery)
+		if err != nil {
+			return nil, err
+		}
+		sdkDeliveries = append(sdkDeliveries, *sdkDelivery)
+	}
+
+	return sdkDeliveries, nil
+}
+
+// ReplayDelivery sends a delivered or dead-lettered response for the client again
+func (s *OutreachService) ReplayDelivery(clientID string, id uint) (*sdk.OutreachDelivery, error) {
+	// Other clients' deliveries are reported as missing
+	delivery, err := s.store.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(delivery.Response.ClientIds(), clientID) {
+		return nil, fmt.Errorf("delivery %d: %w", id, outreach.ErrDeliveryNotFound)
+	}
+
+	delivery, err = s.store.ReplayDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[OUTREACH]: Replaying delivery %d for task '%s'", delivery.ID, delivery.Response.Key)
+	go s.deliver(delivery)
+
+	return s.toSDKDelivery(delivery)
+}
+
+/** ---- HELPERS ---- */
+
+// retryDelay returns the backoff before a delivery round, with jitter so failed deliveries don't retry in lockstep
+func retryDelay(round int) time.Duration {
+	delay := OUTREACH_RETRY_MAX_DELAY
+	if shift := round - 1; shift < 16 {
+		delay = min(OUTREACH_RETRY_BASE_DELAY<<shift, OUTREACH_RETRY_MAX_DELAY)
+	}
+
+	// Wait somewhere between half and all of the delay
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// toSDKDelivery converts a delivery, its attempts, and its dead letters to the API format
+func (s *OutreachService) toSDKDelivery(delivery *outreach.Delivery) (*sdk.OutreachDelivery, error) {
+	attempts, err := s.store.ListDeliveryAttempts(delivery.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters, err := s.store.ListDeadLetters(delivery.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sdkDelivery := &sdk.OutreachDelivery{
+		Id:            delivery.ID,
+		CreatedAt:     delivery.CreatedAt,
+		UpdatedAt:     delivery.UpdatedAt,
+		IdempotencyId: delivery.Response.IdempotencyId,
+		Key:           delivery.Response.Key,
+		Content:       delivery.Response.Content,
+		Status:        string(delivery.Status),
+		Rounds:        delivery.Rounds,
+		AttemptCount:  delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		DeliveredAt:   delivery.DeliveredAt,
+		DeliveredTo:   delivery.DeliveredTo,
+		LastError:     delivery.LastError,
+		Attempts:      make([]sdk.OutreachDeliveryAttempt, len(attempts)),
+		DeadLetters:   make([]sdk.OutreachDeadLetter, len(deadLetters)),
+	}
+
+	for i, attempt := range attempts {
+		sdkDelivery.Attempts[i] = sdk.OutreachDeliveryAttempt{
+			Id:         attempt.ID,
+			CreatedAt:  attempt.CreatedAt,
+			ClientId:   attempt.ClientID,
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			LatencyMs:  attempt.Latency.Milliseconds(),
+		}
+	}
+
+	for i, letter := range deadLetters {
+		sdkDelivery.DeadLetters[i] = sdk.OutreachDeadLetter{
+			Id:         letter.ID,
+			CreatedAt:  letter.CreatedAt,
+			Reason:     letter.Reason,
+			ReplayedAt: letter.ReplayedAt,
+		}
+	}
+
+	return sdkDelivery, nil
+}
@@ -0,0 +1,39 @@
+package outreach_module
+
+import (
+	"testing"
+
+	"github.com/ethanbaker/assistant/pkg/outreach"
+	"github.com/ethanbaker/assistant/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveriesAreLimitedToTheirClients(t *testing.T) {
+	service := newTestService(t)
+
+	response := &outreach.Response{
+		Key:           "digest",
+		IdempotencyId: "digest-1",
+		Clients: []struct {
+			Id          string `json:"id"`
+			CallbackUrl string `json:"callback_url"`
+		}{{Id: "discord", CallbackUrl: "http://localhost:8081/outreach"}},
+	}
+	delivery, err := service.store.CreateDelivery(response)
+	require.NoError(t, err)
+	require.NoError(t, service.store.DeadLetterDelivery(delivery.ID, "gave up"))
+
+	// Other clients don't see or replay the delivery
+	deliveries, err := service.ListDeliveries("other", &sdk.ListOutreachDeliveriesRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	_, err = service.ReplayDelivery("other", delivery.ID)
+	assert.ErrorIs(t, err, outreach.ErrDeliveryNotFound)
+
+	deliveries, err = service.ListDeliveries("discord", &sdk.ListOutreachDeliveriesRequest{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "digest-1", deliveries[0].IdempotencyId)
+}
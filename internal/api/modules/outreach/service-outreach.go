@@ -9,24 +9,30 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
+	reminderagent "github.com/ethanbaker/assistant/internal/agents/reminder"
+	"github.com/ethanbaker/assistant/internal/agents/schedule"
+	outreach_agentprompt "github.com/ethanbaker/assistant/internal/outreaches/agent-prompt"
 	outreach_dailydigest "github.com/ethanbaker/assistant/internal/outreaches/daily-digest"
 	outreach_notionschedule "github.com/ethanbaker/assistant/internal/outreaches/notion-schedule"
+	"github.com/ethanbaker/assistant/internal/stores/database"
+	"github.com/ethanbaker/assistant/internal/stores/memory"
 	outreach_store "github.com/ethanbaker/assistant/internal/stores/outreach"
 	"github.com/ethanbaker/assistant/pkg/outreach"
 	"github.com/ethanbaker/assistant/pkg/sdk"
 	"github.com/ethanbaker/assistant/pkg/utils"
-	"github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 	"gopkg.in/yaml.v3"
 )
 
-const MAX_SEND_OUTREACH_RETRIES = 3
-
 // OutreachService handles outreach operations and manages the outreach manager
 type OutreachService struct {
 	manager    *outreach.Manager
+	store      outreach.StoreInterface
 	httpClient *http.Client
 	ctx        context.Context
 	cancel     context.CancelFunc
@@ -35,22 +41,26 @@ type OutreachService struct {
 
 var outreachService *OutreachService
 
-// outreachTaskFunctions maps task keys to their corresponding run functions
+// outreachTaskFunctions maps task types to their corresponding run functions
 var outreachTaskFunctions = map[string]outreach.TaskRunFunction{
+	"agent-prompt":    outreach_agentprompt.RunAgentPrompt,
 	"daily-digest":    outreach_dailydigest.CreateDailyDigest,
 	"notion-schedule": outreach_notionschedule.NotionScheduleReminder,
+
+	outreach.REMINDER_TASK_TYPE: outreach.RunReminder,
 }
 
 // outreachInits contains a list of outreach initialization functions
 var outreachInits = map[string]func(cfg *utils.Config) error{
+	"agent-prompt":    outreach_agentprompt.Init,
 	"daily-digest":    outreach_dailydigest.Init,
 	"notion-schedule": outreach_notionschedule.Init,
 }
 
 /** ---- INIT ---- */
 
-// Init creates a new outreach service
-func Init(cfg *utils.Config) error {
+// Init creates a new outreach service. Agent prompt tasks remember facts with the given memory store
+func Init(cfg *utils.Config, memoryStore *memory.Store) error {
 	var err error
 
 	// Load manager config
@@ -69,30 +79,30 @@ func Init(cfg *utils.Config) error {
 		return fmt.Errorf("failed to parse outreach config file: %w", err)
 	}
 
-	// Create MySQL config
-	dbConfig := mysql.Config{
-		User:      cfg.Get("MYSQL_USERNAME"),
-		Passwd:    cfg.Get("MYSQL_ROOT_PASSWORD"),
-		Net:       "tcp",
-		Addr:      fmt.Sprintf("%s:%s", cfg.Get("MYSQL_HOST"), cfg.Get("MYSQL_PORT")),
-		DBName:    cfg.Get("MYSQL_DATABASE"),
-		ParseTime: true,
-	}
-
 	// Create store
 	var store outreach.StoreInterface
-	if dbConfig.DBName != "" {
+	if databaseURL := database.URLFromConfig(cfg); databaseURL != "" {
 		// Create sql store
-		if store, err = outreach_store.NewStore(dbConfig.FormatDSN()); err != nil {
+		if store, err = outreach_store.NewStore(databaseURL); err != nil {
 			return err
 		}
 	} else {
 		// Fallback to in-memory store
-		log.Println("[OUTREACH]: Warning, MYSQL_DATABASE not set, using in-memory store (data will not persist across restarts)")
+		log.Println("[OUTREACH]: Warning, DATABASE_URL not set, using in-memory store (data will not persist across restarts)")
 		store = outreach_store.NewInMemoryStore()
 	}
 
 	opts.Store = store
+	opts.TaskFunctions = outreachTaskFunctions
+
+	// Calendar event tasks use the schedule agent's calendars, if they are configured
+	if cfg.Get("GOOGLE_CALENDAR_CREDENTIALS_JSON") != "" {
+		if calendar, err := schedule.NewCalendarService(context.Background(), cfg); err != nil {
+			log.Printf("[OUTREACH]: Warning, failed to load calendars, calendar event tasks are disabled: %v", err)
+		} else {
+			opts.Calendar = calendar
+		}
+	}
 
 	// Create manager
 	manager, err := outreach.NewManager(cfg, &opts)
@@ -104,34 +114,46 @@ func Init(cfg *utils.Config) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	service := &OutreachService{
 		manager:    manager,
+		store:      store,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		ctx:        ctx,
 		cancel:     cancel,
 		mutex:      sync.RWMutex{},
 	}
 
-	// Start response listener
+	// Start response listener and pick up deliveries left unfinished by the last run
 	go service.listenForResponses()
+	service.resumeDeliveries()
 
 	taskPath := cfg.Get("OUTREACH_TASKS_PATH")
 
-	// Load tasks on startup
+	// Load tasks on startup; tasks saved through the API replace config tasks with the same key
 	if err := service.loadTasksFromConfig(taskPath); err != nil {
 		return fmt.Errorf("failed to load tasks from config: %w", err)
 	}
 
+	count, err := manager.LoadStoredTasks()
+	if err != nil {
+		return fmt.Errorf("failed to load stored tasks: %w", err)
+	}
+	log.Printf("[OUTREACH]: Loaded %d stored tasks", count)
+
 	// Run outreach inits
+	outreach_agentprompt.SetMemoryStore(memoryStore)
 	for name, initFunc := range outreachInits {
 		if err := initFunc(cfg); err != nil {
 			return fmt.Errorf("failed to run outreach init for %s: %w", name, err)
 		}
 	}
 
+	// Let the reminder agent schedule reminders through the manager
+	reminderagent.SetScheduler(manager)
+
 	outreachService = service
 	return nil
 }
 
-// listenForResponses is a helper function that listens for responses from the manager and forwards them to implementations
+// listenForResponses is a helper function that listens for responses from the manager, stores them, and delivers them to implementations
 func (s *OutreachService) listenForResponses() {
 	responseCh := s.manager.GetResponseChannel()
 
@@ -145,22 +167,14 @@ func (s *OutreachService) listenForResponses() {
 				return
 			}
 
-			// Forward response to each client
-			sent := false
-			for i := 0; i < len(response.Clients) && !sent; i++ {
-				client := response.Clients[i]
-
-				for range MAX_SEND_OUTREACH_RETRIES {
-					// Send response and log any errors
-					err := s.forwardResponseToClient(client.CallbackUrl, response)
-					if err == nil {
-						sent = true
-						break
-					}
-
-					log.Printf("[OUTREACH]: Failed to forward response to %s: %v", client.CallbackUrl, err)
-				}
+			// Persist the response before sending it so it survives failures and restarts
+			delivery, err := s.store.CreateDelivery(response)
+			if err != nil {
+				log.Printf("[OUTREACH]: Failed to store response for task '%s': %v", response.Key, err)
+				continue
 			}
+
+			go s.deliver(delivery)
 		}
 	}
 }
@@ -188,15 +202,6 @@ func (s *OutreachService) loadTasksFromConfig(path string) error {
 		return fmt.Errorf("failed to parse tasks configuration file: %w", err)
 	}
 
-	// Map tasks to run functions
-	for _, task := range config.Tasks {
-		if runFunc, exists := outreachTaskFunctions[task.Key]; exists {
-			task.Run = runFunc
-		} else {
-			return fmt.Errorf("no run function found for task key '%s'", task.Key)
-		}
-	}
-
 	// Allow empty tasks
 	if len(config.Tasks) == 0 {
 		log.Println("[OUTREACH]: No tasks found in configuration, skipping task loading")
@@ -216,16 +221,17 @@ func (s *OutreachService) loadTasksFromConfig(path string) error {
 
 // Stop gracefully stops the service
 func (s *OutreachService) Stop() {
+	reminderagent.SetScheduler(nil)
 	s.cancel()
 	s.manager.Stop()
 }
 
-// RegisterImplementation registers a new implementation
-func (s *OutreachService) RegisterImplementation(req *sdk.OutreachRegisterRequest) error {
-	// Validate client secret if provided
-	if req.ClientSecret != "" {
-		if _, err := s.AuthenticateClient(req.ClientId, req.ClientSecret); err != nil {
-			return fmt.Errorf("invalid client credentials: %w", err)
+// RegisterImplementation registers a new implementation and returns the key it should verify outreach requests with
+func (s *OutreachService) RegisterImplementation(req *sdk.OutreachRegisterRequest) (*sdk.OutreachRegisterResponse, error) {
+	// Implementations that already exist must register with their current secret
+	if _, err := s.manager.GetImplementation(req.ClientId); err == nil {
+		if _, err := s.manager.AuthenticateImplementation(req.ClientId, req.ClientSecret); err != nil {
+			return nil, fmt.Errorf("invalid client credentials: %w", err)
 		}
 	}
 
@@ -237,11 +243,15 @@ func (s *OutreachService) RegisterImplementation(req *sdk.OutreachRegisterReques
 	}
 
 	// Register with manager
-	if err := s.manager.RegisterImplementation(outreachReq); err != nil {
-		return fmt.Errorf("failed to register implementation: %w", err)
+	impl, err := s.manager.RegisterImplementation(outreachReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register implementation: %w", err)
 	}
 
-	return nil
+	return &sdk.OutreachRegisterResponse{
+		ClientId:   impl.ClientID,
+		SigningKey: impl.Credentials.SigningKey,
+	}, nil
 }
 
 // UnregisterImplementation removes an implementation
@@ -249,6 +259,22 @@ func (s *OutreachService) UnregisterImplementation(clientId string) error {
 	return s.manager.UnregisterImplementation(clientId)
 }
 
+// RotateSecret replaces an implementation's client secret, accepting the old secret until the grace period ends
+func (s *OutreachService) RotateSecret(clientID, newSecret string, gracePeriod time.Duration) (*sdk.OutreachRotateSecretResponse, error) {
+	impl, err := s.manager.RotateImplementationSecret(clientID, newSecret, gracePeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[OUTREACH]: Rotated client secret for '%s', previous secret accepted until %s", clientID, impl.Credentials.PreviousSecretExpiresAt.Format(time.RFC3339))
+
+	return &sdk.OutreachRotateSecretResponse{
+		ClientId:                clientID,
+		SigningKey:              impl.Credentials.SigningKey,
+		PreviousSecretExpiresAt: *impl.Credentials.PreviousSecretExpiresAt,
+	}, nil
+}
+
 // GetImplementations returns all registered implementations
 func (s *OutreachService) GetImplementations() (*sdk.OutreachListImplementationsResponse, error) {
 	implementations := s.manager.GetImplementations()
@@ -272,16 +298,46 @@ func (s *OutreachService) GetStatus() *sdk.OutreachStatusResponse {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	tasks := s.manager.GetTasks()
+	statuses := s.manager.GetTaskStatuses()
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Key < statuses[j].Key
+	})
 	implementations := s.manager.GetImplementations()
 
+	tasksStatus := sdk.OutreachTaskStatus{
+		Loaded: len(statuses),
+		Tasks:  make([]sdk.OutreachTaskState, len(statuses)),
+	}
+	for i, status := range statuses {
+		if status.Paused {
+			tasksStatus.Paused++
+		}
+
+		tasksStatus.Tasks[i] = sdk.OutreachTaskState{
+			Key:      status.Key,
+			Cadence:  string(status.Cadence),
+			Paused:   status.Paused,
+			NextRun:  status.NextRun,
+			Runs:     status.Runs,
+			Failures: status.Failures,
+		}
+		if status.LastRun != nil {
+			lastRun := toSDKTaskRun(*status.LastRun)
+			tasksStatus.Tasks[i].LastRun = &lastRun
+		}
+	}
+
+	running := s.manager.Running()
+	serviceStatus := "running"
+	if !running {
+		serviceStatus = "stopped"
+	}
+
 	return &sdk.OutreachStatusResponse{
-		Status: "running",
-		TasksStatus: sdk.OutreachTaskStatus{
-			Loaded: len(tasks),
-		},
+		Status:               serviceStatus,
+		TasksStatus:          tasksStatus,
 		ImplementationsCount: len(implementations),
-		ManagerRunning:       true,
+		ManagerRunning:       running,
 	}
 }
 
@@ -301,8 +357,21 @@ func (s *OutreachService) AuthenticateClient(clientID, clientSecret string) (*ou
 
 /** ---- HELPERS ---- */
 
-// forwardResponseToClient sends a response to a specific client implementation
-func (s *OutreachService) forwardResponseToClient(callbackUrl string, response *outreach.Response) error {
+// forwardResponseToClient sends a response to a specific client implementation, signed with the implementation's signing key.
+// While a rotated secret is in its grace period the request is signed with both the new and old keys.
+// The returned status code is 0 if the client never responded.
+func (s *OutreachService) forwardResponseToClient(ctx context.Context, clientID, callbackUrl string, response *outreach.Response) (int, error) {
+	// Get the implementation's keys to sign the request with
+	impl, err := s.manager.GetImplementation(clientID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get implementation: %w", err)
+	}
+
+	signingKeys := impl.Credentials.SigningKeys(time.Now())
+	if len(signingKeys) == 0 {
+		return 0, fmt.Errorf("implementation '%s' has no signing key, it must register again", clientID)
+	}
+
 	// Create outreach request
 	outreachReq := &sdk.OutreachRequest{
 		Id:      response.IdempotencyId,
@@ -315,30 +384,32 @@ func (s *OutreachService) forwardResponseToClient(callbackUrl string, response *
 	// Marshal to JSON
 	jsonData, err := json.Marshal(outreachReq)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, callbackUrl, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackUrl, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "assistant-outreach/1.0")
+	sdk.SignOutreachRequest(req, jsonData, time.Now(), signingKeys...)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 	// Send request
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer resp.Body.Close()
 
 	// Log response status
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("outreach request failed with status %d: %s", resp.StatusCode, string(body))
+		return resp.StatusCode, fmt.Errorf("outreach request failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
-	return nil
+	return resp.StatusCode, nil
 }
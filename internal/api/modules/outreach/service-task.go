@@ -0,0 +1,243 @@
+package outreach_module
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+
+	"github.com/ethanbaker/assistant/pkg/outreach"
+	"github.com/ethanbaker/assistant/pkg/sdk"
+)
+
+// errTaskNotOwned is returned when a client changes or runs a task that isn't delivered to it
+var errTaskNotOwned = errors.New("task is not delivered to the authenticated client")
+
+// ListTasks returns all loaded tasks ordered by key
+func (s *OutreachService) ListTasks() []sdk.OutreachTask {
+	tasks := s.manager.GetTasks()
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Key < tasks[j].Key
+	})
+
+	sdkTasks := make([]sdk.OutreachTask, len(tasks))
+	for i, task := range tasks {
+		sdkTasks[i] = toSDKTask(task)
+	}
+
+	return sdkTasks
+}
+
+// GetTask returns a loaded task by key
+func (s *OutreachService) GetTask(key string) (*sdk.OutreachTask, error) {
+	task, err := s.manager.GetTask(key)
+	if err != nil {
+		return nil, err
+	}
+
+	sdkTask := toSDKTask(task)
+	return &sdkTask, nil
+}
+
+// CreateTask adds a new task and schedules it. The creating client must be one of the task's clients
+func (s *OutreachService) CreateTask(clientID string, req *sdk.CreateOutreachTaskRequest) (*sdk.OutreachTask, error) {
+	task := &outreach.Task{
+		Key:           req.Key,
+		Type:          req.Type,
+		ClientIds:     req.ClientIds,
+		Params:        req.Params,
+		Cadence:       outreach.CadenceType(req.Cadence),
+		CadenceParams: req.CadenceParams,
+		Paused:        req.Paused,
+	}
+	if err := validateTask(task); err != nil {
+		return nil, err
+	}
+	if err := checkTaskClient(task, clientID); err != nil {
+		return nil, err
+	}
+
+	if err := s.manager.AddTask(task); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[OUTREACH]: Added task '%s'", task.Key)
+	return s.GetTask(task.Key)
+}
+
+// UpdateTask replaces a task and reschedules it. The client must be one of the task's clients before and after
+func (s *OutreachService) UpdateTask(clientID, key string, req *sdk.UpdateOutreachTaskRequest) (*sdk.OutreachTask, error) {
+	if _, err := s.getOwnedTask(clientID, key); err != nil {
+		return nil, err
+	}
+
+	task := &outreach.Task{
+		Key:           key,
+		Type:          req.Type,
+		ClientIds:     req.ClientIds,
+		Params:        req.Params,
+		Cadence:       outreach.CadenceType(req.Cadence),
+		CadenceParams: req.CadenceParams,
+		Paused:        req.Paused,
+	}
+	if err := validateTask(task); err != nil {
+		return nil, err
+	}
+	if err := checkTaskClient(task, clientID); err != nil {
+		return nil, err
+	}
+
+	if err := s.manager.UpdateTask(task); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[OUTREACH]: Updated task '%s'", key)
+	return s.GetTask(key)
+}
+
+// RemoveTask unschedules and deletes a task delivered to the client
+func (s *OutreachService) RemoveTask(clientID, key string) error {
+	if _, err := s.getOwnedTask(clientID, key); err != nil {
+		return err
+	}
+
+	if err := s.manager.RemoveTask(key); err != nil {
+		return err
+	}
+
+	log.Printf("[OUTREACH]: Removed task '%s'", key)
+	return nil
+}
+
+// PauseTask stops a task delivered to the client from running until it is resumed
+func (s *OutreachService) PauseTask(clientID, key string) (*sdk.OutreachTask, error) {
+	if _, err := s.getOwnedTask(clientID, key); err != nil {
+		return nil, err
+	}
+
+	if err := s.manager.PauseTask(key); err != nil {
+		return nil, err
+	}
+
+	return s.GetTask(key)
+}
+
+// ResumeTask schedules a paused task delivered to the client again
+func (s *OutreachService) ResumeTask(clientID, key string) (*sdk.OutreachTask, error) {
+	if _, err := s.getOwnedTask(clientID, key); err != nil {
+		return nil, err
+	}
+
+	if err := s.manager.ResumeTask(key); err != nil {
+		return nil, err
+	}
+
+	return s.GetTask(key)
+}
+
+// RunTask runs a task delivered to the client immediately. Previews return the output without delivering it to clients.
+func (s *OutreachService) RunTask(clientID, key string, preview bool) (*sdk.RunOutreachTaskResponse, error) {
+	if _, err := s.getOwnedTask(clientID, key); err != nil {
+		return nil, err
+	}
+
+	output, err := s.manager.TriggerTask(key, outreach.TriggerOptions{Preview: preview})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &sdk.RunOutreachTaskResponse{
+		Key:     key,
+		Preview: preview,
+		Empty:   output == nil,
+	}
+	if output != nil {
+		resp.Content = output.Content
+		resp.Data = output.Data
+		resp.Delivered = !preview
+	}
+
+	log.Printf("[OUTREACH]: Ran task '%s' manually (preview: %t)", key, preview)
+	return resp, nil
+}
+
+// GetTaskRuns returns a task's most recent runs, newest first
+func (s *OutreachService) GetTaskRuns(key string) ([]sdk.OutreachTaskRun, error) {
+	runs, err := s.manager.GetTaskRuns(key)
+	if err != nil {
+		return nil, err
+	}
+
+	sdkRuns := make([]sdk.OutreachTaskRun, len(runs))
+	for i, run := range runs {
+		sdkRuns[i] = toSDKTaskRun(run)
+	}
+
+	return sdkRuns, nil
+}
+
+/** ---- HELPERS ---- */
+
+// getOwnedTask returns a loaded task by key if it is delivered to the client
+func (s *OutreachService) getOwnedTask(clientID, key string) (*outreach.Task, error) {
+	task, err := s.manager.GetTask(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTaskClient(task, clientID); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// checkTaskClient checks a task is delivered to the client
+func checkTaskClient(task *outreach.Task, clientID string) error {
+	if !slices.Contains(task.ClientIds, clientID) {
+		return fmt.Errorf("task '%s': %w", task.Key, errTaskNotOwned)
+	}
+
+	return nil
+}
+
+// validateTask checks the fields of a task from a request
+func validateTask(task *outreach.Task) error {
+	if task.Key == "" {
+		return fmt.Errorf("task key cannot be empty")
+	}
+	if len(task.ClientIds) == 0 {
+		return fmt.Errorf("task must have at least one client_id")
+	}
+	if !outreach.ValidateCadenceType(task.Cadence) {
+		return fmt.Errorf("unsupported cadence type: %s", task.Cadence)
+	}
+
+	return nil
+}
+
+// toSDKTask converts a task to the API format
+func toSDKTask(task *outreach.Task) sdk.OutreachTask {
+	return sdk.OutreachTask{
+		Key:           task.Key,
+		Type:          task.Type,
+		ClientIds:     task.ClientIds,
+		Params:        task.Params,
+		Cadence:       string(task.Cadence),
+		CadenceParams: task.CadenceParams,
+		Paused:        task.Paused,
+	}
+}
+
+// toSDKTaskRun converts a task run to the API format
+func toSDKTaskRun(run outreach.TaskRun) sdk.OutreachTaskRun {
+	return sdk.OutreachTaskRun{
+		Id:            run.ID,
+		Trigger:       string(run.Trigger),
+		StartedAt:     run.StartedAt,
+		DurationMs:    run.Duration.Milliseconds(),
+		Outcome:       string(run.Outcome),
+		Error:         run.Error,
+		IdempotencyId: run.IdempotencyId,
+	}
+}
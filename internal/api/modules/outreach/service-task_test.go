@@ -0,0 +1,71 @@
+package outreach_module
+
+import (
+	"testing"
+
+	outreach_store "github.com/ethanbaker/assistant/internal/stores/outreach"
+	"github.com/ethanbaker/assistant/pkg/outreach"
+	"github.com/ethanbaker/assistant/pkg/sdk"
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestService creates a service with an in-memory store and an 'echo' task type
+func newTestService(t *testing.T) *OutreachService {
+	store := outreach_store.NewInMemoryStore()
+	manager, err := outreach.NewManager(utils.NewConfig(map[string]string{}), &outreach.ManagerOptions{
+		Store: store,
+		TaskFunctions: map[string]outreach.TaskRunFunction{
+			"echo": func(cfg *utils.Config, params map[string]any) *outreach.TaskReturn {
+				return &outreach.TaskReturn{Content: "echo"}
+			},
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(manager.Stop)
+
+	return &OutreachService{manager: manager, store: store}
+}
+
+func TestTasksAreLimitedToTheirClients(t *testing.T) {
+	service := newTestService(t)
+	req := &sdk.CreateOutreachTaskRequest{
+		Key:       "digest",
+		Type:      "echo",
+		ClientIds: []string{"discord"},
+		Cadence:   string(outreach.SunriseCadence),
+		Paused:    true,
+	}
+
+	// Clients can only create tasks delivered to themselves
+	_, err := service.CreateTask("other", req)
+	assert.ErrorIs(t, err, errTaskNotOwned)
+	_, err = service.CreateTask("discord", req)
+	require.NoError(t, err)
+
+	// Other clients can't change, run, or remove the task
+	update := &sdk.UpdateOutreachTaskRequest{Type: "echo", ClientIds: []string{"other"}, Cadence: string(outreach.SunriseCadence)}
+	_, err = service.UpdateTask("other", "digest", update)
+	assert.ErrorIs(t, err, errTaskNotOwned)
+	_, err = service.PauseTask("other", "digest")
+	assert.ErrorIs(t, err, errTaskNotOwned)
+	_, err = service.ResumeTask("other", "digest")
+	assert.ErrorIs(t, err, errTaskNotOwned)
+	_, err = service.RunTask("other", "digest", true)
+	assert.ErrorIs(t, err, errTaskNotOwned)
+	assert.ErrorIs(t, service.RemoveTask("other", "digest"), errTaskNotOwned)
+
+	// The task's client can't give the task away either
+	_, err = service.UpdateTask("discord", "digest", update)
+	assert.ErrorIs(t, err, errTaskNotOwned)
+
+	resp, err := service.RunTask("discord", "digest", true)
+	require.NoError(t, err)
+	assert.Equal(t, "echo", resp.Content)
+	require.NoError(t, service.RemoveTask("discord", "digest"))
+
+	// Missing tasks are still reported as missing
+	_, err = service.PauseTask("discord", "digest")
+	assert.ErrorIs(t, err, outreach.ErrTaskNotFound)
+}
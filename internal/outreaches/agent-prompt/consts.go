@@ -0,0 +1,17 @@
+package outreach_agentprompt
+
+/* ---- CONSTANTS ---- */
+
+// Agents that prompts can be sent to
+const (
+	OVERSEER_AGENT = "overseer"
+	SEARCH_AGENT   = "search"
+	TASK_AGENT     = "task"
+	SCHEDULE_AGENT = "schedule"
+)
+
+// DEFAULT_AGENT handles prompts that don't name an agent
+const DEFAULT_AGENT = OVERSEER_AGENT
+
+// SESSION_USER_ID owns the one-off sessions when a task doesn't name a user
+const SESSION_USER_ID = "outreach-agent-prompt"
@@ -0,0 +1,45 @@
+package outreach_agentprompt
+
+import (
+	"log"
+	"sync"
+
+	"github.com/ethanbaker/assistant/internal/stores/memory"
+	"github.com/ethanbaker/assistant/internal/stores/session"
+	"github.com/ethanbaker/assistant/pkg/agent"
+	"github.com/ethanbaker/assistant/pkg/utils"
+)
+
+/* ---- GLOBALS ---- */
+
+var (
+	// Memory store for the overseer's memory agent (nil without a database)
+	memoryStore *memory.Store
+
+	// Store for the one-off sessions prompts run on
+	sessionStore session.Store = session.NewInMemoryStore()
+
+	// Agents created for earlier prompts, by name
+	promptAgents      = map[string]agent.CustomAgent{}
+	promptAgentsMutex sync.Mutex
+)
+
+/* ---- INIT ---- */
+
+// SetMemoryStore sets the memory store agents remember facts with. The outreach module starts after the
+// agents, so it provides the orchestrator's store, which already has the vector index for semantic search.
+func SetMemoryStore(store *memory.Store) {
+	promptAgentsMutex.Lock()
+	defer promptAgentsMutex.Unlock()
+
+	memoryStore = store
+	promptAgents = map[string]agent.CustomAgent{}
+}
+
+func Init(cfg *utils.Config) error {
+	if memoryStore == nil {
+		log.Println("[AGENT-PROMPT]: Warning, no memory store set, prompts can't use the overseer agent")
+	}
+
+	return nil
+}
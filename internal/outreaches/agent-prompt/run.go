@@ -0,0 +1,156 @@
+package outreach_agentprompt
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ethanbaker/assistant/internal/agents/overseer"
+	"github.com/ethanbaker/assistant/internal/agents/schedule"
+	"github.com/ethanbaker/assistant/internal/agents/search"
+	"github.com/ethanbaker/assistant/internal/agents/task"
+	"github.com/ethanbaker/assistant/internal/telemetry"
+	"github.com/ethanbaker/assistant/pkg/agent"
+	"github.com/ethanbaker/assistant/pkg/outreach"
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/nlpodyssey/openai-agents-go/agents"
+)
+
+/* ---- METHODS ---- */
+
+// RunAgentPrompt sends the task's 'prompt' param to an agent and returns its reply. The optional 'agent' param
+// picks the agent (overseer, search, task, or schedule), and 'user_id' picks the user the agent acts for
+func RunAgentPrompt(cfg *utils.Config, params map[string]any) *outreach.TaskReturn {
+	prompt, err := parsePromptParams(params)
+	if err != nil {
+		log.Printf("[AGENT-PROMPT]: Invalid task params: %v\n", err)
+		return &outreach.TaskReturn{
+			Content: fmt.Sprintf("Error running agent prompt<BLOCKQUOTE>Error: %v</BLOCKQUOTE>", err),
+		}
+	}
+
+	output, err := runPrompt(cfg, prompt)
+	if err != nil {
+		log.Printf("[AGENT-PROMPT]: Error running prompt with the %s agent (err: %v)\n", prompt.Agent, err)
+		return &outreach.TaskReturn{
+			Content: fmt.Sprintf("Error running agent prompt<BLOCKQUOTE>Error: %v</BLOCKQUOTE>", err),
+		}
+	}
+
+	return &outreach.TaskReturn{
+		Content: output,
+		Data:    nil,
+	}
+}
+
+// Helper function to read and check a task's params
+func parsePromptParams(params map[string]any) (*Prompt, error) {
+	prompt := &Prompt{
+		Agent:  DEFAULT_AGENT,
+		UserID: SESSION_USER_ID,
+	}
+
+	text, _ := params["prompt"].(string)
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("'prompt' param is required")
+	}
+	prompt.Text = text
+
+	if name, ok := params["agent"].(string); ok && name != "" {
+		switch name {
+		case OVERSEER_AGENT, SEARCH_AGENT, TASK_AGENT, SCHEDULE_AGENT:
+			prompt.Agent = name
+		default:
+			return nil, fmt.Errorf("unknown agent '%s'", name)
+		}
+	}
+
+	if userID, ok := params["user_id"].(string); ok && userID != "" {
+		prompt.UserID = userID
+	}
+
+	return prompt, nil
+}
+
+// Helper function to run a prompt on a fresh in-memory session
+func runPrompt(cfg *utils.Config, prompt *Prompt) (string, error) {
+	ctx := agent.WithUserID(context.Background(), prompt.UserID)
+
+	// Make an in-memory session for a one time call, forgetting it once the prompt is answered
+	sess, err := sessionStore.CreateSession(ctx, prompt.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to create in-memory session: %v", err)
+	}
+	defer func() {
+		if id, err := uuid.Parse(sess.SessionID(ctx)); err == nil {
+			sessionStore.DeleteSession(ctx, id)
+		}
+	}()
+
+	customAgent, err := getAgent(cfg, prompt.Agent)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s agent: %v", prompt.Agent, err)
+	}
+
+	// Make a runner with the in-memory session
+	runner := agents.Runner{
+		Config: agents.RunConfig{
+			Session: sess,
+		},
+	}
+
+	resp, err := runner.Run(ctx, customAgent.Agent(), prompt.Text)
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s agent: %v", prompt.Agent, err)
+	}
+
+	return fmt.Sprint(resp.FinalOutput), nil
+}
+
+// Helper function to get the agent a prompt is sent to. Agents are created on their first prompt and reused after
+func getAgent(cfg *utils.Config, name string) (agent.CustomAgent, error) {
+	promptAgentsMutex.Lock()
+	defer promptAgentsMutex.Unlock()
+
+	if customAgent, ok := promptAgents[name]; ok {
+		return customAgent, nil
+	}
+
+	customAgent, err := newAgent(cfg, name)
+	if err != nil {
+		return nil, err
+	}
+	promptAgents[name] = customAgent
+
+	return customAgent, nil
+}
+
+// Helper function to create the agent a prompt is sent to
+func newAgent(cfg *utils.Config, name string) (agent.CustomAgent, error) {
+	switch name {
+	case SEARCH_AGENT:
+		return instrument(search.NewSearchAgent(memoryStore, sessionStore, cfg))
+	case TASK_AGENT:
+		return instrument(task.NewTaskAgent(memoryStore, sessionStore, cfg))
+	case SCHEDULE_AGENT:
+		return instrument(schedule.NewScheduleAgent(memoryStore, sessionStore, cfg))
+	default:
+		if memoryStore == nil {
+			return nil, fmt.Errorf("the overseer agent needs a database for its memory agent")
+		}
+		// The overseer instruments itself and its specialized agents
+		return overseer.NewOverseerAgent(memoryStore, sessionStore, cfg)
+	}
+}
+
+// Helper function to trace and measure the tools of a newly created agent
+func instrument(customAgent agent.CustomAgent, err error) (agent.CustomAgent, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	telemetry.InstrumentAgent(customAgent.Agent(), customAgent.ID())
+	return customAgent, nil
+}
@@ -0,0 +1,49 @@
+package outreach_agentprompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePromptParams(t *testing.T) {
+	prompt, err := parsePromptParams(map[string]any{"prompt": "Summarize my week"})
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize my week", prompt.Text)
+	assert.Equal(t, DEFAULT_AGENT, prompt.Agent)
+	assert.Equal(t, SESSION_USER_ID, prompt.UserID)
+
+	prompt, err = parsePromptParams(map[string]any{"prompt": "What's on today?", "agent": "schedule", "user_id": "ethan"})
+	require.NoError(t, err)
+	assert.Equal(t, SCHEDULE_AGENT, prompt.Agent)
+	assert.Equal(t, "ethan", prompt.UserID)
+
+	_, err = parsePromptParams(map[string]any{})
+	assert.Error(t, err)
+	_, err = parsePromptParams(map[string]any{"prompt": "Hello", "agent": "memory"})
+	assert.Error(t, err)
+}
+
+func TestGetAgentReusesAgents(t *testing.T) {
+	promptPath := filepath.Join(t.TempDir(), "search.md")
+	require.NoError(t, os.WriteFile(promptPath, []byte("You search the web"), 0o644))
+	cfg := utils.NewConfig(map[string]string{
+		"SEARXNG_URL":           "http://localhost:8080",
+		"SEARCH_SYSPROMPT_PATH": promptPath,
+	})
+	SetMemoryStore(nil)
+
+	first, err := getAgent(cfg, SEARCH_AGENT)
+	require.NoError(t, err)
+	second, err := getAgent(cfg, SEARCH_AGENT)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	// The overseer can't run without a memory store
+	_, err = getAgent(cfg, OVERSEER_AGENT)
+	assert.Error(t, err)
+}
@@ -0,0 +1,10 @@
+package outreach_agentprompt
+
+/* ---- TYPES ---- */
+
+// Prompt holds the parsed params of an agent prompt task
+type Prompt struct {
+	Text   string // Prompt sent to the agent
+	Agent  string // Agent the prompt is sent to
+	UserID string // User the agent acts for
+}
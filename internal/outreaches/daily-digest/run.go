@@ -19,7 +19,7 @@ import (
 
 /* ---- METHODS ---- */
 
-func CreateDailyDigest(cfg *utils.Config) *outreach.TaskReturn {
+func CreateDailyDigest(cfg *utils.Config, params map[string]any) *outreach.TaskReturn {
 	var output string
 	var err error
 
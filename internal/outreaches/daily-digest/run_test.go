@@ -20,7 +20,7 @@ func TestCreateDailyDigest(t *testing.T) {
 	assert.Nil(err)
 
 	// Run outreach
-	output := outreach_dailydigest.CreateDailyDigest(cfg)
+	output := outreach_dailydigest.CreateDailyDigest(cfg, nil)
 	assert.NotNil(output)
 	t.Logf("Result: %v", output)
 }
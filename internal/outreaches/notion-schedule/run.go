@@ -11,7 +11,7 @@ import (
 /* ---- OUTREACH TASK ---- */
 
 // NotionScheduleReminder checks if there's an event occurring in the current minute
-func NotionScheduleReminder(cfg *utils.Config) *outreach.TaskReturn {
+func NotionScheduleReminder(cfg *utils.Config, params map[string]any) *outreach.TaskReturn {
 	now := time.Now()
 
 	// Get the current list of events (thread-safe)
@@ -22,6 +22,6 @@ func TestNotionScheduleReminder(t *testing.T) {
 	fetchNotionEvents(cfg)
 
 	// Test when no events are active
-	output := NotionScheduleReminder(cfg)
+	output := NotionScheduleReminder(cfg, nil)
 	t.Logf("Result: %v", output)
 }
@@ -0,0 +1,146 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Scopes that API keys can be granted
+const (
+	ScopeAgentRead     = "agent:read"     // Read sessions
+	ScopeAgentWrite    = "agent:write"    // Create sessions and send messages (includes agent:read)
+	ScopeMemoryRead    = "memory:read"    // Read facts
+	ScopeMemoryWrite   = "memory:write"   // Store and change facts (includes memory:read)
+	ScopeOutreachAdmin = "outreach:admin" // Register outreach implementations
+	ScopeKeysAdmin     = "keys:admin"     // Mint and revoke API keys
+	ScopeMetricsRead   = "metrics:read"   // Scrape Prometheus metrics
+)
+
+// AllScopes lists every scope a key can be granted
+var AllScopes = []string{ScopeAgentRead, ScopeAgentWrite, ScopeMemoryRead, ScopeMemoryWrite, ScopeOutreachAdmin, ScopeKeysAdmin, ScopeMetricsRead}
+
+// KEY_PREFIX starts every generated API key so keys are easy to recognize
+const KEY_PREFIX = "ak_"
+
+// KEY_BYTES is the number of random bytes in a generated API key
+const KEY_BYTES = 32
+
+// DISPLAY_PREFIX_LENGTH is how much of a key is kept in plain text to help recognize it
+const DISPLAY_PREFIX_LENGTH = 10
+
+// Key is a stored API key. Only a hash of the key itself is kept.
+type Key struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+
+	Name   string `json:"name" gorm:"column:name;size:255;not null"`                   // Label for the key's client, such as "discord"
+	Prefix string `json:"prefix" gorm:"column:prefix;size:32;not null"`                // Start of the key, to recognize it in lists
+	Hash   string `json:"-" gorm:"column:key_hash;size:64;not null;uniqueIndex"`       // SHA-256 of the key
+	Scopes Scopes `json:"scopes" gorm:"column:scopes;size:1024;not null;default:''"`   // Scopes the key is granted
+	UserID string `json:"user_id,omitempty" gorm:"column:user_id;size:255;default:''"` // User the key is bound to, if any
+
+	ClientID string `json:"client_id,omitempty" gorm:"column:client_id;size:255;default:''"` // Outreach implementation that delivers reminders set through the key, if any
+
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" gorm:"column:last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" gorm:"column:revoked_at;index"`
+}
+
+// TableName sets the table name for GORM
+func (Key) TableName() string {
+	return "api_keys"
+}
+
+// HasScope reports whether the key is granted a scope. Write scopes include their read scope.
+func (k *Key) HasScope(scope string) bool {
+	return k.Scopes.Has(scope)
+}
+
+// Revoked reports whether the key has been revoked
+func (k *Key) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// GenerateKey creates a new random API key
+func GenerateKey() (string, error) {
+	b := make([]byte, KEY_BYTES)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	return KEY_PREFIX + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashKey hashes an API key for storage and lookup. Keys are long and random, so a fast hash is enough.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Scopes is a list of scopes that implements database serialization as a comma separated string
+type Scopes []string
+
+// NewScopes normalizes scopes, checking that each one is known
+func NewScopes(scopes ...string) (Scopes, error) {
+	result := Scopes{}
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope == "" || slices.Contains(result, scope) {
+			continue
+		}
+		if !slices.Contains(AllScopes, scope) {
+			return nil, fmt.Errorf("unknown scope '%s'", scope)
+		}
+		result = append(result, scope)
+	}
+
+	return result, nil
+}
+
+// Has reports whether the scopes include the given scope, counting write scopes as including reads
+func (s Scopes) Has(scope string) bool {
+	if slices.Contains(s, scope) {
+		return true
+	}
+
+	if resource, found := strings.CutSuffix(scope, ":read"); found {
+		return slices.Contains(s, resource+":write")
+	}
+
+	return false
+}
+
+// Value implements the driver.Valuer interface for database storage
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, ","), nil
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (s *Scopes) Scan(value any) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		raw = ""
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("cannot scan %T into Scopes", value)
+	}
+
+	*s = Scopes{}
+	for _, scope := range strings.Split(raw, ",") {
+		if scope != "" {
+			*s = append(*s, scope)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/database"
+	"gorm.io/gorm"
+)
+
+// ErrKeyNotFound is returned when a key doesn't exist
+var ErrKeyNotFound = errors.New("API key not found")
+
+// ErrInvalidKey is returned when a key is unknown or revoked
+var ErrInvalidKey = errors.New("invalid API key")
+
+// LAST_USED_RESOLUTION is how often a key's last_used_at is updated, so every request doesn't write to the database
+const LAST_USED_RESOLUTION = time.Minute
+
+// Store handles API key persistence using GORM
+type Store struct {
+	db *gorm.DB
+}
+
+// NewStore creates a new API key store connected to the database URL (see database.ParseURL)
+func NewStore(databaseURL string) (*Store, error) {
+	db, err := database.Open(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{db: db}
+
+	// Auto-migrate tables
+	if err := store.db.AutoMigrate(&Key{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate tables: %w", err)
+	}
+
+	return store, nil
+}
+
+// CreateKey mints a new key. The returned string is the only copy of the key itself.
+// The client ID is the outreach implementation reminders set through the key are delivered by, and may be empty.
+func (s *Store) CreateKey(ctx context.Context, name string, scopes Scopes, userID, clientID string) (*Key, string, error) {
+	if name == "" {
+		return nil, "", fmt.Errorf("key name cannot be empty")
+	}
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("key must have at least one scope")
+	}
+
+	raw, err := GenerateKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &Key{
+		Name:   name,
+		Prefix: raw[:DISPLAY_PREFIX_LENGTH],
+		Hash:   HashKey(raw),
+		Scopes: scopes,
+		UserID: userID,
+
+		ClientID: clientID,
+	}
+	if err := s.db.WithContext(ctx).Create(key).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return key, raw, nil
+}
+
+// GetKey returns a key by ID
+func (s *Store) GetKey(ctx context.Context, id uint) (*Key, error) {
+	var key Key
+	if err := s.db.WithContext(ctx).First(&key, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("key %d: %w", id, ErrKeyNotFound)
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// ListKeys returns all keys, newest first. Revoked keys are only included if asked for.
+func (s *Store) ListKeys(ctx context.Context, includeRevoked bool) ([]*Key, error) {
+	query := s.db.WithContext(ctx).Order("id DESC")
+	if !includeRevoked {
+		query = query.Where("revoked_at IS NULL")
+	}
+
+	var keys []*Key
+	if err := query.Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeKey stops a key from being accepted. Revoking a revoked key does nothing.
+func (s *Store) RevokeKey(ctx context.Context, id uint) (*Key, error) {
+	key, err := s.GetKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.Revoked() {
+		return key, nil
+	}
+
+	now := time.Now().UTC()
+	if err := s.db.WithContext(ctx).Model(key).Update("revoked_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	key.RevokedAt = &now
+
+	return key, nil
+}
+
+// Authenticate finds the active key matching a raw key and records that it was used
+func (s *Store) Authenticate(ctx context.Context, raw string) (*Key, error) {
+	var key Key
+	if err := s.db.WithContext(ctx).Where("key_hash = ?", HashKey(raw)).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidKey
+		}
+		return nil, fmt.Errorf("failed to authenticate API key: %w", err)
+	}
+	if key.Revoked() {
+		return nil, ErrInvalidKey
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= LAST_USED_RESOLUTION {
+		if err := s.db.WithContext(ctx).Model(&key).UpdateColumn("last_used_at", now).Error; err != nil {
+			return nil, fmt.Errorf("failed to record API key use: %w", err)
+		}
+		key.LastUsedAt = &now
+	}
+
+	return &key, nil
+}
+
+// Close closes the database connection
+func (s *Store) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sql.DB from gorm.DB: %w", err)
+	}
+	return sqlDB.Close()
+}
+
+// GetDB returns the underlying GORM database connection
+func (s *Store) GetDB() *gorm.DB {
+	return s.db
+}
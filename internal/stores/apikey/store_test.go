@@ -0,0 +1,76 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreKeys(t *testing.T) {
+	ctx := context.Background()
+	store := storetest.New(t, NewStore)
+
+	scopes, err := NewScopes("agent:write", "memory:read")
+	require.NoError(t, err)
+
+	key, raw, err := store.CreateKey(ctx, "discord", scopes, "user-a", "discord")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, KEY_PREFIX))
+	assert.Equal(t, raw[:DISPLAY_PREFIX_LENGTH], key.Prefix)
+	assert.NotContains(t, key.Hash, raw)
+
+	// The raw key authenticates and records its use
+	got, err := store.Authenticate(ctx, raw)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, got.ID)
+	assert.Equal(t, "user-a", got.UserID)
+	assert.Equal(t, "discord", got.ClientID)
+	assert.Equal(t, scopes, got.Scopes)
+	require.NotNil(t, got.LastUsedAt)
+	assert.WithinDuration(t, time.Now(), *got.LastUsedAt, 5*time.Second)
+
+	_, err = store.Authenticate(ctx, raw+"x")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	// Revoked keys are rejected and hidden from the default listing
+	_, _, err = store.CreateKey(ctx, "cli", Scopes{ScopeKeysAdmin}, "", "")
+	require.NoError(t, err)
+
+	revoked, err := store.RevokeKey(ctx, key.ID)
+	require.NoError(t, err)
+	assert.True(t, revoked.Revoked())
+
+	_, err = store.Authenticate(ctx, raw)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	keys, err := store.ListKeys(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "cli", keys[0].Name)
+
+	keys, err = store.ListKeys(ctx, true)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	_, err = store.RevokeKey(ctx, 999)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestScopes(t *testing.T) {
+	scopes, err := NewScopes(" Agent:Write ", "agent:write", "")
+	require.NoError(t, err)
+	assert.Equal(t, Scopes{ScopeAgentWrite}, scopes)
+
+	// Write scopes include their read scope
+	assert.True(t, scopes.Has(ScopeAgentRead))
+	assert.True(t, scopes.Has(ScopeAgentWrite))
+	assert.False(t, scopes.Has(ScopeMemoryRead))
+
+	_, err = NewScopes("agent:delete")
+	assert.Error(t, err)
+}
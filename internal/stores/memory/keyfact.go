@@ -1,6 +1,10 @@
 package memory
 
 import (
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,8 +17,13 @@ type KeyFact struct {
 	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"column:deleted_at;index"`
 
-	Key   string `json:"key" gorm:"column:fact_key;unique;not null;size:255"`
-	Value string `json:"value" gorm:"type:text"`
+	UserID string `json:"user_id" gorm:"column:user_id;not null;default:'';size:255;uniqueIndex:idx_key_facts_user_key"` // User that owns the fact
+	Key    string `json:"key" gorm:"column:fact_key;not null;size:255;uniqueIndex:idx_key_facts_user_key"`
+	Value  string `json:"value" gorm:"type:text"`
+
+	Tags            FactTags   `json:"tags,omitempty" gorm:"column:tags;size:1024;not null;default:''"`     // Optional labels used to group facts
+	SourceSessionID string     `json:"source_session_id,omitempty" gorm:"column:source_session_id;size:36"` // Session the fact was stored from
+	ExpiresAt       *time.Time `json:"expires_at,omitempty" gorm:"column:expires_at;index"`                 // When the fact should be forgotten (optional)
 }
 
 // TableName sets the table name for GORM
@@ -22,10 +31,68 @@ func (KeyFact) TableName() string {
 	return "key_facts"
 }
 
-// NewKeyFact creates a new key fact with auto-incrementing ID
-func NewKeyFact(key, value string) *KeyFact {
+// NewKeyFact creates a new key fact owned by a user with auto-incrementing ID
+func NewKeyFact(userID, key, value string) *KeyFact {
 	return &KeyFact{
-		Key:   key,
-		Value: value,
+		UserID: userID,
+		Key:    key,
+		Value:  value,
+	}
+}
+
+// IsExpired reports whether the fact's expiry time has passed
+func (f *KeyFact) IsExpired(now time.Time) bool {
+	return f.ExpiresAt != nil && !f.ExpiresAt.After(now)
+}
+
+// FactTags is a list of tags that implements database serialization. Tags are
+// stored as ",tag1,tag2," so a single tag can be matched with LIKE.
+type FactTags []string
+
+// NewFactTags normalizes tags by trimming, lowercasing and removing empty or duplicate tags
+func NewFactTags(tags ...string) FactTags {
+	result := FactTags{}
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag != "" && !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// Has reports whether the tags include the given tag
+func (t FactTags) Has(tag string) bool {
+	return slices.Contains(t, normalizeTag(tag))
+}
+
+// Value implements the driver.Valuer interface for database storage
+func (t FactTags) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return "", nil
 	}
+	return "," + strings.Join(t, ",") + ",", nil
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (t *FactTags) Scan(value any) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		raw = ""
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("cannot scan %T into FactTags", value)
+	}
+
+	*t = NewFactTags(strings.Split(raw, ",")...)
+	return nil
+}
+
+// Helper function to normalize a tag. Commas are removed since they separate stored tags.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(strings.ReplaceAll(tag, ",", "")))
 }
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFactTags(t *testing.T) {
+	tags := NewFactTags(" Health ", "work", "", "health", "a,b")
+	assert.Equal(t, FactTags{"health", "work", "ab"}, tags)
+	assert.True(t, tags.Has("HEALTH"))
+	assert.False(t, tags.Has("family"))
+
+	// Tags round trip through their stored form
+	value, err := tags.Value()
+	require.NoError(t, err)
+	assert.Equal(t, ",health,work,ab,", value)
+
+	var scanned FactTags
+	require.NoError(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, tags, scanned)
+
+	// Empty tags are stored as an empty string
+	value, err = FactTags{}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "", value)
+
+	require.NoError(t, scanned.Scan(nil))
+	assert.Empty(t, scanned)
+}
+
+func TestKeyFactIsExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	assert.False(t, (&KeyFact{}).IsExpired(now))
+	assert.True(t, (&KeyFact{ExpiresAt: &past}).IsExpired(now))
+	assert.True(t, (&KeyFact{ExpiresAt: &now}).IsExpired(now))
+	assert.False(t, (&KeyFact{ExpiresAt: &future}).IsExpired(now))
+}
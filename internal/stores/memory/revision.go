@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Revision operations
+const (
+	REVISION_SET    = "set"    // The fact was created or updated
+	REVISION_DELETE = "delete" // The fact was deleted
+	REVISION_EXPIRE = "expire" // The fact was deleted by the expiry sweeper
+	REVISION_REVERT = "revert" // The fact was reverted to an earlier value
+)
+
+// FactRevision records a single change to a key fact
+type FactRevision struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+
+	FactID    uint   `json:"fact_id" gorm:"column:fact_id;not null;index"`
+	UserID    string `json:"user_id" gorm:"column:user_id;not null;size:255;index:idx_key_fact_revisions_user_key"`
+	Key       string `json:"key" gorm:"column:fact_key;not null;size:255;index:idx_key_fact_revisions_user_key"`
+	Operation string `json:"operation" gorm:"column:operation;not null;size:16"`
+
+	PreviousValue *string `json:"previous_value" gorm:"column:previous_value;type:text"` // nil if the fact didn't exist before the change
+	NewValue      *string `json:"new_value" gorm:"column:new_value;type:text"`           // nil if the change removed the fact
+	SessionID     string  `json:"session_id,omitempty" gorm:"column:session_id;size:36"` // Session that triggered the change (optional)
+}
+
+// TableName sets the table name for GORM
+func (FactRevision) TableName() string {
+	return "key_fact_revisions"
+}
+
+// recordRevision is a helper that stores a revision as part of a fact change
+func recordRevision(tx *gorm.DB, fact *KeyFact, operation string, previous, next *string, sessionID string) error {
+	return tx.Create(&FactRevision{
+		FactID:        fact.ID,
+		UserID:        fact.UserID,
+		Key:           fact.Key,
+		Operation:     operation,
+		PreviousValue: previous,
+		NewValue:      next,
+		SessionID:     sessionID,
+	}).Error
+}
@@ -2,23 +2,40 @@ package memory
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
+	"time"
 
-	"gorm.io/driver/mysql"
+	"github.com/ethanbaker/assistant/internal/stores/database"
+	"github.com/ethanbaker/assistant/internal/stores/vector"
 	"gorm.io/gorm"
 )
 
+// ErrSemanticSearchDisabled is returned by semantic searches when no vector index is configured
+var ErrSemanticSearchDisabled = errors.New("semantic search is not enabled")
+
+// ErrFactNotFound is returned when a fact or one of its revisions doesn't exist
+var ErrFactNotFound = errors.New("fact not found")
+
 // Store handles memory persistence using GORM
 type Store struct {
-	db *gorm.DB
+	db    *gorm.DB
+	index vector.Index // optional index for semantic search
+}
+
+// ScoredFact is a fact found by semantic search
+type ScoredFact struct {
+	*KeyFact
+	Score float32 `json:"score"`
 }
 
-// NewStore creates a new memory store with GORM connection
+// NewStore creates a new memory store connected to the database URL (see database.ParseURL)
 func NewStore(databaseURL string) (*Store, error) {
-	db, err := gorm.Open(mysql.Open(databaseURL), &gorm.Config{})
+	db, err := database.Open(databaseURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
 	store := &Store{db: db}
@@ -33,45 +50,131 @@ func NewStore(databaseURL string) (*Store, error) {
 
 // migrate creates or updates the required database tables
 func (s *Store) migrate() error {
-	return s.db.AutoMigrate(&KeyFact{})
+	if err := s.db.AutoMigrate(&KeyFact{}, &FactRevision{}); err != nil {
+		return err
+	}
+
+	// Fact keys used to be globally unique; they are now unique per user
+	migrator := s.db.Migrator()
+	for _, name := range []string{"uni_key_facts_fact_key", "fact_key"} {
+		if migrator.HasConstraint(&KeyFact{}, name) {
+			if err := migrator.DropConstraint(&KeyFact{}, name); err != nil {
+				return fmt.Errorf("failed to drop unique constraint %s: %w", name, err)
+			}
+		} else if migrator.HasIndex(&KeyFact{}, name) {
+			if err := migrator.DropIndex(&KeyFact{}, name); err != nil {
+				return fmt.Errorf("failed to drop unique index %s: %w", name, err)
+			}
+		}
+	}
+
+	return nil
 }
 
-// SetFact stores or updates a key fact
-func (s *Store) SetFact(ctx context.Context, key, value string) error {
-	fact := &KeyFact{
-		Key:   key,
-		Value: value,
+// AssignUnownedFacts gives every fact without an owner to the given user.
+// It migrates facts stored before facts were scoped per user, and returns how many were assigned.
+func (s *Store) AssignUnownedFacts(ctx context.Context, userID string) (int64, error) {
+	if userID == "" {
+		return 0, fmt.Errorf("user ID cannot be empty")
 	}
 
-	// GORM's Save will create or update based on primary key
-	// For upsert behavior on unique key, we use Create with OnConflict
-	result := s.db.WithContext(ctx).Where("fact_key = ?", key).First(&KeyFact{})
+	result := s.db.WithContext(ctx).Model(&KeyFact{}).Where("user_id = ?", "").Update("user_id", userID)
 	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			// Create new record
-			if err := s.db.WithContext(ctx).Create(fact).Error; err != nil {
-				return fmt.Errorf("failed to create fact: %w", err)
+		return 0, fmt.Errorf("failed to assign facts: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// SetIndex sets the vector index used for semantic search (for dependency injection)
+func (s *Store) SetIndex(index vector.Index) {
+	s.index = index
+}
+
+// GetDB returns the underlying GORM database connection
+func (s *Store) GetDB() *gorm.DB {
+	return s.db
+}
+
+// SetFact stores or updates a user's key fact. A previously deleted or expired fact
+// with the same key is restored with the new value. The change is recorded in the
+// fact's history along with the fact's source session.
+func (s *Store) SetFact(ctx context.Context, fact *KeyFact) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return saveFact(tx, fact, REVISION_SET, fact.SourceSessionID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.indexFact(ctx, fact)
+	return nil
+}
+
+// ImportResult summarizes a bulk fact import
+type ImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"` // Existing facts kept because overwrite was disabled, or facts that already expired
+}
+
+// ImportFacts stores many facts in a single transaction. Existing facts are only replaced when overwrite is set.
+func (s *Store) ImportFacts(ctx context.Context, facts []*KeyFact, overwrite bool) (*ImportResult, error) {
+	result := &ImportResult{}
+	now := time.Now()
+
+	var saved []*KeyFact
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, fact := range facts {
+			if fact.UserID == "" || fact.Key == "" {
+				return fmt.Errorf("facts must have a user ID and key")
+			}
+			if fact.IsExpired(now) {
+				result.Skipped++
+				continue
+			}
+
+			// Check whether the fact currently exists
+			var count int64
+			if err := tx.Model(&KeyFact{}).
+				Where("user_id = ? AND fact_key = ?", fact.UserID, fact.Key).
+				Where("expires_at IS NULL OR expires_at > ?", now.UTC()).
+				Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to check existing fact: %w", err)
+			}
+			if count > 0 && !overwrite {
+				result.Skipped++
+				continue
+			}
+
+			if err := saveFact(tx, fact, REVISION_SET, fact.SourceSessionID); err != nil {
+				return fmt.Errorf("failed to import fact '%s': %w", fact.Key, err)
+			}
+			saved = append(saved, fact)
+
+			if count > 0 {
+				result.Updated++
+			} else {
+				result.Created++
 			}
-		} else {
-			// Unexpected error state
-			return fmt.Errorf("failed to check existing fact: %w", result.Error)
-		}
-	} else {
-		// Update existing record
-		if err := s.db.WithContext(ctx).Model(&KeyFact{}).Where("fact_key = ?", key).Updates(map[string]interface{}{
-			"value": value,
-		}).Error; err != nil {
-			return fmt.Errorf("failed to update fact: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	for _, fact := range saved {
+		s.indexFact(ctx, fact)
+	}
+
+	return result, nil
 }
 
-// GetFact retrieves a fact by key
-func (s *Store) GetFact(ctx context.Context, key string) (*KeyFact, error) {
+// GetFact retrieves a user's fact by key
+func (s *Store) GetFact(ctx context.Context, userID, key string) (*KeyFact, error) {
 	var fact KeyFact
-	result := s.db.WithContext(ctx).Where("fact_key = ?", key).First(&fact)
+	result := s.active(ctx).Where("user_id = ? AND fact_key = ?", userID, key).First(&fact)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, nil // Not found
@@ -82,10 +185,11 @@ func (s *Store) GetFact(ctx context.Context, key string) (*KeyFact, error) {
 	return &fact, nil
 }
 
-// SearchFacts searches for facts by key pattern
-func (s *Store) SearchFacts(ctx context.Context, pattern string) ([]*KeyFact, error) {
+// SearchFacts searches for a user's facts whose key or value contains the pattern
+func (s *Store) SearchFacts(ctx context.Context, userID, pattern string) ([]*KeyFact, error) {
 	var facts []*KeyFact
-	result := s.db.WithContext(ctx).Where("fact_key LIKE ?", "%"+pattern+"%").Find(&facts)
+	like := "%" + pattern + "%"
+	result := s.active(ctx).Where("user_id = ?", userID).Where("fact_key LIKE ? OR value LIKE ?", like, like).Order("fact_key").Find(&facts)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to search facts: %w", result.Error)
 	}
@@ -93,31 +197,305 @@ func (s *Store) SearchFacts(ctx context.Context, pattern string) ([]*KeyFact, er
 	return facts, nil
 }
 
-// ListAllFacts returns all stored facts
-func (s *Store) ListAllFacts(ctx context.Context) ([]*KeyFact, error) {
+// SearchFactsSemantic finds the user's facts most similar in meaning to the query
+func (s *Store) SearchFactsSemantic(ctx context.Context, userID, query string, limit int) ([]*ScoredFact, error) {
+	if s.index == nil {
+		return nil, ErrSemanticSearchDisabled
+	}
+
+	matches, err := s.index.Search(ctx, vector.SOURCE_FACT, userID, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search facts: %w", err)
+	}
+
+	// Load the current version of each matching fact
+	facts := []*ScoredFact{}
+	for _, match := range matches {
+		fact, err := s.GetFact(ctx, userID, match.SourceID)
+		if err != nil {
+			return nil, err
+		}
+		if fact == nil {
+			continue // Deleted since it was indexed
+		}
+
+		facts = append(facts, &ScoredFact{KeyFact: fact, Score: match.Score})
+	}
+
+	return facts, nil
+}
+
+// IndexFacts adds every user's facts to the vector index. Unchanged facts are skipped by the index.
+func (s *Store) IndexFacts(ctx context.Context) error {
+	if s.index == nil {
+		return ErrSemanticSearchDisabled
+	}
+
 	var facts []*KeyFact
-	result := s.db.WithContext(ctx).Order("fact_key").Find(&facts)
-	if result.Error != nil {
-		return nil, fmt.Errorf("failed to list facts: %w", result.Error)
+	if err := s.db.WithContext(ctx).Find(&facts).Error; err != nil {
+		return fmt.Errorf("failed to list facts: %w", err)
+	}
+
+	for _, fact := range facts {
+		if err := s.index.Upsert(ctx, factDocument(fact)); err != nil {
+			return fmt.Errorf("failed to index fact '%s': %w", fact.Key, err)
+		}
+	}
+
+	return nil
+}
+
+// ListAllFacts returns all of a user's stored facts
+func (s *Store) ListAllFacts(ctx context.Context, userID string) ([]*KeyFact, error) {
+	return s.ListFactsByTag(ctx, userID, "")
+}
+
+// ListFactsByTag returns a user's stored facts with the given tag. An empty tag lists every fact.
+func (s *Store) ListFactsByTag(ctx context.Context, userID, tag string) ([]*KeyFact, error) {
+	query := s.active(ctx).Where("user_id = ?", userID)
+	if tag = normalizeTag(tag); tag != "" {
+		query = query.Where("tags LIKE ?", "%,"+tag+",%")
+	}
+
+	var facts []*KeyFact
+	if err := query.Order("fact_key").Find(&facts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list facts: %w", err)
 	}
 
 	return facts, nil
 }
 
-// DeleteFact removes a fact by key
-func (s *Store) DeleteFact(ctx context.Context, key string) error {
-	result := s.db.WithContext(ctx).Where("fact_key = ?", key).Delete(&KeyFact{})
+// DeleteFact removes a user's fact by key. The change is recorded in the fact's history
+// along with the session that triggered it (optional).
+func (s *Store) DeleteFact(ctx context.Context, userID, key, sessionID string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var fact KeyFact
+		if err := tx.Where("user_id = ? AND fact_key = ?", userID, key).First(&fact).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("%w: '%s'", ErrFactNotFound, key)
+			}
+			return fmt.Errorf("failed to get fact: %w", err)
+		}
+
+		return removeFact(tx, &fact, REVISION_DELETE, sessionID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.unindexFact(ctx, userID, key)
+	return nil
+}
+
+// DeleteExpiredFacts soft-deletes every fact whose expiry time has passed, returning how many were deleted
+func (s *Store) DeleteExpiredFacts(ctx context.Context) (int64, error) {
+	var expired []*KeyFact
+	if err := s.db.WithContext(ctx).Where("expires_at IS NOT NULL AND expires_at <= ?", time.Now().UTC()).Find(&expired).Error; err != nil {
+		return 0, fmt.Errorf("failed to find expired facts: %w", err)
+	}
+
+	var count int64
+	for _, fact := range expired {
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return removeFact(tx, fact, REVISION_EXPIRE, "")
+		})
+		if err != nil {
+			return count, fmt.Errorf("failed to delete expired fact '%s': %w", fact.Key, err)
+		}
+
+		s.unindexFact(ctx, fact.UserID, fact.Key)
+		count++
+	}
+
+	return count, nil
+}
+
+// GetFactHistory returns the revisions of a user's fact, newest first
+func (s *Store) GetFactHistory(ctx context.Context, userID, key string) ([]*FactRevision, error) {
+	var revisions []*FactRevision
+	result := s.db.WithContext(ctx).Where("user_id = ? AND fact_key = ?", userID, key).Order("id DESC").Find(&revisions)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get fact history: %w", result.Error)
+	}
+
+	return revisions, nil
+}
+
+// RevertFact undoes a revision of a user's fact by restoring the value the fact had before it.
+// If the fact didn't exist before the revision, it is deleted and nil is returned. The revert
+// itself is recorded as a new revision, so it can be undone too.
+func (s *Store) RevertFact(ctx context.Context, userID, key string, revisionID uint, sessionID string) (*KeyFact, error) {
+	var restored *KeyFact
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var revision FactRevision
+		if err := tx.Where("id = ? AND user_id = ? AND fact_key = ?", revisionID, userID, key).First(&revision).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("%w: no revision %d for '%s'", ErrFactNotFound, revisionID, key)
+			}
+			return fmt.Errorf("failed to get fact revision: %w", err)
+		}
+
+		// The fact didn't exist before the revision, so reverting removes it
+		if revision.PreviousValue == nil {
+			var fact KeyFact
+			if err := tx.Where("user_id = ? AND fact_key = ?", userID, key).First(&fact).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return nil // Already gone
+				}
+				return fmt.Errorf("failed to get fact: %w", err)
+			}
+
+			return removeFact(tx, &fact, REVISION_REVERT, sessionID)
+		}
+
+		// Otherwise restore the previous value, keeping the fact's current tags
+		var current KeyFact
+		if err := tx.Unscoped().Where("user_id = ? AND fact_key = ?", userID, key).First(&current).Error; err != nil && err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to get fact: %w", err)
+		}
+
+		restored = NewKeyFact(userID, key, *revision.PreviousValue)
+		restored.Tags = current.Tags
+		restored.SourceSessionID = sessionID
+		if !current.IsExpired(time.Now()) {
+			restored.ExpiresAt = current.ExpiresAt
+		}
+
+		return saveFact(tx, restored, REVISION_REVERT, sessionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if restored == nil {
+		s.unindexFact(ctx, userID, key)
+	} else {
+		s.indexFact(ctx, restored)
+	}
+
+	return restored, nil
+}
+
+// RunExpirySweeper deletes expired facts every interval until the context is done
+func (s *Store) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := s.DeleteExpiredFacts(ctx)
+			if err != nil {
+				log.Printf("[MEMORY]: Failed to sweep expired facts: %v", err)
+			} else if count > 0 {
+				log.Printf("[MEMORY]: Deleted %d expired facts", count)
+			}
+		}
+	}
+}
+
+// saveFact is a helper that creates or updates a fact and records the change in a transaction
+func saveFact(tx *gorm.DB, fact *KeyFact, operation, sessionID string) error {
+	if fact.Tags == nil {
+		fact.Tags = FactTags{}
+	}
+
+	// Look up the existing record, including soft-deleted ones since keys are unique per user
+	var existing KeyFact
+	var previous *string
+	result := tx.Unscoped().Where("user_id = ? AND fact_key = ?", fact.UserID, fact.Key).First(&existing)
 	if result.Error != nil {
-		return fmt.Errorf("failed to delete fact: %w", result.Error)
+		if result.Error != gorm.ErrRecordNotFound {
+			// Unexpected error state
+			return fmt.Errorf("failed to check existing fact: %w", result.Error)
+		}
+
+		// Create new record
+		if err := tx.Create(fact).Error; err != nil {
+			return fmt.Errorf("failed to create fact: %w", err)
+		}
+	} else {
+		// Deleted and expired facts count as not existing
+		if !existing.DeletedAt.Valid && !existing.IsExpired(time.Now()) {
+			value := existing.Value
+			previous = &value
+		}
+
+		// Update existing record
+		if err := tx.Unscoped().Model(&existing).Updates(map[string]interface{}{
+			"value":             fact.Value,
+			"tags":              fact.Tags,
+			"source_session_id": fact.SourceSessionID,
+			"expires_at":        fact.ExpiresAt,
+			"deleted_at":        nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update fact: %w", err)
+		}
+		fact.ID = existing.ID
+	}
+
+	value := fact.Value
+	if err := recordRevision(tx, fact, operation, previous, &value, sessionID); err != nil {
+		return fmt.Errorf("failed to record fact revision: %w", err)
 	}
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("fact with key '%s' not found", key)
+	return nil
+}
+
+// removeFact is a helper that soft-deletes a fact and records the change in a transaction
+func removeFact(tx *gorm.DB, fact *KeyFact, operation, sessionID string) error {
+	if err := tx.Delete(fact).Error; err != nil {
+		return fmt.Errorf("failed to delete fact: %w", err)
+	}
+
+	previous := fact.Value
+	if err := recordRevision(tx, fact, operation, &previous, nil, sessionID); err != nil {
+		return fmt.Errorf("failed to record fact revision: %w", err)
 	}
 
 	return nil
 }
 
+// active is a helper that scopes a query to facts that haven't expired yet
+func (s *Store) active(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Where("expires_at IS NULL OR expires_at > ?", time.Now().UTC())
+}
+
+// indexFact is a helper to keep a fact's vector up to date. Indexing is best-effort so a
+// failing embedder never prevents a fact from being stored.
+func (s *Store) indexFact(ctx context.Context, fact *KeyFact) {
+	if s.index == nil {
+		return
+	}
+
+	if err := s.index.Upsert(ctx, factDocument(fact)); err != nil {
+		log.Printf("[MEMORY]: Failed to index fact '%s': %v", fact.Key, err)
+	}
+}
+
+// unindexFact is a helper to remove a deleted fact from the index. Failures are only logged.
+func (s *Store) unindexFact(ctx context.Context, userID, key string) {
+	if s.index == nil {
+		return
+	}
+
+	if err := s.index.Delete(ctx, vector.SOURCE_FACT, userID, key); err != nil {
+		log.Printf("[MEMORY]: Failed to remove fact '%s' from index: %v", key, err)
+	}
+}
+
+// Helper function to build the indexed document for a fact
+func factDocument(fact *KeyFact) vector.Document {
+	return vector.Document{
+		Source:   vector.SOURCE_FACT,
+		SourceID: fact.Key,
+		UserID:   fact.UserID,
+		Content:  fact.Key + ": " + fact.Value,
+	}
+}
+
 // Close closes the database connection
 func (s *Store) Close() error {
 	sqlDB, err := s.db.DB()
@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreFacts(t *testing.T) {
+	ctx := context.Background()
+	store := storetest.New(t, NewStore)
+
+	fact := NewKeyFact("user-a", "favorite_color", "green")
+	fact.Tags = NewFactTags("preferences")
+	require.NoError(t, store.SetFact(ctx, fact))
+	require.NoError(t, store.SetFact(ctx, NewKeyFact("user-a", "city", "Raleigh")))
+	require.NoError(t, store.SetFact(ctx, NewKeyFact("user-b", "favorite_color", "blue")))
+
+	// Facts are scoped per user
+	got, err := store.GetFact(ctx, "user-a", "favorite_color")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "green", got.Value)
+	assert.Equal(t, FactTags{"preferences"}, got.Tags)
+
+	got, err = store.GetFact(ctx, "user-b", "favorite_color")
+	require.NoError(t, err)
+	assert.Equal(t, "blue", got.Value)
+
+	// Tags filter listed facts
+	facts, err := store.ListFactsByTag(ctx, "user-a", "preferences")
+	require.NoError(t, err)
+	require.Len(t, facts, 1)
+	assert.Equal(t, "favorite_color", facts[0].Key)
+
+	facts, err = store.ListAllFacts(ctx, "user-a")
+	require.NoError(t, err)
+	assert.Len(t, facts, 2)
+
+	// Search matches keys and values
+	facts, err = store.SearchFacts(ctx, "user-a", "Rale")
+	require.NoError(t, err)
+	require.Len(t, facts, 1)
+	assert.Equal(t, "city", facts[0].Key)
+
+	// Deleted facts can be stored again
+	require.NoError(t, store.DeleteFact(ctx, "user-a", "city", ""))
+	assert.ErrorIs(t, store.DeleteFact(ctx, "user-a", "city", ""), ErrFactNotFound)
+
+	got, err = store.GetFact(ctx, "user-a", "city")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	require.NoError(t, store.SetFact(ctx, NewKeyFact("user-a", "city", "Durham")))
+	got, err = store.GetFact(ctx, "user-a", "city")
+	require.NoError(t, err)
+	assert.Equal(t, "Durham", got.Value)
+}
+
+func TestStoreExpiredFacts(t *testing.T) {
+	ctx := context.Background()
+	store := storetest.New(t, NewStore)
+
+	past := time.Now().Add(-time.Minute).UTC()
+	future := time.Now().Add(time.Hour).UTC()
+
+	expired := NewKeyFact("user", "parking_spot", "B12")
+	expired.ExpiresAt = &past
+	require.NoError(t, store.SetFact(ctx, expired))
+
+	active := NewKeyFact("user", "hotel", "Room 301")
+	active.ExpiresAt = &future
+	require.NoError(t, store.SetFact(ctx, active))
+
+	// Expired facts are hidden before the sweeper runs
+	got, err := store.GetFact(ctx, "user", "parking_spot")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	count, err := store.DeleteExpiredFacts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	facts, err := store.ListAllFacts(ctx, "user")
+	require.NoError(t, err)
+	require.Len(t, facts, 1)
+	assert.Equal(t, "hotel", facts[0].Key)
+
+	// The sweep is recorded in the fact's history
+	revisions, err := store.GetFactHistory(ctx, "user", "parking_spot")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, REVISION_EXPIRE, revisions[0].Operation)
+}
+
+func TestStoreFactHistory(t *testing.T) {
+	ctx := context.Background()
+	store := storetest.New(t, NewStore)
+
+	first := NewKeyFact("user", "coffee", "latte")
+	first.SourceSessionID = "session-1"
+	require.NoError(t, store.SetFact(ctx, first))
+
+	second := NewKeyFact("user", "coffee", "espresso")
+	second.SourceSessionID = "session-2"
+	require.NoError(t, store.SetFact(ctx, second))
+
+	// Revisions are returned newest first with the previous value and session
+	revisions, err := store.GetFactHistory(ctx, "user", "coffee")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, REVISION_SET, revisions[0].Operation)
+	assert.Equal(t, "latte", *revisions[0].PreviousValue)
+	assert.Equal(t, "espresso", *revisions[0].NewValue)
+	assert.Equal(t, "session-2", revisions[0].SessionID)
+	assert.Nil(t, revisions[1].PreviousValue)
+
+	// Reverting the update restores the previous value
+	fact, err := store.RevertFact(ctx, "user", "coffee", revisions[0].ID, "session-3")
+	require.NoError(t, err)
+	require.NotNil(t, fact)
+	assert.Equal(t, "latte", fact.Value)
+
+	got, err := store.GetFact(ctx, "user", "coffee")
+	require.NoError(t, err)
+	assert.Equal(t, "latte", got.Value)
+
+	// Reverting the creation removes the fact
+	fact, err = store.RevertFact(ctx, "user", "coffee", revisions[1].ID, "")
+	require.NoError(t, err)
+	assert.Nil(t, fact)
+
+	got, err = store.GetFact(ctx, "user", "coffee")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	// A deleted fact can be brought back by reverting its deletion
+	revisions, err = store.GetFactHistory(ctx, "user", "coffee")
+	require.NoError(t, err)
+	require.Len(t, revisions, 4)
+	assert.Equal(t, REVISION_REVERT, revisions[0].Operation)
+
+	fact, err = store.RevertFact(ctx, "user", "coffee", revisions[0].ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, "latte", fact.Value)
+
+	// Revisions of other users can't be reverted
+	_, err = store.RevertFact(ctx, "other", "coffee", revisions[0].ID, "")
+	assert.ErrorIs(t, err, ErrFactNotFound)
+}
+
+func TestStoreRevertDeletedFact(t *testing.T) {
+	ctx := context.Background()
+	store := storetest.New(t, NewStore)
+
+	require.NoError(t, store.SetFact(ctx, NewKeyFact("user", "city", "Raleigh")))
+	require.NoError(t, store.SetFact(ctx, NewKeyFact("other", "city", "Durham")))
+	require.NoError(t, store.DeleteFact(ctx, "user", "city", "session-1"))
+
+	// The deletion is recorded with the removed value and the session that removed it
+	revisions, err := store.GetFactHistory(ctx, "user", "city")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, REVISION_DELETE, revisions[0].Operation)
+	assert.Equal(t, "Raleigh", *revisions[0].PreviousValue)
+	assert.Nil(t, revisions[0].NewValue)
+	assert.Equal(t, "session-1", revisions[0].SessionID)
+
+	// Another user can't revert the deletion, even though they have a fact with the same key
+	_, err = store.RevertFact(ctx, "other", "city", revisions[0].ID, "")
+	assert.ErrorIs(t, err, ErrFactNotFound)
+
+	got, err := store.GetFact(ctx, "other", "city")
+	require.NoError(t, err)
+	assert.Equal(t, "Durham", got.Value)
+
+	// The owner can bring the fact back
+	fact, err := store.RevertFact(ctx, "user", "city", revisions[0].ID, "session-2")
+	require.NoError(t, err)
+	require.NotNil(t, fact)
+	assert.Equal(t, "Raleigh", fact.Value)
+
+	got, err = store.GetFact(ctx, "user", "city")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "Raleigh", got.Value)
+
+	revisions, err = store.GetFactHistory(ctx, "user", "city")
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, REVISION_REVERT, revisions[0].Operation)
+	assert.Nil(t, revisions[0].PreviousValue)
+	assert.Equal(t, "Raleigh", *revisions[0].NewValue)
+}
+
+func TestStoreImportFacts(t *testing.T) {
+	ctx := context.Background()
+	store := storetest.New(t, NewStore)
+
+	require.NoError(t, store.SetFact(ctx, NewKeyFact("user", "city", "Raleigh")))
+
+	past := time.Now().Add(-time.Minute)
+	expired := NewKeyFact("user", "parking_spot", "B12")
+	expired.ExpiresAt = &past
+
+	// Existing facts are skipped unless overwrite is set
+	result, err := store.ImportFacts(ctx, []*KeyFact{
+		NewKeyFact("user", "city", "Durham"),
+		NewKeyFact("user", "pet", "cat"),
+		expired,
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, &ImportResult{Created: 1, Skipped: 2}, result)
+
+	got, err := store.GetFact(ctx, "user", "city")
+	require.NoError(t, err)
+	assert.Equal(t, "Raleigh", got.Value)
+
+	result, err = store.ImportFacts(ctx, []*KeyFact{NewKeyFact("user", "city", "Durham")}, true)
+	require.NoError(t, err)
+	assert.Equal(t, &ImportResult{Updated: 1}, result)
+
+	got, err = store.GetFact(ctx, "user", "city")
+	require.NoError(t, err)
+	assert.Equal(t, "Durham", got.Value)
+}
@@ -0,0 +1,270 @@
+package outreach
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ethanbaker/assistant/pkg/outreach"
+	"gorm.io/gorm"
+)
+
+// CreateDelivery stores a response and a pending delivery for it
+func (s *Store) CreateDelivery(response *outreach.Response) (*outreach.Delivery, error) {
+	if response == nil {
+		return nil, fmt.Errorf("response cannot be nil")
+	}
+	if response.IdempotencyId == "" {
+		return nil, fmt.Errorf("idempotency_id cannot be empty")
+	}
+
+	now := time.Now().UTC()
+	model := &DeliveryModel{
+		Response: ResponseModel{
+			IdempotencyID: response.IdempotencyId,
+			Key:           response.Key,
+			Data:          ResponseData{response},
+			ClientIDs:     joinClientIDs(response.ClientIds()),
+		},
+		Status:        outreach.DeliveryPending,
+		NextAttemptAt: &now,
+	}
+
+	// Create the response first; saving it as an association would silently skip duplicates
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.Response).Error; err != nil {
+			return err
+		}
+
+		model.ResponseID = model.Response.ID
+		return tx.Omit("Response").Create(model).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create delivery: %w", err)
+	}
+
+	return model.toDelivery(), nil
+}
+
+// GetDelivery retrieves a delivery and its response by ID
+func (s *Store) GetDelivery(id uint) (*outreach.Delivery, error) {
+	var model DeliveryModel
+	if err := s.db.Preload("Response").First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("delivery %d: %w", id, outreach.ErrDeliveryNotFound)
+		}
+		return nil, fmt.Errorf("failed to get delivery: %w", err)
+	}
+
+	return model.toDelivery(), nil
+}
+
+// ListDeliveries returns deliveries matching the filter, newest first
+func (s *Store) ListDeliveries(filter outreach.DeliveryFilter) ([]*outreach.Delivery, error) {
+	query := s.db.Preload("Response").Order("id DESC")
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ClientID != "" {
+		responses := s.db.Model(&ResponseModel{}).Select("id").Where("client_ids LIKE ? ESCAPE '!'", "%,"+escapeLike(filter.ClientID)+",%")
+		query = query.Where("response_id IN (?)", responses)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		// An offset needs a limit in SQL
+		if filter.Limit <= 0 {
+			query = query.Limit(math.MaxInt32)
+		}
+		query = query.Offset(filter.Offset)
+	}
+
+	var models []DeliveryModel
+	if err := query.Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+
+	deliveries := make([]*outreach.Delivery, len(models))
+	for i := range models {
+		deliveries[i] = models[i].toDelivery()
+	}
+
+	return deliveries, nil
+}
+
+// UpdateDelivery saves the state of a delivery
+func (s *Store) UpdateDelivery(delivery *outreach.Delivery) error {
+	result := s.db.Model(&DeliveryModel{}).Where("id = ?", delivery.ID).Updates(map[string]any{
+		"status":          delivery.Status,
+		"rounds":          delivery.Rounds,
+		"attempts":        delivery.Attempts,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"delivered_at":    delivery.DeliveredAt,
+		"delivered_to":    delivery.DeliveredTo,
+		"last_error":      delivery.LastError,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update delivery: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("delivery %d: %w", delivery.ID, outreach.ErrDeliveryNotFound)
+	}
+
+	return nil
+}
+
+// RecordDeliveryAttempt stores a single delivery attempt
+func (s *Store) RecordDeliveryAttempt(attempt *outreach.DeliveryAttempt) error {
+	model := &DeliveryAttemptModel{
+		DeliveryID: attempt.DeliveryID,
+		ClientID:   attempt.ClientID,
+		StatusCode: attempt.StatusCode,
+		Error:      attempt.Error,
+		LatencyMs:  attempt.Latency.Milliseconds(),
+	}
+
+	if err := s.db.Create(model).Error; err != nil {
+		return fmt.Errorf("failed to record delivery attempt: %w", err)
+	}
+
+	attempt.ID = model.ID
+	attempt.CreatedAt = model.CreatedAt
+	return nil
+}
+
+// ListDeliveryAttempts returns the attempts made for a delivery, oldest first
+func (s *Store) ListDeliveryAttempts(deliveryID uint) ([]*outreach.DeliveryAttempt, error) {
+	var models []DeliveryAttemptModel
+	if err := s.db.Where("delivery_id = ?", deliveryID).Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list delivery attempts: %w", err)
+	}
+
+	attempts := make([]*outreach.DeliveryAttempt, len(models))
+	for i, model := range models {
+		attempts[i] = &outreach.DeliveryAttempt{
+			ID:         model.ID,
+			CreatedAt:  model.CreatedAt,
+			DeliveryID: model.DeliveryID,
+			ClientID:   model.ClientID,
+			StatusCode: model.StatusCode,
+			Error:      model.Error,
+			Latency:    time.Duration(model.LatencyMs) * time.Millisecond,
+		}
+	}
+
+	return attempts, nil
+}
+
+// DeadLetterDelivery marks a delivery as dead and moves it to the dead-letter table
+func (s *Store) DeadLetterDelivery(id uint, reason string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&DeliveryModel{}).Where("id = ?", id).Updates(map[string]any{
+			"status":          outreach.DeliveryDead,
+			"next_attempt_at": nil,
+			"last_error":      reason,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update delivery: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("delivery %d: %w", id, outreach.ErrDeliveryNotFound)
+		}
+
+		if err := tx.Create(&DeadLetterModel{DeliveryID: id, Reason: reason}).Error; err != nil {
+			return fmt.Errorf("failed to create dead letter: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ListDeadLetters returns the dead letters recorded for a delivery, oldest first
+func (s *Store) ListDeadLetters(deliveryID uint) ([]*outreach.DeadLetter, error) {
+	var models []DeadLetterModel
+	if err := s.db.Where("delivery_id = ?", deliveryID).Order("id").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	letters := make([]*outreach.DeadLetter, len(models))
+	for i, model := range models {
+		letters[i] = &outreach.DeadLetter{
+			ID:         model.ID,
+			CreatedAt:  model.CreatedAt,
+			DeliveryID: model.DeliveryID,
+			Reason:     model.Reason,
+			ReplayedAt: model.ReplayedAt,
+		}
+	}
+
+	return letters, nil
+}
+
+// ReplayDelivery queues a finished delivery to be sent again, starting a fresh set of rounds
+func (s *Store) ReplayDelivery(id uint) (*outreach.Delivery, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var model DeliveryModel
+		if err := tx.First(&model, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("delivery %d: %w", id, outreach.ErrDeliveryNotFound)
+			}
+			return fmt.Errorf("failed to get delivery: %w", err)
+		}
+
+		if model.Status == outreach.DeliveryPending || model.Status == outreach.DeliveryRetrying {
+			return fmt.Errorf("delivery %d: %w", id, outreach.ErrDeliveryInProgress)
+		}
+
+		now := time.Now().UTC()
+		if err := tx.Model(&model).Updates(map[string]any{
+			"status":          outreach.DeliveryPending,
+			"rounds":          0,
+			"next_attempt_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update delivery: %w", err)
+		}
+
+		if err := tx.Model(&DeadLetterModel{}).Where("delivery_id = ? AND replayed_at IS NULL", id).Update("replayed_at", now).Error; err != nil {
+			return fmt.Errorf("failed to update dead letters: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetDelivery(id)
+}
+
+// migrateResponseClients fills in the client IDs of responses stored before they were kept in their own column
+func (s *Store) migrateResponseClients() error {
+	var models []ResponseModel
+	if err := s.db.Where("client_ids IS NULL").Find(&models).Error; err != nil {
+		return fmt.Errorf("failed to find responses without client IDs: %w", err)
+	}
+
+	for _, model := range models {
+		var clientIDs []string
+		if model.Data.Response != nil {
+			clientIDs = model.Data.Response.ClientIds()
+		}
+
+		if err := s.db.Model(&ResponseModel{}).Where("id = ?", model.ID).Update("client_ids", joinClientIDs(clientIDs)).Error; err != nil {
+			return fmt.Errorf("failed to store client IDs of response %d: %w", model.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// joinClientIDs formats client IDs for the client_ids column, wrapped in commas so each ID can be matched whole
+func joinClientIDs(clientIDs []string) string {
+	return "," + strings.Join(clientIDs, ",") + ","
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, using '!' as the escape character
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
@@ -0,0 +1,173 @@
+package outreach
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/storetest"
+	"github.com/ethanbaker/assistant/pkg/outreach"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreDeliveries(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			testStoreDeliveries(t, store)
+		})
+	}
+}
+
+func testStoreDeliveries(t *testing.T, store outreach.StoreInterface) {
+	response := &outreach.Response{
+		Status:        "success",
+		Key:           "daily-digest",
+		IdempotencyId: "daily-digest-1",
+		Content:       "Good morning",
+		Clients: []struct {
+			Id          string `json:"id"`
+			CallbackUrl string `json:"callback_url"`
+		}{{Id: "discord", CallbackUrl: "http://localhost:8081/outreach"}},
+	}
+
+	delivery, err := store.CreateDelivery(response)
+	require.NoError(t, err)
+	assert.Equal(t, outreach.DeliveryPending, delivery.Status)
+	require.NotNil(t, delivery.NextAttemptAt)
+
+	// Responses are stored once
+	_, err = store.CreateDelivery(response)
+	assert.Error(t, err)
+
+	// The response is stored with the delivery
+	delivery, err = store.GetDelivery(delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Good morning", delivery.Response.Content)
+	require.Len(t, delivery.Response.Clients, 1)
+	assert.Equal(t, "discord", delivery.Response.Clients[0].Id)
+
+	_, err = store.GetDelivery(delivery.ID + 100)
+	assert.ErrorIs(t, err, outreach.ErrDeliveryNotFound)
+
+	// Deliveries that are still being sent can't be replayed
+	_, err = store.ReplayDelivery(delivery.ID)
+	assert.ErrorIs(t, err, outreach.ErrDeliveryInProgress)
+
+	// Record a failed attempt and schedule a retry
+	require.NoError(t, store.RecordDeliveryAttempt(&outreach.DeliveryAttempt{
+		DeliveryID: delivery.ID,
+		ClientID:   "discord",
+		StatusCode: 502,
+		Error:      "bad gateway",
+		Latency:    120 * time.Millisecond,
+	}))
+
+	next := time.Now().Add(time.Minute)
+	delivery.Status = outreach.DeliveryRetrying
+	delivery.Rounds = 1
+	delivery.Attempts = 1
+	delivery.NextAttemptAt = &next
+	delivery.LastError = "bad gateway"
+	require.NoError(t, store.UpdateDelivery(delivery))
+
+	attempts, err := store.ListDeliveryAttempts(delivery.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	assert.Equal(t, 502, attempts[0].StatusCode)
+	assert.Equal(t, 120*time.Millisecond, attempts[0].Latency)
+
+	retrying, err := store.ListDeliveries(outreach.DeliveryFilter{Status: outreach.DeliveryRetrying})
+	require.NoError(t, err)
+	require.Len(t, retrying, 1)
+	assert.Equal(t, 1, retrying[0].Rounds)
+
+	// Exhausted deliveries move to the dead-letter table
+	require.NoError(t, store.DeadLetterDelivery(delivery.ID, "gave up"))
+
+	delivery, err = store.GetDelivery(delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, outreach.DeliveryDead, delivery.Status)
+	assert.Nil(t, delivery.NextAttemptAt)
+
+	letters, err := store.ListDeadLetters(delivery.ID)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, "gave up", letters[0].Reason)
+	assert.Nil(t, letters[0].ReplayedAt)
+
+	// Replaying starts a fresh set of rounds and marks the dead letter as replayed
+	delivery, err = store.ReplayDelivery(delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, outreach.DeliveryPending, delivery.Status)
+	assert.Equal(t, 0, delivery.Rounds)
+	assert.Equal(t, 1, delivery.Attempts)
+
+	letters, err = store.ListDeadLetters(delivery.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, letters[0].ReplayedAt)
+
+	// Newest deliveries are listed first
+	second := *response
+	second.IdempotencyId = "daily-digest-2"
+	_, err = store.CreateDelivery(&second)
+	require.NoError(t, err)
+
+	deliveries, err := store.ListDeliveries(outreach.DeliveryFilter{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "daily-digest-2", deliveries[0].Response.IdempotencyId)
+
+	deliveries, err = store.ListDeliveries(outreach.DeliveryFilter{Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "daily-digest-1", deliveries[0].Response.IdempotencyId)
+
+	// Deliveries can be limited to the responses sent to a client
+	third := *response
+	third.IdempotencyId = "daily-digest-3"
+	third.Clients = []struct {
+		Id          string `json:"id"`
+		CallbackUrl string `json:"callback_url"`
+	}{{Id: "slack_bot", CallbackUrl: "http://localhost:8082/outreach"}, {Id: "discord", CallbackUrl: "http://localhost:8081/outreach"}}
+	_, err = store.CreateDelivery(&third)
+	require.NoError(t, err)
+
+	deliveries, err = store.ListDeliveries(outreach.DeliveryFilter{ClientID: "slack_bot"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "daily-digest-3", deliveries[0].Response.IdempotencyId)
+
+	deliveries, err = store.ListDeliveries(outreach.DeliveryFilter{ClientID: "discord", Offset: 1})
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 2)
+
+	// Client IDs are matched whole, without wildcards
+	for _, clientID := range []string{"slackXbot", "slack", "%"} {
+		deliveries, err = store.ListDeliveries(outreach.DeliveryFilter{ClientID: clientID})
+		require.NoError(t, err)
+		assert.Empty(t, deliveries, clientID)
+	}
+}
+
+func TestStoreMigrateResponseClients(t *testing.T) {
+	store := storetest.New(t, NewStore)
+
+	// Responses saved before their client IDs had a column
+	response := &outreach.Response{
+		Key:           "daily-digest",
+		IdempotencyId: "daily-digest-1",
+		Clients: []struct {
+			Id          string `json:"id"`
+			CallbackUrl string `json:"callback_url"`
+		}{{Id: "discord", CallbackUrl: "http://localhost:8081/outreach"}},
+	}
+	model := &ResponseModel{IdempotencyID: response.IdempotencyId, Key: response.Key, Data: ResponseData{response}}
+	require.NoError(t, store.db.Omit("client_ids").Create(model).Error)
+	require.NoError(t, store.db.Create(&DeliveryModel{ResponseID: model.ID, Status: outreach.DeliveryDelivered}).Error)
+	require.NoError(t, store.migrate())
+
+	deliveries, err := store.ListDeliveries(outreach.DeliveryFilter{ClientID: "discord"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "daily-digest-1", deliveries[0].Response.IdempotencyId)
+}
@@ -2,22 +2,24 @@ package outreach
 
 import (
 	"fmt"
+	"log"
+	"time"
 
+	"github.com/ethanbaker/assistant/internal/stores/database"
 	"github.com/ethanbaker/assistant/pkg/outreach"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
-// Store handles storage and retrieval of outreach implementations using MySQL
+// Store handles storage and retrieval of outreach implementations using a SQL database
 type Store struct {
 	db *gorm.DB
 }
 
-// NewStore creates a new outreach store with MySQL connection
+// NewStore creates a new outreach store connected to the database URL (see database.ParseURL)
 func NewStore(databaseURL string) (*Store, error) {
-	db, err := gorm.Open(mysql.Open(databaseURL), &gorm.Config{})
+	db, err := database.Open(databaseURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
 	store := &Store{db: db}
@@ -32,10 +34,53 @@ func NewStore(databaseURL string) (*Store, error) {
 
 // migrate creates or updates the required database tables
 func (s *Store) migrate() error {
-	return s.db.AutoMigrate(&ImplementationModel{})
+	if err := s.db.AutoMigrate(&ImplementationModel{}, &ResponseModel{}, &DeliveryModel{}, &DeliveryAttemptModel{}, &DeadLetterModel{}, &TaskModel{}); err != nil {
+		return err
+	}
+
+	if err := s.migrateResponseClients(); err != nil {
+		return err
+	}
+
+	return s.migrateSecrets()
 }
 
-// SaveImplementation stores an implementation by client ID
+// migrateSecrets hashes any client secrets still stored in plaintext
+func (s *Store) migrateSecrets() error {
+	var models []ImplementationModel
+	if err := s.db.Unscoped().Where("client_secret <> ?", "").Find(&models).Error; err != nil {
+		return fmt.Errorf("failed to find plaintext client secrets: %w", err)
+	}
+
+	for _, model := range models {
+		if outreach.IsHashedSecret(model.ClientSecret) {
+			// Already hashed by hand; requests can't be signed until the client registers again
+			model.SecretHash = model.ClientSecret
+			log.Printf("[OUTREACH]: Warning, client secret for '%s' was already hashed, register it again to sign outreach requests", model.ClientID)
+		} else {
+			creds, err := outreach.NewSecretCredentials(model.ClientSecret)
+			if err != nil {
+				return fmt.Errorf("failed to hash client secret for '%s': %w", model.ClientID, err)
+			}
+			model.setCredentials(creds)
+		}
+
+		model.ClientSecret = ""
+		if err := s.db.Unscoped().Save(&model).Error; err != nil {
+			return fmt.Errorf("failed to save hashed client secret for '%s': %w", model.ClientID, err)
+		}
+	}
+
+	if len(models) > 0 {
+		log.Printf("[OUTREACH]: Hashed %d plaintext client secrets", len(models))
+	}
+
+	return nil
+}
+
+// SaveImplementation stores an implementation by client ID. The client secret is hashed when the
+// implementation is created; the secret of an existing implementation is only changed by RotateSecret.
+// Saving with a client secret always issues a new signing key.
 func (s *Store) SaveImplementation(impl *outreach.Implementation) error {
 	if impl.ClientID == "" {
 		return fmt.Errorf("client_id cannot be empty")
@@ -44,32 +89,41 @@ func (s *Store) SaveImplementation(impl *outreach.Implementation) error {
 		return fmt.Errorf("callback_url cannot be empty")
 	}
 
-	model := &ImplementationModel{
-		ClientID:     impl.ClientID,
-		CallbackURL:  impl.CallbackURL,
-		ClientSecret: impl.ClientSecret,
-		Active:       impl.Active,
-	}
-
 	// Check if implementation already exists
 	var existing ImplementationModel
 	result := s.db.Where("client_id = ?", impl.ClientID).First(&existing)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			// Create new record
-			if err := s.db.Create(model).Error; err != nil {
-				return fmt.Errorf("failed to create implementation: %w", err)
-			}
-		} else {
-			return fmt.Errorf("failed to check existing implementation: %w", result.Error)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return fmt.Errorf("failed to check existing implementation: %w", result.Error)
+	}
+
+	exists := result.Error == nil
+	existing.ClientID = impl.ClientID
+	existing.CallbackURL = impl.CallbackURL
+	existing.Active = impl.Active
+
+	// Hash the secret if the implementation doesn't have one yet, otherwise registering again issues a new signing key
+	if existing.SecretHash == "" && impl.ClientSecret != "" {
+		creds, err := outreach.NewSecretCredentials(impl.ClientSecret)
+		if err != nil {
+			return err
+		}
+		existing.setCredentials(creds)
+	} else if impl.ClientSecret != "" {
+		signingKey, err := outreach.NewSigningKey()
+		if err != nil {
+			return err
+		}
+		existing.SigningKey = signingKey
+	}
+
+	if !exists {
+		// Create new record
+		if err := s.db.Create(&existing).Error; err != nil {
+			return fmt.Errorf("failed to create implementation: %w", err)
 		}
 	} else {
 		// Update existing record
-		if err := s.db.Model(&existing).Updates(map[string]any{
-			"callback_url":  impl.CallbackURL,
-			"client_secret": impl.ClientSecret,
-			"active":        impl.Active,
-		}).Error; err != nil {
+		if err := s.db.Save(&existing).Error; err != nil {
 			return fmt.Errorf("failed to update implementation: %w", err)
 		}
 	}
@@ -92,14 +146,7 @@ func (s *Store) GetImplementation(clientID string) (*outreach.Implementation, er
 		return nil, fmt.Errorf("failed to get implementation: %w", result.Error)
 	}
 
-	impl := &outreach.Implementation{
-		ClientID:     model.ClientID,
-		CallbackURL:  model.CallbackURL,
-		ClientSecret: model.ClientSecret,
-		Active:       model.Active,
-	}
-
-	return impl, nil
+	return model.toImplementation(), nil
 }
 
 // DisableImplementation removes an implementation by client ID
@@ -120,22 +167,17 @@ func (s *Store) DisableImplementation(clientID string) error {
 	return nil
 }
 
-// ListImplementations returns all registered implementations
+// ListImplementations returns all active implementations
 func (s *Store) ListImplementations() []*outreach.Implementation {
 	var models []ImplementationModel
-	if err := s.db.Order("client_id").Find(&models).Where("active = ?", true).Error; err != nil {
+	if err := s.db.Where("active = ?", true).Order("client_id").Find(&models).Error; err != nil {
 		// Return empty slice on error rather than nil to maintain interface contract
 		return []*outreach.Implementation{}
 	}
 
 	implementations := make([]*outreach.Implementation, len(models))
 	for i, model := range models {
-		implementations[i] = &outreach.Implementation{
-			ClientID:     model.ClientID,
-			CallbackURL:  model.CallbackURL,
-			ClientSecret: model.ClientSecret,
-			Active:       model.Active,
-		}
+		implementations[i] = model.toImplementation()
 	}
 
 	return implementations
@@ -161,7 +203,8 @@ func (s *Store) Close() error {
 	return sqlDB.Close()
 }
 
-// AuthenticateImplementation validates client ID and secret combination
+// AuthenticateImplementation validates client ID and secret combination.
+// A rotated secret is accepted until its grace period ends.
 func (s *Store) AuthenticateImplementation(clientID, clientSecret string) (*outreach.Implementation, error) {
 	if clientID == "" {
 		return nil, fmt.Errorf("client_id cannot be empty")
@@ -171,19 +214,42 @@ func (s *Store) AuthenticateImplementation(clientID, clientSecret string) (*outr
 	}
 
 	var model ImplementationModel
-	result := s.db.Where("client_id = ? AND client_secret = ?", clientID, clientSecret).First(&model)
+	result := s.db.Where("client_id = ?", clientID).First(&model)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to authenticate implementation: %w", result.Error)
+	}
 
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("invalid client credentials")
+	// Unknown clients are still compared so they take as long to reject
+	if !model.toImplementation().Credentials.SecretMatches(clientSecret, time.Now()) {
+		return nil, fmt.Errorf("invalid client credentials")
+	}
+
+	return model.toImplementation(), nil
+}
+
+// RotateSecret replaces an implementation's client secret. The old secret is accepted until the grace period ends.
+func (s *Store) RotateSecret(clientID, newSecret string, gracePeriod time.Duration) (*outreach.Implementation, error) {
+	if clientID == "" {
+		return nil, fmt.Errorf("client_id cannot be empty")
+	}
+
+	var model ImplementationModel
+	if err := s.db.Where("client_id = ?", clientID).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("implementation with client_id '%s' not found", clientID)
 		}
-		return nil, fmt.Errorf("failed to authenticate implementation: %w", result.Error)
+		return nil, fmt.Errorf("failed to get implementation: %w", err)
+	}
+
+	impl := model.toImplementation()
+	if err := impl.Credentials.Rotate(newSecret, gracePeriod, time.Now()); err != nil {
+		return nil, err
 	}
 
-	return &outreach.Implementation{
-		ClientID:     model.ClientID,
-		CallbackURL:  model.CallbackURL,
-		ClientSecret: model.ClientSecret,
-		Active:       model.Active,
-	}, nil
+	model.setCredentials(impl.Credentials)
+	if err := s.db.Save(&model).Error; err != nil {
+		return nil, fmt.Errorf("failed to rotate client secret: %w", err)
+	}
+
+	return impl, nil
 }
@@ -0,0 +1,147 @@
+package outreach
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/storetest"
+	"github.com/ethanbaker/assistant/pkg/outreach"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStores returns each store implementation to run shared tests against
+func testStores(t *testing.T) map[string]outreach.StoreInterface {
+	return map[string]outreach.StoreInterface{
+		"memory": NewInMemoryStore(),
+		"sql":    storetest.New(t, NewStore),
+	}
+}
+
+func TestStoreImplementations(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			testStoreImplementations(t, store)
+		})
+	}
+}
+
+func testStoreImplementations(t *testing.T, store outreach.StoreInterface) {
+	require.NoError(t, store.SaveImplementation(&outreach.Implementation{
+		ClientID:     "discord",
+		CallbackURL:  "http://localhost:8081/outreach",
+		ClientSecret: "secret",
+		Active:       true,
+	}))
+	assert.True(t, store.Exists("discord"))
+	assert.False(t, store.Exists("slack"))
+
+	// The secret is never returned, only its hash and signing key
+	impl, err := store.GetImplementation("discord")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8081/outreach", impl.CallbackURL)
+	assert.True(t, impl.Active)
+	assert.Empty(t, impl.ClientSecret)
+	assert.NotEqual(t, "secret", impl.Credentials.SecretHash)
+	assert.Len(t, impl.Credentials.SigningKey, outreach.SIGNING_KEY_LENGTH)
+	signingKey := impl.Credentials.SigningKey
+
+	// Saving again updates the existing implementation and issues a new signing key, but keeps its secret
+	require.NoError(t, store.SaveImplementation(&outreach.Implementation{
+		ClientID:     "discord",
+		CallbackURL:  "http://localhost:9000/outreach",
+		ClientSecret: "other",
+		Active:       true,
+	}))
+	impl, err = store.GetImplementation("discord")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:9000/outreach", impl.CallbackURL)
+	assert.Len(t, impl.Credentials.SigningKey, outreach.SIGNING_KEY_LENGTH)
+	assert.NotEqual(t, signingKey, impl.Credentials.SigningKey)
+
+	// Only the right secret authenticates
+	_, err = store.AuthenticateImplementation("discord", "secret")
+	assert.NoError(t, err)
+	_, err = store.AuthenticateImplementation("discord", "other")
+	assert.Error(t, err)
+	_, err = store.AuthenticateImplementation("slack", "secret")
+	assert.Error(t, err)
+
+	// Disabled implementations no longer exist or get listed
+	require.NoError(t, store.DisableImplementation("discord"))
+	assert.False(t, store.Exists("discord"))
+	assert.Empty(t, store.ListImplementations())
+	assert.Error(t, store.DisableImplementation("slack"))
+}
+
+func TestStoreRotateSecret(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			testStoreRotateSecret(t, store)
+		})
+	}
+}
+
+func testStoreRotateSecret(t *testing.T, store outreach.StoreInterface) {
+	require.NoError(t, store.SaveImplementation(&outreach.Implementation{
+		ClientID:     "discord",
+		CallbackURL:  "http://localhost:8081/outreach",
+		ClientSecret: "old",
+		Active:       true,
+	}))
+
+	impl, err := store.GetImplementation("discord")
+	require.NoError(t, err)
+	oldKey := impl.Credentials.SigningKey
+
+	_, err = store.RotateSecret("slack", "new", time.Hour)
+	assert.Error(t, err)
+
+	// Both secrets work during the grace period, and requests are signed with both keys
+	impl, err = store.RotateSecret("discord", "new", time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, impl.Credentials.PreviousSecretExpiresAt)
+	newKey := impl.Credentials.SigningKey
+	assert.Len(t, newKey, outreach.SIGNING_KEY_LENGTH)
+	assert.NotEqual(t, oldKey, newKey)
+
+	_, err = store.AuthenticateImplementation("discord", "new")
+	assert.NoError(t, err)
+	_, err = store.AuthenticateImplementation("discord", "old")
+	assert.NoError(t, err)
+
+	impl, err = store.GetImplementation("discord")
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{newKey, oldKey}, impl.Credentials.SigningKeys(time.Now()))
+
+	// Without a grace period the old secret stops working straight away
+	_, err = store.RotateSecret("discord", "newest", 0)
+	require.NoError(t, err)
+
+	_, err = store.AuthenticateImplementation("discord", "newest")
+	assert.NoError(t, err)
+	_, err = store.AuthenticateImplementation("discord", "new")
+	assert.Error(t, err)
+}
+
+func TestStoreMigrateSecrets(t *testing.T) {
+	store := storetest.New(t, NewStore)
+
+	// Rows saved before secrets were hashed
+	require.NoError(t, store.db.Create(&ImplementationModel{
+		ClientID:     "discord",
+		CallbackURL:  "http://localhost:8081/outreach",
+		ClientSecret: "secret",
+		Active:       true,
+	}).Error)
+	require.NoError(t, store.migrate())
+
+	var model ImplementationModel
+	require.NoError(t, store.db.Where("client_id = ?", "discord").First(&model).Error)
+	assert.Empty(t, model.ClientSecret)
+	assert.True(t, outreach.IsHashedSecret(model.SecretHash))
+	assert.Len(t, model.SigningKey, outreach.SIGNING_KEY_LENGTH)
+
+	_, err := store.AuthenticateImplementation("discord", "secret")
+	assert.NoError(t, err)
+}
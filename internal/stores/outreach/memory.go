@@ -2,7 +2,10 @@ package outreach
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/ethanbaker/assistant/pkg/outreach"
 )
@@ -11,6 +14,14 @@ import (
 type InMemoryStore struct {
 	implementations map[string]*outreach.Implementation
 	mutex           sync.RWMutex
+
+	// Deliveries
+	deliveries  []*outreach.Delivery
+	attempts    []*outreach.DeliveryAttempt
+	deadLetters []*outreach.DeadLetter
+
+	// Tasks
+	tasks map[string]*outreach.Task
 }
 
 // NewInMemoryStore creates a new in-memory outreach store
@@ -18,10 +29,16 @@ func NewInMemoryStore() *InMemoryStore {
 	return &InMemoryStore{
 		implementations: make(map[string]*outreach.Implementation),
 		mutex:           sync.RWMutex{},
+		deliveries:      []*outreach.Delivery{},
+		attempts:        []*outreach.DeliveryAttempt{},
+		deadLetters:     []*outreach.DeadLetter{},
+		tasks:           make(map[string]*outreach.Task),
 	}
 }
 
-// SaveImplementation stores an implementation by client ID
+// SaveImplementation stores an implementation by client ID. The client secret is hashed when the
+// implementation is created; the secret of an existing implementation is only changed by RotateSecret.
+// Saving with a client secret always issues a new signing key.
 func (s *InMemoryStore) SaveImplementation(impl *outreach.Implementation) error {
 	if impl.ClientID == "" {
 		return fmt.Errorf("client_id cannot be empty")
@@ -33,11 +50,27 @@ func (s *InMemoryStore) SaveImplementation(impl *outreach.Implementation) error
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// Create a copy to avoid shared references
+	// Create a copy to avoid shared references, keeping the existing credentials
 	implCopy := &outreach.Implementation{
-		ClientID:     impl.ClientID,
-		CallbackURL:  impl.CallbackURL,
-		ClientSecret: impl.ClientSecret,
+		ClientID:    impl.ClientID,
+		CallbackURL: impl.CallbackURL,
+		Active:      impl.Active,
+	}
+	if existing, exists := s.implementations[impl.ClientID]; exists {
+		implCopy.Credentials = existing.Credentials
+	}
+
+	// Hash the secret if the implementation doesn't have one yet, otherwise registering again issues a new signing key
+	if implCopy.Credentials.SecretHash == "" && impl.ClientSecret != "" {
+		creds, err := outreach.NewSecretCredentials(impl.ClientSecret)
+		if err != nil {
+			return err
+		}
+		implCopy.Credentials = creds
+	} else if impl.ClientSecret != "" {
+		if err := implCopy.Credentials.ResetSigningKey(); err != nil {
+			return err
+		}
 	}
 
 	s.implementations[impl.ClientID] = implCopy
@@ -59,11 +92,7 @@ func (s *InMemoryStore) GetImplementation(clientID string) (*outreach.Implementa
 	}
 
 	// Return a copy to avoid external mutations
-	return &outreach.Implementation{
-		ClientID:     impl.ClientID,
-		CallbackURL:  impl.CallbackURL,
-		ClientSecret: impl.ClientSecret,
-	}, nil
+	return copyImplementation(impl), nil
 }
 
 // DisableImplementation removes an implementation by client ID
@@ -83,25 +112,30 @@ func (s *InMemoryStore) DisableImplementation(clientID string) error {
 	return nil
 }
 
-// ListImplementations returns all registered implementations
+// ListImplementations returns all active implementations
 func (s *InMemoryStore) ListImplementations() []*outreach.Implementation {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	implementations := make([]*outreach.Implementation, 0, len(s.implementations))
 	for _, impl := range s.implementations {
+		if !impl.Active {
+			continue
+		}
+
 		// Create copies to avoid external mutations
-		implementations = append(implementations, &outreach.Implementation{
-			ClientID:     impl.ClientID,
-			CallbackURL:  impl.CallbackURL,
-			ClientSecret: impl.ClientSecret,
-		})
+		implementations = append(implementations, copyImplementation(impl))
 	}
 
+	// Match the SQL store's ordering
+	sort.Slice(implementations, func(i, j int) bool {
+		return implementations[i].ClientID < implementations[j].ClientID
+	})
+
 	return implementations
 }
 
-// Exists checks if an implementation with the given client ID exists
+// Exists checks if an active implementation with the given client ID exists
 func (s *InMemoryStore) Exists(clientID string) bool {
 	if clientID == "" {
 		return false
@@ -110,11 +144,12 @@ func (s *InMemoryStore) Exists(clientID string) bool {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	_, exists := s.implementations[clientID]
-	return exists
+	impl, exists := s.implementations[clientID]
+	return exists && impl.Active
 }
 
-// AuthenticateImplementation validates client ID and secret combination
+// AuthenticateImplementation validates client ID and secret combination.
+// A rotated secret is accepted until its grace period ends.
 func (s *InMemoryStore) AuthenticateImplementation(clientID, clientSecret string) (*outreach.Implementation, error) {
 	if clientID == "" {
 		return nil, fmt.Errorf("client_id cannot be empty")
@@ -124,21 +159,316 @@ func (s *InMemoryStore) AuthenticateImplementation(clientID, clientSecret string
 	}
 
 	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+	impl, exists := s.implementations[clientID]
+	if exists {
+		impl = copyImplementation(impl)
+	} else {
+		// Unknown clients are still compared so they take as long to reject
+		impl = &outreach.Implementation{}
+	}
+	s.mutex.RUnlock()
+
+	if !impl.Credentials.SecretMatches(clientSecret, time.Now()) {
+		return nil, fmt.Errorf("invalid client credentials")
+	}
+
+	return impl, nil
+}
+
+// RotateSecret replaces an implementation's client secret. The old secret is accepted until the grace period ends.
+func (s *InMemoryStore) RotateSecret(clientID, newSecret string, gracePeriod time.Duration) (*outreach.Implementation, error) {
+	if clientID == "" {
+		return nil, fmt.Errorf("client_id cannot be empty")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
 	impl, exists := s.implementations[clientID]
 	if !exists {
-		return nil, fmt.Errorf("invalid client credentials")
+		return nil, fmt.Errorf("implementation with client_id '%s' not found", clientID)
 	}
 
-	if impl.ClientSecret != clientSecret {
-		return nil, fmt.Errorf("invalid client credentials")
+	if err := impl.Credentials.Rotate(newSecret, gracePeriod, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return copyImplementation(impl), nil
+}
+
+// CreateDelivery stores a response and a pending delivery for it
+func (s *InMemoryStore) CreateDelivery(response *outreach.Response) (*outreach.Delivery, error) {
+	if response == nil {
+		return nil, fmt.Errorf("response cannot be nil")
+	}
+	if response.IdempotencyId == "" {
+		return nil, fmt.Errorf("idempotency_id cannot be empty")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Responses are unique by idempotency ID, like the SQL store
+	for _, delivery := range s.deliveries {
+		if delivery.Response.IdempotencyId == response.IdempotencyId {
+			return nil, fmt.Errorf("failed to create delivery: response '%s' already exists", response.IdempotencyId)
+		}
+	}
+
+	now := time.Now()
+	delivery := &outreach.Delivery{
+		ID:            uint(len(s.deliveries) + 1),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Response:      response,
+		Status:        outreach.DeliveryPending,
+		NextAttemptAt: &now,
+	}
+	s.deliveries = append(s.deliveries, delivery)
+
+	return copyDelivery(delivery), nil
+}
+
+// GetDelivery retrieves a delivery and its response by ID
+func (s *InMemoryStore) GetDelivery(id uint) (*outreach.Delivery, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	delivery, err := s.findDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyDelivery(delivery), nil
+}
+
+// ListDeliveries returns deliveries matching the filter, newest first
+func (s *InMemoryStore) ListDeliveries(filter outreach.DeliveryFilter) ([]*outreach.Delivery, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	deliveries := []*outreach.Delivery{}
+	skipped := 0
+	for i := len(s.deliveries) - 1; i >= 0; i-- {
+		delivery := s.deliveries[i]
+		if filter.Status != "" && delivery.Status != filter.Status {
+			continue
+		}
+		if filter.ClientID != "" && !slices.Contains(delivery.Response.ClientIds(), filter.ClientID) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		if filter.Limit > 0 && len(deliveries) >= filter.Limit {
+			break
+		}
+
+		deliveries = append(deliveries, copyDelivery(delivery))
+	}
+
+	return deliveries, nil
+}
+
+// UpdateDelivery saves the state of a delivery
+func (s *InMemoryStore) UpdateDelivery(delivery *outreach.Delivery) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing, err := s.findDelivery(delivery.ID)
+	if err != nil {
+		return err
+	}
+
+	existing.UpdatedAt = time.Now()
+	existing.Status = delivery.Status
+	existing.Rounds = delivery.Rounds
+	existing.Attempts = delivery.Attempts
+	existing.NextAttemptAt = delivery.NextAttemptAt
+	existing.DeliveredAt = delivery.DeliveredAt
+	existing.DeliveredTo = delivery.DeliveredTo
+	existing.LastError = delivery.LastError
+
+	return nil
+}
+
+// RecordDeliveryAttempt stores a single delivery attempt
+func (s *InMemoryStore) RecordDeliveryAttempt(attempt *outreach.DeliveryAttempt) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	attempt.ID = uint(len(s.attempts) + 1)
+	attempt.CreatedAt = time.Now()
+
+	attemptCopy := *attempt
+	s.attempts = append(s.attempts, &attemptCopy)
+	return nil
+}
+
+// ListDeliveryAttempts returns the attempts made for a delivery, oldest first
+func (s *InMemoryStore) ListDeliveryAttempts(deliveryID uint) ([]*outreach.DeliveryAttempt, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	attempts := []*outreach.DeliveryAttempt{}
+	for _, attempt := range s.attempts {
+		if attempt.DeliveryID == deliveryID {
+			attemptCopy := *attempt
+			attempts = append(attempts, &attemptCopy)
+		}
+	}
+
+	return attempts, nil
+}
+
+// DeadLetterDelivery marks a delivery as dead and moves it to the dead-letter list
+func (s *InMemoryStore) DeadLetterDelivery(id uint, reason string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delivery, err := s.findDelivery(id)
+	if err != nil {
+		return err
 	}
 
-	// Return a copy to avoid shared references
-	return &outreach.Implementation{
-		ClientID:     impl.ClientID,
-		CallbackURL:  impl.CallbackURL,
-		ClientSecret: impl.ClientSecret,
-	}, nil
+	delivery.UpdatedAt = time.Now()
+	delivery.Status = outreach.DeliveryDead
+	delivery.NextAttemptAt = nil
+	delivery.LastError = reason
+
+	s.deadLetters = append(s.deadLetters, &outreach.DeadLetter{
+		ID:         uint(len(s.deadLetters) + 1),
+		CreatedAt:  time.Now(),
+		DeliveryID: id,
+		Reason:     reason,
+	})
+
+	return nil
+}
+
+// ListDeadLetters returns the dead letters recorded for a delivery, oldest first
+func (s *InMemoryStore) ListDeadLetters(deliveryID uint) ([]*outreach.DeadLetter, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	letters := []*outreach.DeadLetter{}
+	for _, letter := range s.deadLetters {
+		if letter.DeliveryID == deliveryID {
+			letterCopy := *letter
+			letters = append(letters, &letterCopy)
+		}
+	}
+
+	return letters, nil
+}
+
+// ReplayDelivery queues a finished delivery to be sent again, starting a fresh set of rounds
+func (s *InMemoryStore) ReplayDelivery(id uint) (*outreach.Delivery, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delivery, err := s.findDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery.Status == outreach.DeliveryPending || delivery.Status == outreach.DeliveryRetrying {
+		return nil, fmt.Errorf("delivery %d: %w", id, outreach.ErrDeliveryInProgress)
+	}
+
+	now := time.Now()
+	delivery.UpdatedAt = now
+	delivery.Status = outreach.DeliveryPending
+	delivery.Rounds = 0
+	delivery.NextAttemptAt = &now
+
+	for _, letter := range s.deadLetters {
+		if letter.DeliveryID == id && letter.ReplayedAt == nil {
+			letter.ReplayedAt = &now
+		}
+	}
+
+	return copyDelivery(delivery), nil
+}
+
+// SaveTask stores a task by key, replacing any task with the same key
+func (s *InMemoryStore) SaveTask(task *outreach.Task) error {
+	if task.Key == "" {
+		return fmt.Errorf("task key cannot be empty")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Store a copy without the run function, like the SQL store
+	taskCopy := task.Clone()
+	taskCopy.Run = nil
+
+	s.tasks[task.Key] = taskCopy
+	return nil
+}
+
+// GetTask retrieves a task by key
+func (s *InMemoryStore) GetTask(key string) (*outreach.Task, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	task, exists := s.tasks[key]
+	if !exists {
+		return nil, fmt.Errorf("task '%s': %w", key, outreach.ErrTaskNotFound)
+	}
+
+	return task.Clone(), nil
+}
+
+// ListTasks returns all stored tasks ordered by key
+func (s *InMemoryStore) ListTasks() ([]*outreach.Task, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	tasks := make([]*outreach.Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, task.Clone())
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Key < tasks[j].Key
+	})
+
+	return tasks, nil
+}
+
+// DeleteTask removes a task by key
+func (s *InMemoryStore) DeleteTask(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.tasks[key]; !exists {
+		return fmt.Errorf("task '%s': %w", key, outreach.ErrTaskNotFound)
+	}
+
+	delete(s.tasks, key)
+	return nil
+}
+
+// findDelivery looks up a delivery by ID (called with mutex held)
+func (s *InMemoryStore) findDelivery(id uint) (*outreach.Delivery, error) {
+	if id == 0 || int(id) > len(s.deliveries) {
+		return nil, fmt.Errorf("delivery %d: %w", id, outreach.ErrDeliveryNotFound)
+	}
+
+	return s.deliveries[id-1], nil
+}
+
+// Helper function to copy a delivery so callers can't mutate the stored one
+func copyDelivery(delivery *outreach.Delivery) *outreach.Delivery {
+	deliveryCopy := *delivery
+	return &deliveryCopy
+}
+
+// Helper function to copy an implementation so callers can't mutate the stored one
+func copyImplementation(impl *outreach.Implementation) *outreach.Implementation {
+	implCopy := *impl
+	return &implCopy
 }
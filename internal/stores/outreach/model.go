@@ -1,8 +1,12 @@
 package outreach
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
+	"github.com/ethanbaker/assistant/pkg/outreach"
 	"gorm.io/gorm"
 )
 
@@ -15,11 +19,232 @@ type ImplementationModel struct {
 
 	ClientID     string `json:"client_id" gorm:"column:client_id;unique;not null;size:255"`
 	CallbackURL  string `json:"callback_url" gorm:"column:callback_url;not null;size:500"`
-	ClientSecret string `json:"client_secret" gorm:"column:client_secret;size:255"`
+	ClientSecret string `json:"-" gorm:"column:client_secret;size:255"` // Legacy plaintext secret, hashed and cleared on startup
 	Active       bool   `json:"active" gorm:"column:active;default:true"`
+
+	SecretHash              string     `json:"-" gorm:"column:secret_hash;size:255"`
+	SigningKey              []byte     `json:"-" gorm:"column:signing_key"`
+	PreviousSecretHash      string     `json:"-" gorm:"column:previous_secret_hash;size:255"`
+	PreviousSigningKey      []byte     `json:"-" gorm:"column:previous_signing_key"`
+	PreviousSecretExpiresAt *time.Time `json:"-" gorm:"column:previous_secret_expires_at"`
 }
 
 // TableName sets the table name for GORM
 func (ImplementationModel) TableName() string {
 	return "outreach_implementations"
 }
+
+// toImplementation converts the model to an implementation
+func (m *ImplementationModel) toImplementation() *outreach.Implementation {
+	return &outreach.Implementation{
+		ClientID:    m.ClientID,
+		CallbackURL: m.CallbackURL,
+		Active:      m.Active,
+		Credentials: outreach.SecretCredentials{
+			SecretHash:              m.SecretHash,
+			SigningKey:              m.SigningKey,
+			PreviousSecretHash:      m.PreviousSecretHash,
+			PreviousSigningKey:      m.PreviousSigningKey,
+			PreviousSecretExpiresAt: m.PreviousSecretExpiresAt,
+		},
+	}
+}
+
+// setCredentials copies hashed credentials onto the model
+func (m *ImplementationModel) setCredentials(creds outreach.SecretCredentials) {
+	m.SecretHash = creds.SecretHash
+	m.SigningKey = creds.SigningKey
+	m.PreviousSecretHash = creds.PreviousSecretHash
+	m.PreviousSigningKey = creds.PreviousSigningKey
+	m.PreviousSecretExpiresAt = creds.PreviousSecretExpiresAt
+}
+
+// ResponseData stores an outreach response as JSON in a single column
+type ResponseData struct {
+	*outreach.Response
+}
+
+// Value implements driver.Valuer for storing the response
+func (r ResponseData) Value() (driver.Value, error) {
+	if r.Response == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(r.Response)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner for loading the response
+func (r *ResponseData) Scan(value any) error {
+	if value == nil {
+		r.Response = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ResponseData", value)
+	}
+
+	r.Response = &outreach.Response{}
+	return json.Unmarshal(data, r.Response)
+}
+
+// ResponseModel represents the database model for responses produced by outreach tasks
+type ResponseModel struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+
+	IdempotencyID string       `json:"idempotency_id" gorm:"column:idempotency_id;uniqueIndex;not null;size:255"`
+	Key           string       `json:"key" gorm:"column:task_key;index;size:255"`
+	Data          ResponseData `json:"data" gorm:"column:data;type:text;not null"`
+	ClientIDs     string       `json:"client_ids" gorm:"column:client_ids;type:text"` // Stored as ",a,b," so deliveries can be filtered by client
+}
+
+// TableName sets the table name for GORM
+func (ResponseModel) TableName() string {
+	return "outreach_responses"
+}
+
+// DeliveryModel represents the database model for sending a response to its clients
+type DeliveryModel struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+
+	ResponseID    uint                    `json:"response_id" gorm:"column:response_id;index;not null"`
+	Response      ResponseModel           `json:"response" gorm:"foreignKey:ResponseID"`
+	Status        outreach.DeliveryStatus `json:"status" gorm:"column:status;index;size:32;not null"`
+	Rounds        int                     `json:"rounds" gorm:"column:rounds;not null;default:0"`
+	Attempts      int                     `json:"attempts" gorm:"column:attempts;not null;default:0"`
+	NextAttemptAt *time.Time              `json:"next_attempt_at" gorm:"column:next_attempt_at"`
+	DeliveredAt   *time.Time              `json:"delivered_at" gorm:"column:delivered_at"`
+	DeliveredTo   string                  `json:"delivered_to" gorm:"column:delivered_to;size:255"`
+	LastError     string                  `json:"last_error" gorm:"column:last_error;type:text"`
+}
+
+// TableName sets the table name for GORM
+func (DeliveryModel) TableName() string {
+	return "outreach_deliveries"
+}
+
+// toDelivery converts the model to a delivery
+func (m *DeliveryModel) toDelivery() *outreach.Delivery {
+	return &outreach.Delivery{
+		ID:            m.ID,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+		Response:      m.Response.Data.Response,
+		Status:        m.Status,
+		Rounds:        m.Rounds,
+		Attempts:      m.Attempts,
+		NextAttemptAt: m.NextAttemptAt,
+		DeliveredAt:   m.DeliveredAt,
+		DeliveredTo:   m.DeliveredTo,
+		LastError:     m.LastError,
+	}
+}
+
+// DeliveryAttemptModel represents the database model for a single delivery attempt
+type DeliveryAttemptModel struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+
+	DeliveryID uint   `json:"delivery_id" gorm:"column:delivery_id;index;not null"`
+	ClientID   string `json:"client_id" gorm:"column:client_id;size:255"`
+	StatusCode int    `json:"status_code" gorm:"column:status_code"`
+	Error      string `json:"error" gorm:"column:error;type:text"`
+	LatencyMs  int64  `json:"latency_ms" gorm:"column:latency_ms"`
+}
+
+// TableName sets the table name for GORM
+func (DeliveryAttemptModel) TableName() string {
+	return "outreach_delivery_attempts"
+}
+
+// DeadLetterModel represents the database model for deliveries that ran out of retries
+type DeadLetterModel struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+
+	DeliveryID uint       `json:"delivery_id" gorm:"column:delivery_id;index;not null"`
+	Reason     string     `json:"reason" gorm:"column:reason;type:text"`
+	ReplayedAt *time.Time `json:"replayed_at" gorm:"column:replayed_at"`
+}
+
+// TableName sets the table name for GORM
+func (DeadLetterModel) TableName() string {
+	return "outreach_dead_letters"
+}
+
+// JSONData stores a value as JSON in a single column
+type JSONData[T any] struct {
+	Data T
+}
+
+// Value implements driver.Valuer for storing the value
+func (j JSONData[T]) Value() (driver.Value, error) {
+	data, err := json.Marshal(j.Data)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner for loading the value
+func (j *JSONData[T]) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into JSONData", value)
+	}
+
+	return json.Unmarshal(data, &j.Data)
+}
+
+// TaskModel represents the database model for tasks added through the manager
+type TaskModel struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+
+	Key           string                   `json:"key" gorm:"column:task_key;uniqueIndex;not null;size:255"`
+	Type          string                   `json:"type" gorm:"column:task_type;size:255"`
+	ClientIds     JSONData[[]string]       `json:"client_ids" gorm:"column:client_ids;type:text"`
+	Params        JSONData[map[string]any] `json:"params" gorm:"column:params;type:text"`
+	Cadence       outreach.CadenceType     `json:"cadence" gorm:"column:cadence;size:32;not null"`
+	CadenceParams JSONData[map[string]any] `json:"cadence_params" gorm:"column:cadence_params;type:text"`
+	Paused        bool                     `json:"paused" gorm:"column:paused;not null;default:false"`
+}
+
+// TableName sets the table name for GORM
+func (TaskModel) TableName() string {
+	return "outreach_tasks"
+}
+
+// toTask converts the model to a task
+func (m *TaskModel) toTask() *outreach.Task {
+	return &outreach.Task{
+		Key:           m.Key,
+		Type:          m.Type,
+		ClientIds:     m.ClientIds.Data,
+		Params:        m.Params.Data,
+		Cadence:       m.Cadence,
+		CadenceParams: m.CadenceParams.Data,
+		Paused:        m.Paused,
+	}
+}
@@ -0,0 +1,77 @@
+package outreach
+
+import (
+	"fmt"
+
+	"github.com/ethanbaker/assistant/pkg/outreach"
+	"gorm.io/gorm"
+)
+
+// SaveTask stores a task by key, replacing any task with the same key
+func (s *Store) SaveTask(task *outreach.Task) error {
+	if task.Key == "" {
+		return fmt.Errorf("task key cannot be empty")
+	}
+
+	var model TaskModel
+	result := s.db.Where("task_key = ?", task.Key).First(&model)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return fmt.Errorf("failed to check existing task: %w", result.Error)
+	}
+
+	model.Key = task.Key
+	model.Type = task.Type
+	model.ClientIds = JSONData[[]string]{task.ClientIds}
+	model.Params = JSONData[map[string]any]{task.Params}
+	model.Cadence = task.Cadence
+	model.CadenceParams = JSONData[map[string]any]{task.CadenceParams}
+	model.Paused = task.Paused
+
+	if err := s.db.Save(&model).Error; err != nil {
+		return fmt.Errorf("failed to save task: %w", err)
+	}
+
+	return nil
+}
+
+// GetTask retrieves a task by key
+func (s *Store) GetTask(key string) (*outreach.Task, error) {
+	var model TaskModel
+	if err := s.db.Where("task_key = ?", key).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("task '%s': %w", key, outreach.ErrTaskNotFound)
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	return model.toTask(), nil
+}
+
+// ListTasks returns all stored tasks ordered by key
+func (s *Store) ListTasks() ([]*outreach.Task, error) {
+	var models []TaskModel
+	if err := s.db.Order("task_key").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	tasks := make([]*outreach.Task, len(models))
+	for i := range models {
+		tasks[i] = models[i].toTask()
+	}
+
+	return tasks, nil
+}
+
+// DeleteTask removes a task by key
+func (s *Store) DeleteTask(key string) error {
+	result := s.db.Where("task_key = ?", key).Delete(&TaskModel{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete task: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("task '%s': %w", key, outreach.ErrTaskNotFound)
+	}
+
+	return nil
+}
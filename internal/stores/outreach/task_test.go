@@ -0,0 +1,53 @@
+package outreach
+
+import (
+	"testing"
+
+	"github.com/ethanbaker/assistant/pkg/outreach"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreTasks(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			testStoreTasks(t, store)
+		})
+	}
+}
+
+func testStoreTasks(t *testing.T, store outreach.StoreInterface) {
+	task := &outreach.Task{
+		Key:           "morning-digest",
+		Type:          "daily-digest",
+		ClientIds:     []string{"discord"},
+		Params:        map[string]any{"topic": "news"},
+		Cadence:       outreach.CronCadence,
+		CadenceParams: map[string]any{"spec": "0 8 * * *"},
+	}
+	require.NoError(t, store.SaveTask(task))
+
+	stored, err := store.GetTask("morning-digest")
+	require.NoError(t, err)
+	assert.Equal(t, "daily-digest", stored.Type)
+	assert.Equal(t, []string{"discord"}, stored.ClientIds)
+	assert.Equal(t, "news", stored.Params["topic"])
+	assert.Equal(t, "0 8 * * *", stored.CadenceParams["spec"])
+	assert.False(t, stored.Paused)
+
+	// Saving again replaces the task
+	task.Paused = true
+	require.NoError(t, store.SaveTask(task))
+	require.NoError(t, store.SaveTask(&outreach.Task{Key: "evening", Cadence: outreach.SunsetCadence}))
+
+	tasks, err := store.ListTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "evening", tasks[0].Key)
+	assert.True(t, tasks[1].Paused)
+
+	require.NoError(t, store.DeleteTask("evening"))
+	assert.ErrorIs(t, store.DeleteTask("evening"), outreach.ErrTaskNotFound)
+	_, err = store.GetTask("evening")
+	assert.ErrorIs(t, err, outreach.ErrTaskNotFound)
+}
@@ -0,0 +1,88 @@
+package run
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Record is a structured record of one agent run: the agents it passed through, the tools they called and the
+// tokens it used
+type Record struct {
+	ID        string    `json:"id" gorm:"type:char(36);primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;index"`
+
+	SessionID string `json:"session_id" gorm:"column:session_id;size:36;index"`       // Session the run was for
+	UserID    string `json:"user_id" gorm:"column:user_id;size:255;index;default:''"` // User that owns the session
+	Stream    bool   `json:"stream" gorm:"column:stream"`                             // Whether the run was streamed
+
+	AgentChain Chain  `json:"agent_chain" gorm:"column:agent_chain;type:text"` // Agents in the order they were handed off to
+	Model      string `json:"model" gorm:"column:model;size:255"`              // Models used, comma separated if there were several
+
+	Requests     uint64 `json:"requests" gorm:"column:requests"`           // Number of model calls
+	InputTokens  uint64 `json:"input_tokens" gorm:"column:input_tokens"`   // Tokens sent to the model
+	OutputTokens uint64 `json:"output_tokens" gorm:"column:output_tokens"` // Tokens received from the model
+	TotalTokens  uint64 `json:"total_tokens" gorm:"column:total_tokens"`   // Tokens sent and received
+
+	Duration time.Duration `json:"duration" gorm:"column:duration"`               // Time from the start of the run to its end
+	Error    string        `json:"error,omitempty" gorm:"column:error;type:text"` // Why the run failed, if it did
+
+	ToolCalls []ToolCall `json:"tool_calls" gorm:"foreignKey:RunID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName sets the table name for GORM
+func (Record) TableName() string {
+	return "agent_runs"
+}
+
+// ToolCall is a single call to a function tool made during a run
+type ToolCall struct {
+	ID    uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	RunID string `json:"run_id" gorm:"column:run_id;type:char(36);not null;index"`
+
+	Sequence  int           `json:"sequence" gorm:"column:sequence"`               // Position of the call in the run, in the order calls finished
+	AgentID   string        `json:"agent_id" gorm:"column:agent_id;size:255"`      // Agent that called the tool
+	Tool      string        `json:"tool" gorm:"column:tool;size:255;not null"`     // Name of the tool
+	Arguments string        `json:"arguments" gorm:"column:arguments;type:text"`   // JSON arguments passed to the tool
+	Result    string        `json:"result" gorm:"column:result;type:text"`         // Output of the tool
+	Error     string        `json:"error,omitempty" gorm:"column:error;type:text"` // Error returned by the tool, if any
+	StartedAt time.Time     `json:"started_at" gorm:"column:started_at"`           // When the tool was called
+	Duration  time.Duration `json:"duration" gorm:"column:duration"`               // How long the tool took
+}
+
+// TableName sets the table name for GORM
+func (ToolCall) TableName() string {
+	return "agent_run_tool_calls"
+}
+
+// Chain is a list of agent IDs, stored as a comma separated string
+type Chain []string
+
+// Value implements the driver.Valuer interface for database storage
+func (c Chain) Value() (driver.Value, error) {
+	return strings.Join(c, ","), nil
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (c *Chain) Scan(value any) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		raw = ""
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("cannot scan %T into Chain", value)
+	}
+
+	*c = Chain{}
+	for _, agentID := range strings.Split(raw, ",") {
+		if agentID != "" {
+			*c = append(*c, agentID)
+		}
+	}
+	return nil
+}
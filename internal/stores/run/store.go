@@ -0,0 +1,96 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethanbaker/assistant/internal/stores/database"
+	"gorm.io/gorm"
+)
+
+// ErrRunNotFound is returned when a run doesn't exist
+var ErrRunNotFound = errors.New("run not found")
+
+// Store handles run record persistence using GORM
+type Store struct {
+	db *gorm.DB
+}
+
+// NewStore creates a new run store connected to the database URL (see database.ParseURL)
+func NewStore(databaseURL string) (*Store, error) {
+	db, err := database.Open(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{db: db}
+
+	// Auto-migrate tables
+	if err := store.db.AutoMigrate(&Record{}, &ToolCall{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate tables: %w", err)
+	}
+
+	return store, nil
+}
+
+// CreateRun stores a run along with its tool calls
+func (s *Store) CreateRun(ctx context.Context, run *Record) error {
+	if run.ID == "" {
+		return errors.New("run ID is required")
+	}
+
+	if err := s.db.WithContext(ctx).Create(run).Error; err != nil {
+		return fmt.Errorf("failed to create run: %w", err)
+	}
+	return nil
+}
+
+// GetRun returns a run with its tool calls
+func (s *Store) GetRun(ctx context.Context, id string) (*Record, error) {
+	var run Record
+	if err := s.withToolCalls(ctx).Where("id = ?", id).First(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("run %s: %w", id, ErrRunNotFound)
+		}
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// ListSessionRuns returns the runs of a session with their tool calls, newest first. A limit of 0 returns every run
+func (s *Store) ListSessionRuns(ctx context.Context, sessionID string, limit int) ([]*Record, error) {
+	query := s.withToolCalls(ctx).Where("session_id = ?", sessionID).Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var runs []*Record
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// withToolCalls is a helper to build a query that loads runs with their tool calls in order
+func (s *Store) withToolCalls(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Preload("ToolCalls", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	})
+}
+
+// GetDB returns the underlying GORM database connection
+func (s *Store) GetDB() *gorm.DB {
+	return s.db
+}
+
+// Close closes the database connection
+func (s *Store) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sql.DB from gorm.DB: %w", err)
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,65 @@
+package run
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRuns(t *testing.T) {
+	ctx := context.Background()
+	store := storetest.New(t, NewStore)
+
+	first := &Record{
+		ID:          "00000000-0000-0000-0000-000000000001",
+		CreatedAt:   time.Now().Add(-time.Minute),
+		SessionID:   "s1",
+		UserID:      "user-a",
+		AgentChain:  Chain{"overseer-agent", "memory-agent"},
+		Model:       "gpt-4.1",
+		Requests:    2,
+		TotalTokens: 120,
+		Duration:    3 * time.Second,
+		ToolCalls: []ToolCall{
+			{Sequence: 1, AgentID: "memory-agent", Tool: "search_facts", Arguments: `{"query":"cats"}`, Duration: time.Second},
+			{Sequence: 0, AgentID: "memory-agent", Tool: "save_fact", Arguments: `{}`, Error: "missing fact"},
+		},
+	}
+	second := &Record{ID: "00000000-0000-0000-0000-000000000002", SessionID: "s1", UserID: "user-a", AgentChain: Chain{"overseer-agent"}}
+	other := &Record{ID: "00000000-0000-0000-0000-000000000003", SessionID: "s2", UserID: "user-b"}
+
+	for _, run := range []*Record{first, second, other} {
+		require.NoError(t, store.CreateRun(ctx, run))
+	}
+	assert.Error(t, store.CreateRun(ctx, &Record{SessionID: "s1"}))
+
+	// Runs are loaded with their chain and tool calls in order
+	run, err := store.GetRun(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, Chain{"overseer-agent", "memory-agent"}, run.AgentChain)
+	assert.Equal(t, 3*time.Second, run.Duration)
+	require.Len(t, run.ToolCalls, 2)
+	assert.Equal(t, "save_fact", run.ToolCalls[0].Tool)
+	assert.Equal(t, "missing fact", run.ToolCalls[0].Error)
+	assert.Equal(t, `{"query":"cats"}`, run.ToolCalls[1].Arguments)
+
+	_, err = store.GetRun(ctx, "missing")
+	assert.ErrorIs(t, err, ErrRunNotFound)
+
+	// Session runs are listed newest first
+	runs, err := store.ListSessionRuns(ctx, "s1", 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, second.ID, runs[0].ID)
+	assert.Equal(t, first.ID, runs[1].ID)
+	assert.Len(t, runs[1].ToolCalls, 2)
+
+	runs, err = store.ListSessionRuns(ctx, "s1", 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, second.ID, runs[0].ID)
+}
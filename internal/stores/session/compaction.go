@@ -0,0 +1,263 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"github.com/nlpodyssey/openai-agents-go/memory"
+	"github.com/openai/openai-go/v2/packages/param"
+	"github.com/openai/openai-go/v2/responses"
+	"gorm.io/gorm"
+)
+
+// SUMMARY_PREFIX is prepended to the rolling summary when it is sent to the model
+const SUMMARY_PREFIX = "Summary of the earlier conversation in this session:\n\n"
+
+// summarizerInstructions are the instructions given to the agent that writes rolling summaries
+const summarizerInstructions = `You maintain a rolling summary of a conversation between a user and their personal assistant.
+You are given the previous summary (which may be empty) and a transcript of the messages and tool calls that happened after it.
+Write an updated summary that merges both. Keep facts, decisions, open tasks, names, dates, and anything the user asked to remember.
+Drop small talk and tool call details that no longer matter. Respond with the summary only, written in plain prose or short bullet points.`
+
+// Summary is the stored rolling summary of a session's older items.
+// The raw items it covers are kept in the items table for auditing.
+type Summary struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+
+	SessionID     uuid.UUID `json:"session_id" gorm:"type:char(36);not null;uniqueIndex"`
+	Content       string    `json:"content" gorm:"type:text;not null"`
+	ThroughItemID uint      `json:"through_item_id" gorm:"not null"` // ID of the newest item included in the summary
+	ItemCount     int       `json:"item_count" gorm:"not null"`      // Total number of items summarized so far
+}
+
+// TableName specifies the database table name for GORM
+func (*Summary) TableName() string {
+	return "session_summaries"
+}
+
+// ResponseItem returns the summary as an input item that can be sent before the windowed items
+func (s *Summary) ResponseItem() memory.TResponseInputItem {
+	return memory.TResponseInputItem{
+		OfMessage: &responses.EasyInputMessageParam{
+			Content: responses.EasyInputMessageContentUnionParam{
+				OfString: param.NewOpt(SUMMARY_PREFIX + s.Content),
+			},
+			Role: responses.EasyInputMessageRoleDeveloper,
+			Type: responses.EasyInputMessageTypeMessage,
+		},
+	}
+}
+
+// Summarizer merges a previous summary with newer items into an updated summary
+type Summarizer interface {
+	Summarize(ctx context.Context, previous string, items []*Item) (string, error)
+}
+
+// AgentSummarizer writes summaries using a dedicated agent
+type AgentSummarizer struct {
+	agent *agents.Agent
+}
+
+// NewAgentSummarizer creates a summarizer backed by the given model
+func NewAgentSummarizer(model string) *AgentSummarizer {
+	return &AgentSummarizer{
+		agent: agents.New("summarizer-agent").
+			WithInstructions(summarizerInstructions).
+			WithModel(model),
+	}
+}
+
+// Summarize runs the summarizer agent over the previous summary and the new items
+func (s *AgentSummarizer) Summarize(ctx context.Context, previous string, items []*Item) (string, error) {
+	var prompt strings.Builder
+	prompt.WriteString("Previous summary:\n")
+	if previous == "" {
+		prompt.WriteString("(none)\n")
+	} else {
+		prompt.WriteString(previous + "\n")
+	}
+	prompt.WriteString("\nTranscript:\n")
+	prompt.WriteString(Transcript(items))
+
+	result, err := agents.Runner{}.Run(ctx, s.agent, prompt.String())
+	if err != nil {
+		return "", fmt.Errorf("summarizer execution failed: %w", err)
+	}
+
+	return strings.TrimSpace(fmt.Sprint(result.FinalOutput)), nil
+}
+
+// Transcript renders items as a plain text transcript, one line per item
+func Transcript(items []*Item) string {
+	var b strings.Builder
+	for _, item := range items {
+		if line := itemText(item.ResponseItem); line != "" {
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// Helper function to render a single item as a transcript line
+func itemText(data ResponseItemData) string {
+	item := data.TResponseInputItem
+	if item == nil {
+		return ""
+	}
+
+	switch {
+	case item.OfMessage != nil:
+		content := item.OfMessage.Content
+		if content.OfString.Valid() {
+			return fmt.Sprintf("%s: %s", item.OfMessage.Role, content.OfString.Value)
+		}
+
+		var parts []string
+		for _, part := range content.OfInputItemContentList {
+			if part.OfInputText != nil {
+				parts = append(parts, part.OfInputText.Text)
+			}
+		}
+		return fmt.Sprintf("%s: %s", item.OfMessage.Role, strings.Join(parts, " "))
+	case item.OfOutputMessage != nil:
+		var parts []string
+		for _, part := range item.OfOutputMessage.Content {
+			if part.OfOutputText != nil {
+				parts = append(parts, part.OfOutputText.Text)
+			}
+		}
+		return "assistant: " + strings.Join(parts, " ")
+	case item.OfFunctionCall != nil:
+		return fmt.Sprintf("tool call %s(%s)", item.OfFunctionCall.Name, item.OfFunctionCall.Arguments)
+	case item.OfFunctionCallOutput != nil:
+		return "tool output: " + item.OfFunctionCallOutput.Output
+	default:
+		b, err := json.Marshal(item)
+		if err != nil {
+			return ""
+		}
+		return "item: " + string(b)
+	}
+}
+
+// Compactor summarizes the older items of sessions that grow past a threshold
+type Compactor struct {
+	store      Store
+	summarizer Summarizer
+	threshold  int // Number of unsummarized items that triggers a compaction
+	keep       int // Number of recent items left out of the summary
+}
+
+// NewCompactor creates a compactor. Sessions with more than 'threshold' unsummarized items
+// are compacted down to the latest 'keep' items plus the rolling summary.
+func NewCompactor(store Store, summarizer Summarizer, threshold, keep int) (*Compactor, error) {
+	if threshold <= 0 {
+		return nil, fmt.Errorf("compaction threshold must be positive")
+	}
+	if keep < 0 || keep >= threshold {
+		return nil, fmt.Errorf("compaction must keep between 0 and %d items", threshold-1)
+	}
+
+	return &Compactor{
+		store:      store,
+		summarizer: summarizer,
+		threshold:  threshold,
+		keep:       keep,
+	}, nil
+}
+
+// Compact summarizes a session's older items if it has passed the threshold.
+// It returns whether the summary was updated.
+func (c *Compactor) Compact(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	summary, err := c.store.GetSummary(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	if summary == nil {
+		summary = &Summary{SessionID: sessionID}
+	}
+
+	// Find the items the summary doesn't cover yet
+	items, err := c.store.GetSessionItems(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	pending := unsummarizedItems(items, summary)
+	if len(pending) <= c.threshold {
+		return false, nil
+	}
+
+	// Never split a tool call from its output; pull leading outputs into the summary
+	split := len(pending) - c.keep
+	for split < len(pending) && isToolCallOutput(pending[split].ResponseItem) {
+		split++
+	}
+
+	content, err := c.summarizer.Summarize(ctx, summary.Content, pending[:split])
+	if err != nil {
+		return false, err
+	}
+
+	summary.Content = content
+	summary.ThroughItemID = pending[split-1].ID
+	summary.ItemCount += split
+
+	if err := c.store.SaveSummary(ctx, summary); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Helper function to get the items that come after a summary
+func unsummarizedItems(items []*Item, summary *Summary) []*Item {
+	if summary == nil || summary.ThroughItemID == 0 {
+		return items
+	}
+
+	for i, item := range items {
+		if item.ID == summary.ThroughItemID {
+			return items[i+1:]
+		}
+	}
+
+	return items
+}
+
+// Helper function to check if an item is the output of a tool call
+func isToolCallOutput(data ResponseItemData) bool {
+	if data.TResponseInputItem == nil {
+		return false
+	}
+	_, ok := getToolCallIdFromOutput(data)
+	return ok
+}
+
+// Helper function to drop tool call outputs at the start of a window, since their calls were cut off
+func trimLeadingToolOutputs(items []memory.TResponseInputItem) []memory.TResponseInputItem {
+	for len(items) > 0 && isToolCallOutput(ResponseItemData{TResponseInputItem: &items[0]}) {
+		items = items[1:]
+	}
+	return items
+}
+
+// Helper function to load a session's summary, returning nil if there is none
+func findSummary(ctx context.Context, db *gorm.DB, sessionID uuid.UUID) (*Summary, error) {
+	var summary Summary
+	if err := db.WithContext(ctx).Where("session_id = ?", sessionID).First(&summary).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get session summary: %w", err)
+	}
+
+	return &summary, nil
+}
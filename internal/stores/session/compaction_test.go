@@ -0,0 +1,157 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nlpodyssey/openai-agents-go/memory"
+	"github.com/openai/openai-go/v2/packages/param"
+	"github.com/openai/openai-go/v2/responses"
+	"github.com/openai/openai-go/v2/shared/constant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSummarizer joins the previous summary with the transcript of the new items
+type fakeSummarizer struct {
+	calls int
+}
+
+func (f *fakeSummarizer) Summarize(ctx context.Context, previous string, items []*Item) (string, error) {
+	f.calls++
+	return strings.TrimSpace(previous + "\n" + Transcript(items)), nil
+}
+
+func userMessage(content string) memory.TResponseInputItem {
+	return memory.TResponseInputItem{
+		OfMessage: &responses.EasyInputMessageParam{
+			Content: responses.EasyInputMessageContentUnionParam{OfString: param.NewOpt(content)},
+			Role:    responses.EasyInputMessageRoleUser,
+			Type:    responses.EasyInputMessageTypeMessage,
+		},
+	}
+}
+
+func toolCall(callID string) memory.TResponseInputItem {
+	return memory.TResponseInputItem{
+		OfFunctionCall: &responses.ResponseFunctionToolCallParam{CallID: callID, Name: "get_fact", Arguments: "{}", Type: constant.ValueOf[constant.FunctionCall]()},
+	}
+}
+
+func toolOutput(callID string) memory.TResponseInputItem {
+	return memory.TResponseInputItem{
+		OfFunctionCallOutput: &responses.ResponseInputItemFunctionCallOutputParam{CallID: callID, Output: "ok", Type: constant.ValueOf[constant.FunctionCallOutput]()},
+	}
+}
+
+func TestCompactorCompact(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			testCompactorCompact(t, store)
+		})
+	}
+}
+
+func testCompactorCompact(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	sess, err := store.CreateSession(ctx, "user")
+	require.NoError(t, err)
+	id, _ := sessionInfo(sess)
+
+	summarizer := &fakeSummarizer{}
+	compactor, err := NewCompactor(store, summarizer, 5, 2)
+	require.NoError(t, err)
+
+	// Below the threshold nothing happens
+	require.NoError(t, sess.AddItems(ctx, []memory.TResponseInputItem{userMessage("one"), userMessage("two")}))
+	compacted, err := compactor.Compact(ctx, id)
+	require.NoError(t, err)
+	assert.False(t, compacted)
+	assert.Equal(t, 0, summarizer.calls)
+
+	// The kept window would start with a tool output, so it is summarized with its call
+	require.NoError(t, sess.AddItems(ctx, []memory.TResponseInputItem{
+		userMessage("three"),
+		userMessage("four"),
+		toolCall("call_1"),
+		toolOutput("call_1"),
+		userMessage("five"),
+	}))
+	compacted, err = compactor.Compact(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, compacted)
+
+	summary, err := store.GetSummary(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, summary)
+	assert.Equal(t, 6, summary.ItemCount)
+	assert.Contains(t, summary.Content, "user: one")
+	assert.Contains(t, summary.Content, "tool output: ok")
+	assert.NotContains(t, summary.Content, "five")
+
+	// The summary comes first, followed by the items it doesn't cover
+	items, err := sess.GetItems(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, SUMMARY_PREFIX+summary.Content, items[0].OfMessage.Content.OfString.Value)
+	assert.Equal(t, "five", items[1].OfMessage.Content.OfString.Value)
+
+	// Raw items are kept for auditing
+	raw, err := store.GetSessionItems(ctx, id)
+	require.NoError(t, err)
+	assert.Len(t, raw, 7)
+
+	// Later compactions roll the previous summary forward
+	for i := range 5 {
+		require.NoError(t, sess.AddItems(ctx, []memory.TResponseInputItem{userMessage(fmt.Sprintf("more %d", i))}))
+	}
+	compacted, err = compactor.Compact(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, compacted)
+
+	summary, err = store.GetSummary(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 10, summary.ItemCount)
+	assert.Contains(t, summary.Content, "user: one")
+	assert.Contains(t, summary.Content, "user: more 2")
+	assert.NotContains(t, summary.Content, "more 3")
+}
+
+func TestGetItemsDropsLeadingToolOutputs(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			testGetItemsDropsLeadingToolOutputs(t, store)
+		})
+	}
+}
+
+func testGetItemsDropsLeadingToolOutputs(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	sess, err := store.CreateSession(ctx, "user")
+	require.NoError(t, err)
+
+	require.NoError(t, sess.AddItems(ctx, []memory.TResponseInputItem{
+		userMessage("one"),
+		toolCall("call_1"),
+		toolOutput("call_1"),
+		userMessage("two"),
+	}))
+
+	// A window starting at the tool output drops it, since its call was cut off
+	items, err := sess.GetItems(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "two", items[0].OfMessage.Content.OfString.Value)
+}
+
+func TestNewCompactorValidation(t *testing.T) {
+	_, err := NewCompactor(NewInMemoryStore(), &fakeSummarizer{}, 0, 0)
+	assert.Error(t, err)
+
+	_, err = NewCompactor(NewInMemoryStore(), &fakeSummarizer{}, 5, 5)
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package session
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_SESSION_PAGE_SIZE = 20  // Number of sessions returned when no limit is given
+	MAX_SESSION_PAGE_SIZE     = 100 // Maximum number of sessions returned in a single page
+)
+
+// SessionFilter defines the filtering and pagination options used when listing sessions
+type SessionFilter struct {
+	UserID string `json:"user_id"` // Only include sessions owned by this user (optional)
+
+	CreatedAfter  time.Time `json:"created_after"`  // Only include sessions created at or after this time (optional)
+	CreatedBefore time.Time `json:"created_before"` // Only include sessions created before this time (optional)
+	UpdatedAfter  time.Time `json:"updated_after"`  // Only include sessions updated at or after this time (optional)
+	UpdatedBefore time.Time `json:"updated_before"` // Only include sessions updated before this time (optional)
+
+	Cursor string `json:"cursor"` // Opaque cursor returned from a previous page (optional)
+	Limit  int    `json:"limit"`  // Maximum number of sessions to return (optional)
+}
+
+// SessionPage represents a single page of sessions, ordered by most recently updated
+type SessionPage struct {
+	Sessions   []Session `json:"sessions"`
+	NextCursor string    `json:"next_cursor,omitempty"` // Empty when there are no more sessions
+}
+
+// pageSize returns the effective page size for the filter
+func (f SessionFilter) pageSize() int {
+	if f.Limit <= 0 {
+		return DEFAULT_SESSION_PAGE_SIZE
+	}
+	return min(f.Limit, MAX_SESSION_PAGE_SIZE)
+}
+
+// sessionCursor marks the position of the last session in a page
+type sessionCursor struct {
+	UpdatedAt time.Time
+	ID        string
+}
+
+// encodeCursor builds an opaque cursor string from a session position
+func encodeCursor(updatedAt time.Time, id string) string {
+	raw := fmt.Sprintf("%d|%s", updatedAt.UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses an opaque cursor string. An empty cursor returns nil
+func decodeCursor(cursor string) (*sessionCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid cursor format")
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor timestamp: %w", err)
+	}
+
+	return &sessionCursor{UpdatedAt: time.Unix(0, nanos).UTC(), ID: parts[1]}, nil
+}
@@ -23,7 +23,13 @@ func (r ResponseItemData) Value() (driver.Value, error) {
 	if r.TResponseInputItem == nil {
 		return nil, nil
 	}
-	return json.Marshal(r.TResponseInputItem)
+
+	// Stored as text so transcript searches can match it with LIKE
+	data, err := json.Marshal(r.TResponseInputItem)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
 }
 
 // Scan implements the sql.Scanner interface for database retrieval
@@ -1,14 +1,118 @@
 package session
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
 	"time"
 
+	"github.com/ethanbaker/assistant/internal/stores/vector"
+	"github.com/ethanbaker/assistant/pkg/agent"
 	"github.com/google/uuid"
+	"github.com/openai/openai-go/v2/responses"
 )
 
+// ErrSemanticSearchDisabled is returned by semantic searches when no vector index is configured
+var ErrSemanticSearchDisabled = errors.New("semantic search is not enabled")
+
+// ErrSearchUserRequired is returned by semantic searches when the context doesn't name the user to search for
+var ErrSearchUserRequired = errors.New("semantic session search requires a user")
+
 // SessionTranscript represents searchable session content
 type SessionTranscript struct {
 	SessionID uuid.UUID `json:"session_id"`
 	Data      string    `json:"data"`
 	CreatedAt time.Time `json:"created_at"`
+	Score     float32   `json:"score,omitempty"` // Similarity to the query, for semantic searches
+}
+
+// Helper function to search the session items of the context's user in a vector index
+func searchIndex(ctx context.Context, index vector.Index, query string, limit int) ([]*SessionTranscript, error) {
+	if index == nil {
+		return nil, ErrSemanticSearchDisabled
+	}
+
+	// Only the acting user's conversations may be searched
+	userID, ok := agent.UserIDFromContext(ctx)
+	if !ok {
+		return nil, ErrSearchUserRequired
+	}
+
+	matches, err := index.Search(ctx, vector.SOURCE_SESSION_ITEM, userID, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search sessions: %w", err)
+	}
+
+	transcripts := []*SessionTranscript{}
+	for _, match := range matches {
+		sessionID, err := uuid.Parse(match.SessionID)
+		if err != nil {
+			continue
+		}
+
+		transcripts = append(transcripts, &SessionTranscript{
+			SessionID: sessionID,
+			Data:      match.Content,
+			CreatedAt: match.UpdatedAt,
+			Score:     match.Score,
+		})
+	}
+
+	return transcripts, nil
+}
+
+// Helper function to add user and assistant messages to a vector index. Indexing is
+// best-effort so a failing embedder never prevents items from being stored.
+func indexItems(ctx context.Context, index vector.Index, userID string, items []*Item) {
+	if index == nil {
+		return
+	}
+
+	for _, item := range items {
+		if !isConversationMessage(item.ResponseItem) {
+			continue
+		}
+
+		doc := vector.Document{
+			Source:    vector.SOURCE_SESSION_ITEM,
+			SourceID:  strconv.FormatUint(uint64(item.ID), 10),
+			UserID:    userID,
+			SessionID: item.SessionID.String(),
+			Content:   itemText(item.ResponseItem),
+		}
+
+		if err := index.Upsert(ctx, doc); err != nil {
+			log.Printf("[SESSION]: Failed to index item %d: %v", item.ID, err)
+		}
+	}
+}
+
+// Helper function to remove a deleted session's items from a vector index. Failures are only logged.
+func unindexSession(ctx context.Context, index vector.Index, sessionID uuid.UUID) {
+	if index == nil {
+		return
+	}
+
+	if err := index.DeleteSession(ctx, sessionID.String()); err != nil {
+		log.Printf("[SESSION]: Failed to remove session %s from index: %v", sessionID, err)
+	}
+}
+
+// Helper function to check if an item is a user or assistant message
+func isConversationMessage(data ResponseItemData) bool {
+	item := data.TResponseInputItem
+	if item == nil {
+		return false
+	}
+
+	switch {
+	case item.OfOutputMessage != nil:
+		return true
+	case item.OfMessage != nil:
+		return item.OfMessage.Role == responses.EasyInputMessageRoleUser || item.OfMessage.Role == responses.EasyInputMessageRoleAssistant
+	default:
+		return false
+	}
 }
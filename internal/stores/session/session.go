@@ -3,10 +3,10 @@ package session
 import (
 	"context"
 	"fmt"
-	"slices"
 	"sync"
 	"time"
 
+	"github.com/ethanbaker/assistant/internal/stores/vector"
 	"github.com/google/uuid"
 	"github.com/nlpodyssey/openai-agents-go/memory"
 	"github.com/openai/openai-go/v2/shared/constant"
@@ -17,9 +17,11 @@ import (
 type Session interface {
 	memory.Session
 
+	GetUserID() string
 	GetItemCount() int
 	GetLastItem() *Item
 	GetLatestItems(ctx context.Context, n int) []Item
+	SaveItems(ctx context.Context, responseItems []memory.TResponseInputItem) ([]*Item, error)
 }
 
 // MySqlSession represents a conversation session
@@ -32,12 +34,18 @@ type MySqlSession struct {
 	UserID string  `json:"user_id" gorm:"size:255"`
 	Items  []*Item `json:"items,omitempty" gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
 
-	db *gorm.DB   `json:"-" gorm:"-"` // db used in openai-agents-go
-	mu sync.Mutex `json:"-" gorm:"-"` // mutex for thread-safe access
+	db    *gorm.DB     `json:"-" gorm:"-"` // db used in openai-agents-go
+	index vector.Index `json:"-" gorm:"-"` // optional index for semantic search
+	mu    sync.Mutex   `json:"-" gorm:"-"` // mutex for thread-safe access
 }
 
 /** Message management methods **/
 
+// GetUserID returns the ID of the user that owns the session
+func (s *MySqlSession) GetUserID() string {
+	return s.UserID
+}
+
 // GetItemCount returns the number of items in the session
 func (s *MySqlSession) GetItemCount() int {
 	if s.Items == nil {
@@ -85,13 +93,23 @@ func (s *MySqlSession) GetItems(ctx context.Context, limit int) ([]memory.TRespo
 		return nil, fmt.Errorf("database connection not available")
 	}
 
-	// Query messages associated with this session
+	// Find the rolling summary of older items, if the session has been compacted
+	summary, err := findSummary(ctx, s.db, s.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query messages associated with this session that the summary doesn't cover
 	var items []Item
-	query := s.db.WithContext(ctx).Where("session_id = ?", s.ID).Order("created_at DESC").Order("id DESC")
+	query := s.db.WithContext(ctx).Where("session_id = ?", s.ID)
+	if summary != nil {
+		query = query.Where("id > ?", summary.ThroughItemID)
+	}
+	query = query.Order("created_at DESC").Order("id DESC")
 
 	if limit > 0 {
 		// Get the latest N messages in descending order first
-		query = s.db.WithContext(ctx).Where("session_id = ?", s.ID).Order("created_at DESC").Order("id DESC").Limit(limit)
+		query = query.Limit(limit)
 	}
 
 	// Execute the query
@@ -122,20 +140,11 @@ func (s *MySqlSession) GetItems(ctx context.Context, limit int) ([]memory.TRespo
 	}
 
 	// Function calls and call outputs must appear together. So, if the limit ended with a function call output, truncate it
-	safeStart := false
-	for !safeStart && len(responseItems) > 0 {
-		switch *responseItems[0].GetType() {
-		case string(constant.ValueOf[constant.FunctionCallOutput]()):
-			responseItems = slices.Delete(responseItems, 0, 1)
-		case string(constant.ValueOf[constant.ComputerCallOutput]()):
-			responseItems = slices.Delete(responseItems, 0, 1)
-		case string(constant.ValueOf[constant.LocalShellCallOutput]()):
-			responseItems = slices.Delete(responseItems, 0, 1)
-		case string(constant.ValueOf[constant.CustomToolCallOutput]()):
-			responseItems = slices.Delete(responseItems, 0, 1)
-		default:
-			safeStart = true
-		}
+	responseItems = trimLeadingToolOutputs(responseItems)
+
+	// The summary always comes before the windowed items
+	if summary != nil {
+		responseItems = append([]memory.TResponseInputItem{summary.ResponseItem()}, responseItems...)
 	}
 
 	return responseItems, nil
@@ -143,14 +152,20 @@ func (s *MySqlSession) GetItems(ctx context.Context, limit int) ([]memory.TRespo
 
 // AddItems adds new items to the conversation history
 func (s *MySqlSession) AddItems(ctx context.Context, responseItems []memory.TResponseInputItem) error {
+	_, err := s.SaveItems(ctx, responseItems)
+	return err
+}
+
+// SaveItems adds new items to the conversation history and returns the stored items in the order they were saved
+func (s *MySqlSession) SaveItems(ctx context.Context, responseItems []memory.TResponseInputItem) ([]*Item, error) {
 	// Make sure database connection is available
 	if s.db == nil {
-		return fmt.Errorf("database connection not available")
+		return nil, fmt.Errorf("database connection not available")
 	}
 
 	// If no response items provided, nothing to add
 	if len(responseItems) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Convert TResponseInputItem to Item models
@@ -195,17 +210,28 @@ func (s *MySqlSession) AddItems(ctx context.Context, responseItems []memory.TRes
 	// Save items to database one-by-one to persist ordering
 	tx := s.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
-		return tx.Error
+		return nil, tx.Error
 	}
 
 	for _, item := range items {
 		if err := tx.Create(&item).Error; err != nil {
 			tx.Rollback()
-			return err
+			return nil, err
 		}
 	}
 
-	return tx.Commit().Error
+	// Mark the session as recently active
+	if err := tx.Model(&MySqlSession{}).Where("id = ?", s.ID).Update("updated_at", time.Now().UTC()).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	indexItems(ctx, s.index, s.UserID, items)
+	return items, nil
 }
 
 // PopItem removes and returns the most recent item from the session.
@@ -270,6 +296,11 @@ func (s *MySqlSession) SetDB(db *gorm.DB) {
 	s.db = db
 }
 
+// SetIndex sets the vector index new items are added to (for dependency injection)
+func (s *MySqlSession) SetIndex(index vector.Index) {
+	s.index = index
+}
+
 // InMemorySession represents a conversation session stored in memory
 type InMemorySession struct {
 	ID        uuid.UUID `json:"id"`
@@ -285,6 +316,11 @@ type InMemorySession struct {
 
 /** Message management methods **/
 
+// GetUserID returns the ID of the user that owns the session
+func (s *InMemorySession) GetUserID() string {
+	return s.UserID
+}
+
 // GetItemCount returns the number of items in the session
 func (s *InMemorySession) GetItemCount() int {
 	s.mu.RLock()
@@ -345,6 +381,13 @@ func (s *InMemorySession) GetItems(ctx context.Context, limit int) ([]memory.TRe
 		return nil, fmt.Errorf("failed to retrieve items: %w", err)
 	}
 
+	// Skip items covered by the rolling summary, if the session has been compacted
+	summary, err := s.store.GetSummary(ctx, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	items = unsummarizedItems(items, summary)
+
 	// Sort items in descending order first if we need to limit
 	if limit > 0 && len(items) > limit {
 		// Get the latest N items
@@ -371,21 +414,36 @@ func (s *InMemorySession) GetItems(ctx context.Context, limit int) ([]memory.TRe
 		}
 	}
 
+	// Function calls and call outputs must appear together. So, if the limit ended with a function call output, truncate it
+	responseItems = trimLeadingToolOutputs(responseItems)
+
+	// The summary always comes before the windowed items
+	if summary != nil {
+		responseItems = append([]memory.TResponseInputItem{summary.ResponseItem()}, responseItems...)
+	}
+
 	return responseItems, nil
 }
 
 // AddItems adds new items to the conversation history
 func (s *InMemorySession) AddItems(ctx context.Context, responseItems []memory.TResponseInputItem) error {
+	_, err := s.SaveItems(ctx, responseItems)
+	return err
+}
+
+// SaveItems adds new items to the conversation history and returns the stored items in the order they were saved
+func (s *InMemorySession) SaveItems(ctx context.Context, responseItems []memory.TResponseInputItem) ([]*Item, error) {
 	if s.store == nil {
-		return fmt.Errorf("store connection not available")
+		return nil, fmt.Errorf("store connection not available")
 	}
 
 	// If no response items provided, nothing to add
 	if len(responseItems) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Convert TResponseInputItem to Item models
+	items := make([]*Item, 0, len(responseItems))
 	for _, responseItem := range responseItems {
 		item := &Item{
 			SessionID: s.ID,
@@ -397,11 +455,13 @@ func (s *InMemorySession) AddItems(ctx context.Context, responseItems []memory.T
 
 		// Save item to store
 		if err := s.store.SaveItem(ctx, item); err != nil {
-			return fmt.Errorf("failed to save item: %w", err)
+			return nil, fmt.Errorf("failed to save item: %w", err)
 		}
+		items = append(items, item)
 	}
 
-	return nil
+	indexItems(ctx, s.store.index, s.UserID, items)
+	return items, nil
 }
 
 // PopItem removes and returns the most recent item from the session.
@@ -3,43 +3,55 @@ package session
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/ethanbaker/assistant/internal/stores/database"
+	"github.com/ethanbaker/assistant/internal/stores/vector"
 	"github.com/google/uuid"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
+// ErrSessionNotFound is returned when a session doesn't exist, or belongs to a different user
+var ErrSessionNotFound = errors.New("session not found")
+
 // Store interface defines methods for session storage
 type Store interface {
 	CreateSession(ctx context.Context, userID string) (Session, error)
 	GetSession(ctx context.Context, sessionID uuid.UUID) (Session, error)
 	GetSessionWithItems(ctx context.Context, sessionID uuid.UUID) (Session, error)
+	GetUserSessionWithItems(ctx context.Context, sessionID uuid.UUID, userID string) (Session, error)
 	SaveItem(ctx context.Context, item *Item) error
 	GetSessionItems(ctx context.Context, sessionID uuid.UUID) ([]*Item, error)
 	DeleteSession(ctx context.Context, sessionID uuid.UUID) error
-	SearchSessionTranscripts(ctx context.Context, query string) ([]*SessionTranscript, error)
+	ListSessions(ctx context.Context, filter SessionFilter) (*SessionPage, error)
+	SearchSessionTranscripts(ctx context.Context, userID, query string) ([]*SessionTranscript, error)
+	SearchSessionsSemantic(ctx context.Context, query string, limit int) ([]*SessionTranscript, error)
+	GetSummary(ctx context.Context, sessionID uuid.UUID) (*Summary, error)
+	SaveSummary(ctx context.Context, summary *Summary) error
 }
 
-// MySqlStore handles session persistence using GORM
+// MySqlStore handles session persistence in a SQL database (MySQL or SQLite) using GORM
 type MySqlStore struct {
-	db *gorm.DB
+	db    *gorm.DB
+	index vector.Index // optional index for semantic search
 }
 
-// NewMySqlStore creates a new session store with GORM connection
+// NewMySqlStore creates a new SQL session store connected to the database URL (see database.ParseURL)
 func NewMySqlStore(databaseURL string) (*MySqlStore, error) {
-	db, err := gorm.Open(mysql.Open(databaseURL), &gorm.Config{})
+	db, err := database.Open(databaseURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
 	store := &MySqlStore{db: db}
 
 	// Auto-migrate tables
-	if err := db.AutoMigrate(&MySqlSession{}, &Item{}); err != nil {
+	if err := db.AutoMigrate(&MySqlSession{}, &Item{}, &Summary{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate tables: %w", err)
 	}
 
@@ -55,6 +67,7 @@ func (s *MySqlStore) CreateSession(ctx context.Context, userID string) (Session,
 		Items:  []*Item{},
 	}
 	session.db = s.db // Set the GORM DB connection
+	session.index = s.index
 
 	result := s.db.WithContext(ctx).Create(session)
 	if result.Error != nil {
@@ -73,13 +86,14 @@ func (s *MySqlStore) GetSession(ctx context.Context, sessionID uuid.UUID) (Sessi
 	if result.Error != nil {
 		// Handle not found error
 		if result.Error == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("session not found")
+			return nil, ErrSessionNotFound
 		}
 		// Handle generic errors
 		return nil, fmt.Errorf("failed to get session: %w", result.Error)
 	}
 
 	session.db = s.db // Set the GORM DB connection
+	session.index = s.index
 	return &session, nil
 }
 
@@ -96,12 +110,36 @@ func (s *MySqlStore) GetSessionWithItems(ctx context.Context, sessionID uuid.UUI
 	if result.Error != nil {
 		// Handle not found error
 		if result.Error == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("session not found")
+			return nil, ErrSessionNotFound
+		}
+		// Handle generic errors
+		return nil, fmt.Errorf("failed to get session with items: %w", result.Error)
+	}
+
+	return &session, nil
+}
+
+// GetUserSessionWithItems retrieves a session owned by a user with all its items preloaded in order.
+// Sessions of other users are reported as not found
+func (s *MySqlStore) GetUserSessionWithItems(ctx context.Context, sessionID uuid.UUID, userID string) (Session, error) {
+	var session MySqlSession
+	result := s.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC").Order("id ASC")
+		}).
+		First(&session, "id = ? AND user_id = ?", sessionID, userID)
+
+	if result.Error != nil {
+		// Handle not found error
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrSessionNotFound
 		}
 		// Handle generic errors
 		return nil, fmt.Errorf("failed to get session with items: %w", result.Error)
 	}
 
+	session.db = s.db // Set the GORM DB connection
+	session.index = s.index
 	return &session, nil
 }
 
@@ -130,12 +168,17 @@ func (s *MySqlStore) GetSessionItems(ctx context.Context, sessionID uuid.UUID) (
 // DeleteSession deletes a session and its items from the database
 func (s *MySqlStore) DeleteSession(ctx context.Context, sessionID uuid.UUID) error {
 	// Start a transaction
-	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Delete items associated with the session
 		if err := tx.Where("session_id = ?", sessionID).Delete(&Item{}).Error; err != nil {
 			return fmt.Errorf("failed to delete session items: %w", err)
 		}
 
+		// Delete the session's rolling summary
+		if err := tx.Where("session_id = ?", sessionID).Delete(&Summary{}).Error; err != nil {
+			return fmt.Errorf("failed to delete session summary: %w", err)
+		}
+
 		// Delete the session itself
 		if err := tx.Where("id = ?", sessionID).Delete(&MySqlSession{}).Error; err != nil {
 			return fmt.Errorf("failed to delete session: %w", err)
@@ -143,17 +186,79 @@ func (s *MySqlStore) DeleteSession(ctx context.Context, sessionID uuid.UUID) err
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Remove the session's items from the index once the deletion is committed
+	unindexSession(ctx, s.index, sessionID)
+	return nil
 }
 
-// SearchSessionTranscripts performs full-text search across session messages and tool calls
-func (s *MySqlStore) SearchSessionTranscripts(ctx context.Context, query string) ([]*SessionTranscript, error) {
+// ListSessions returns a page of sessions matching the filter, ordered by most recently updated
+func (s *MySqlStore) ListSessions(ctx context.Context, filter SessionFilter) (*SessionPage, error) {
+	cursor, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply filters
+	query := s.db.WithContext(ctx).Model(&MySqlSession{})
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedAfter.UTC())
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedBefore.UTC())
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		query = query.Where("updated_at >= ?", filter.UpdatedAfter.UTC())
+	}
+	if !filter.UpdatedBefore.IsZero() {
+		query = query.Where("updated_at < ?", filter.UpdatedBefore.UTC())
+	}
+
+	// Continue after the last session of the previous page
+	if cursor != nil {
+		query = query.Where("updated_at < ? OR (updated_at = ? AND id < ?)", cursor.UpdatedAt, cursor.UpdatedAt, cursor.ID)
+	}
+
+	// Fetch one extra session to know whether there is another page
+	size := filter.pageSize()
+	var sessions []*MySqlSession
+	if err := query.Order("updated_at DESC").Order("id DESC").Limit(size + 1).Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	page := &SessionPage{Sessions: []Session{}}
+	for i, session := range sessions {
+		if i == size {
+			last := sessions[size-1]
+			page.NextCursor = encodeCursor(last.UpdatedAt, last.ID.String())
+			break
+		}
+
+		session.db = s.db // Set the GORM DB connection
+		session.index = s.index
+		page.Sessions = append(page.Sessions, session)
+	}
+
+	return page, nil
+}
+
+// SearchSessionTranscripts performs full-text search across a user's session messages and tool calls
+func (s *MySqlStore) SearchSessionTranscripts(ctx context.Context, userID, query string) ([]*SessionTranscript, error) {
 	var transcripts []*SessionTranscript
 	searchPattern := "%" + query + "%"
 
-	// Search in items
+	// Search in the items of the user's sessions
 	var items []Item
-	result := s.db.WithContext(ctx).Where("data LIKE ?", searchPattern).
-		Order("created_at DESC").Order("id DESC").Limit(50).Find(&items)
+	result := s.db.WithContext(ctx).Select("items.*").
+		Joins("JOIN sessions ON sessions.id = items.session_id").
+		Where("sessions.user_id = ? AND items.data LIKE ?", userID, searchPattern).
+		Order("items.created_at DESC").Order("items.id DESC").Limit(50).Find(&items)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to search messages: %w", result.Error)
 	}
@@ -176,6 +281,30 @@ func (s *MySqlStore) SearchSessionTranscripts(ctx context.Context, query string)
 	return transcripts, nil
 }
 
+// SearchSessionsSemantic finds the messages of the context's user (see agent.WithUserID) most similar in meaning to the query
+func (s *MySqlStore) SearchSessionsSemantic(ctx context.Context, query string, limit int) ([]*SessionTranscript, error) {
+	return searchIndex(ctx, s.index, query, limit)
+}
+
+// SetIndex sets the vector index used for semantic search (for dependency injection)
+func (s *MySqlStore) SetIndex(index vector.Index) {
+	s.index = index
+}
+
+// GetSummary retrieves the rolling summary for a session, or nil if it hasn't been compacted
+func (s *MySqlStore) GetSummary(ctx context.Context, sessionID uuid.UUID) (*Summary, error) {
+	return findSummary(ctx, s.db, sessionID)
+}
+
+// SaveSummary creates or updates the rolling summary for a session
+func (s *MySqlStore) SaveSummary(ctx context.Context, summary *Summary) error {
+	if err := s.db.WithContext(ctx).Save(summary).Error; err != nil {
+		return fmt.Errorf("failed to save session summary: %w", err)
+	}
+
+	return nil
+}
+
 // GetDB returns the underlying GORM database connection
 func (s *MySqlStore) GetDB() *gorm.DB {
 	return s.db
@@ -192,17 +321,20 @@ func (s *MySqlStore) Close() error {
 
 // InMemoryStore creates a new in-memory session store (for one-off operations)
 type InMemoryStore struct {
-	sessions map[uuid.UUID]*InMemorySession
-	items    map[uuid.UUID][]*Item // sessionID -> items
-	mu       sync.RWMutex
+	sessions  map[uuid.UUID]*InMemorySession
+	items     map[uuid.UUID][]*Item  // sessionID -> items
+	summaries map[uuid.UUID]*Summary // sessionID -> rolling summary
+	index     vector.Index           // optional index for semantic search
+	mu        sync.RWMutex
 }
 
 // NewInMemoryStore creates a new in-memory session store
 func NewInMemoryStore() *InMemoryStore {
 	return &InMemoryStore{
-		sessions: make(map[uuid.UUID]*InMemorySession),
-		items:    make(map[uuid.UUID][]*Item),
-		mu:       sync.RWMutex{},
+		sessions:  make(map[uuid.UUID]*InMemorySession),
+		items:     make(map[uuid.UUID][]*Item),
+		summaries: make(map[uuid.UUID]*Summary),
+		mu:        sync.RWMutex{},
 	}
 }
 
@@ -235,7 +367,7 @@ func (s *InMemoryStore) GetSession(ctx context.Context, sessionID uuid.UUID) (Se
 
 	session, exists := s.sessions[sessionID]
 	if !exists {
-		return nil, fmt.Errorf("session not found")
+		return nil, ErrSessionNotFound
 	}
 
 	return session, nil
@@ -248,7 +380,7 @@ func (s *InMemoryStore) GetSessionWithItems(ctx context.Context, sessionID uuid.
 
 	session, exists := s.sessions[sessionID]
 	if !exists {
-		return nil, fmt.Errorf("session not found")
+		return nil, ErrSessionNotFound
 	}
 
 	// Copy items to avoid race conditions
@@ -259,6 +391,20 @@ func (s *InMemoryStore) GetSessionWithItems(ctx context.Context, sessionID uuid.
 	return session, nil
 }
 
+// GetUserSessionWithItems retrieves a session owned by a user with all its items preloaded in order.
+// Sessions of other users are reported as not found
+func (s *InMemoryStore) GetUserSessionWithItems(ctx context.Context, sessionID uuid.UUID, userID string) (Session, error) {
+	session, err := s.GetSessionWithItems(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.GetUserID() != userID {
+		return nil, ErrSessionNotFound
+	}
+
+	return session, nil
+}
+
 // SaveItem saves an item to memory
 func (s *InMemoryStore) SaveItem(ctx context.Context, item *Item) error {
 	if item == nil {
@@ -270,7 +416,7 @@ func (s *InMemoryStore) SaveItem(ctx context.Context, item *Item) error {
 
 	// Check if session exists
 	if _, exists := s.sessions[item.SessionID]; !exists {
-		return fmt.Errorf("session not found")
+		return ErrSessionNotFound
 	}
 
 	// Set timestamps
@@ -289,9 +435,10 @@ func (s *InMemoryStore) SaveItem(ctx context.Context, item *Item) error {
 	// Add item to session
 	s.items[item.SessionID] = append(s.items[item.SessionID], item)
 
-	// Update session items reference
+	// Update session items reference and activity time
 	if session, exists := s.sessions[item.SessionID]; exists {
 		session.Items = append(session.Items, item)
+		session.UpdatedAt = now
 	}
 
 	return nil
@@ -320,25 +467,147 @@ func (s *InMemoryStore) DeleteSession(ctx context.Context, sessionID uuid.UUID)
 	defer s.mu.Unlock()
 
 	if _, exists := s.sessions[sessionID]; !exists {
-		return fmt.Errorf("session not found")
+		return ErrSessionNotFound
 	}
 
-	// Delete session and its items
+	// Delete session, its items, and its summary
 	delete(s.sessions, sessionID)
 	delete(s.items, sessionID)
+	delete(s.summaries, sessionID)
 
+	// Remove the session's items from the index
+	unindexSession(ctx, s.index, sessionID)
 	return nil
 }
 
-// SearchSessionTranscripts performs full-text search across session messages and tool calls
-func (s *InMemoryStore) SearchSessionTranscripts(ctx context.Context, query string) ([]*SessionTranscript, error) {
+// ListSessions returns a page of sessions matching the filter, ordered by most recently updated
+func (s *InMemoryStore) ListSessions(ctx context.Context, filter SessionFilter) (*SessionPage, error) {
+	cursor, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Apply filters
+	var sessions []*InMemorySession
+	for _, session := range s.sessions {
+		if filter.UserID != "" && session.UserID != filter.UserID {
+			continue
+		}
+		if !filter.CreatedAfter.IsZero() && session.CreatedAt.Before(filter.CreatedAfter) {
+			continue
+		}
+		if !filter.CreatedBefore.IsZero() && !session.CreatedAt.Before(filter.CreatedBefore) {
+			continue
+		}
+		if !filter.UpdatedAfter.IsZero() && session.UpdatedAt.Before(filter.UpdatedAfter) {
+			continue
+		}
+		if !filter.UpdatedBefore.IsZero() && !session.UpdatedAt.Before(filter.UpdatedBefore) {
+			continue
+		}
+
+		// Continue after the last session of the previous page
+		if cursor != nil {
+			id := session.ID.String()
+			if session.UpdatedAt.After(cursor.UpdatedAt) || (session.UpdatedAt.Equal(cursor.UpdatedAt) && id >= cursor.ID) {
+				continue
+			}
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	// Sort sessions by updated time (descending), then by ID (descending)
+	slices.SortFunc(sessions, func(a, b *InMemorySession) int {
+		if c := b.UpdatedAt.Compare(a.UpdatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(b.ID.String(), a.ID.String())
+	})
+
+	// Cut the page
+	size := filter.pageSize()
+	page := &SessionPage{Sessions: []Session{}}
+	for i, session := range sessions {
+		if i == size {
+			last := sessions[size-1]
+			page.NextCursor = encodeCursor(last.UpdatedAt, last.ID.String())
+			break
+		}
+
+		page.Sessions = append(page.Sessions, session)
+	}
+
+	return page, nil
+}
+
+// SearchSessionsSemantic finds the messages of the context's user (see agent.WithUserID) most similar in meaning to the query
+func (s *InMemoryStore) SearchSessionsSemantic(ctx context.Context, query string, limit int) ([]*SessionTranscript, error) {
+	return searchIndex(ctx, s.index, query, limit)
+}
+
+// SetIndex sets the vector index used for semantic search (for dependency injection)
+func (s *InMemoryStore) SetIndex(index vector.Index) {
+	s.index = index
+}
+
+// GetSummary retrieves the rolling summary for a session, or nil if it hasn't been compacted
+func (s *InMemoryStore) GetSummary(ctx context.Context, sessionID uuid.UUID) (*Summary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summary, exists := s.summaries[sessionID]
+	if !exists {
+		return nil, nil
+	}
+
+	// Return a copy so callers can't modify the stored summary
+	result := *summary
+	return &result, nil
+}
+
+// SaveSummary creates or updates the rolling summary for a session
+func (s *InMemoryStore) SaveSummary(ctx context.Context, summary *Summary) error {
+	if summary == nil {
+		return fmt.Errorf("summary cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.sessions[summary.SessionID]; !exists {
+		return ErrSessionNotFound
+	}
+
+	// Set timestamps
+	now := time.Now().UTC()
+	if summary.CreatedAt.IsZero() {
+		summary.CreatedAt = now
+	}
+	summary.UpdatedAt = now
+
+	stored := *summary
+	s.summaries[summary.SessionID] = &stored
+
+	return nil
+}
+
+// SearchSessionTranscripts performs full-text search across a user's session messages and tool calls
+func (s *InMemoryStore) SearchSessionTranscripts(ctx context.Context, userID, query string) ([]*SessionTranscript, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	var transcripts []*SessionTranscript
 
-	// Search through all items in all sessions
+	// Search through all items in the user's sessions
 	for sessionID, items := range s.items {
+		if session, exists := s.sessions[sessionID]; !exists || session.UserID != userID {
+			continue
+		}
+
 		for _, item := range items {
 			// Convert ResponseItem to json for searching
 			data, err := json.Marshal(item.ResponseItem)
@@ -0,0 +1,307 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/storetest"
+	"github.com/ethanbaker/assistant/internal/stores/vector"
+	"github.com/ethanbaker/assistant/pkg/agent"
+	"github.com/google/uuid"
+	"github.com/nlpodyssey/openai-agents-go/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStores returns every store implementation. The SQL store uses an in-memory SQLite database.
+func testStores(t *testing.T) map[string]Store {
+	return map[string]Store{
+		"in-memory": NewInMemoryStore(),
+		"sql":       storetest.New(t, NewMySqlStore),
+	}
+}
+
+// setSessionTimes overrides the creation and update times of a session
+func setSessionTimes(t *testing.T, store Store, sess Session, createdAt, updatedAt time.Time) {
+	switch s := sess.(type) {
+	case *InMemorySession:
+		s.CreatedAt = createdAt
+		s.UpdatedAt = updatedAt
+	case *MySqlSession:
+		require.NoError(t, store.(*MySqlStore).db.Model(s).UpdateColumns(map[string]any{
+			"created_at": createdAt,
+			"updated_at": updatedAt,
+		}).Error)
+	}
+}
+
+// sessionInfo returns the ID and update time of a session from either store
+func sessionInfo(sess Session) (uuid.UUID, time.Time) {
+	switch s := sess.(type) {
+	case *InMemorySession:
+		return s.ID, s.UpdatedAt
+	case *MySqlSession:
+		return s.ID, s.UpdatedAt
+	}
+	return uuid.Nil, time.Time{}
+}
+
+func TestStoreListSessions(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			testListSessions(t, store)
+		})
+	}
+}
+
+func testListSessions(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	// Create sessions for two users with distinct update times
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		userID := "user-a"
+		if i%2 == 1 {
+			userID = "user-b"
+		}
+
+		sess, err := store.CreateSession(ctx, userID)
+		require.NoError(t, err)
+
+		setSessionTimes(t, store, sess, base.Add(time.Duration(i)*time.Hour), base.Add(time.Duration(i)*time.Hour))
+	}
+
+	t.Run("filter by user", func(t *testing.T) {
+		page, err := store.ListSessions(ctx, SessionFilter{UserID: "user-a"})
+		require.NoError(t, err)
+		assert.Len(t, page.Sessions, 3)
+		assert.Empty(t, page.NextCursor)
+
+		for _, sess := range page.Sessions {
+			assert.Equal(t, "user-a", sess.GetUserID())
+		}
+	})
+
+	t.Run("filter by time range", func(t *testing.T) {
+		page, err := store.ListSessions(ctx, SessionFilter{
+			CreatedAfter:  base.Add(1 * time.Hour),
+			UpdatedBefore: base.Add(4 * time.Hour),
+		})
+		require.NoError(t, err)
+		assert.Len(t, page.Sessions, 3)
+
+		// Bounds in another time zone match the same sessions
+		zone := time.FixedZone("UTC+10", 10*60*60)
+		page, err = store.ListSessions(ctx, SessionFilter{
+			CreatedAfter:  base.Add(1 * time.Hour).In(zone),
+			UpdatedBefore: base.Add(4 * time.Hour).In(zone),
+		})
+		require.NoError(t, err)
+		assert.Len(t, page.Sessions, 3)
+	})
+
+	t.Run("cursor pagination", func(t *testing.T) {
+		var seen []time.Time
+		filter := SessionFilter{Limit: 2}
+
+		for {
+			page, err := store.ListSessions(ctx, filter)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(page.Sessions), 2)
+
+			for _, sess := range page.Sessions {
+				_, updatedAt := sessionInfo(sess)
+				seen = append(seen, updatedAt)
+			}
+
+			if page.NextCursor == "" {
+				break
+			}
+			filter.Cursor = page.NextCursor
+		}
+
+		// Every session is returned once, most recently updated first
+		require.Len(t, seen, 5)
+		for i := 1; i < len(seen); i++ {
+			assert.True(t, seen[i-1].After(seen[i]))
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := store.ListSessions(ctx, SessionFilter{Cursor: "not a cursor"})
+		assert.Error(t, err)
+	})
+}
+
+func TestStoreGetUserSession(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			testGetUserSession(t, store)
+		})
+	}
+}
+
+func testGetUserSession(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	sess, err := store.CreateSession(ctx, "user-a")
+	require.NoError(t, err)
+	id, _ := sessionInfo(sess)
+
+	// The owner can read the session
+	got, err := store.GetUserSessionWithItems(ctx, id, "user-a")
+	require.NoError(t, err)
+	assert.Equal(t, "user-a", got.GetUserID())
+
+	// Other users can't tell the session exists
+	_, err = store.GetUserSessionWithItems(ctx, id, "user-b")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+
+	_, err = store.GetUserSessionWithItems(ctx, id, "")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+
+	_, err = store.GetUserSessionWithItems(ctx, uuid.New(), "user-a")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestStoreSearchSessionsSemantic(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			testSearchSessionsSemantic(t, store)
+		})
+	}
+}
+
+func testSearchSessionsSemantic(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	// Searching without an index is reported as disabled
+	_, err := store.SearchSessionsSemantic(ctx, "anything", 5)
+	assert.ErrorIs(t, err, ErrSemanticSearchDisabled)
+
+	store.(interface{ SetIndex(vector.Index) }).SetIndex(vector.NewInMemoryIndex(vector.NewHashEmbedder(vector.DEFAULT_HASH_DIMENSIONS)))
+
+	sess, err := store.CreateSession(ctx, "user")
+	require.NoError(t, err)
+	require.NoError(t, sess.AddItems(ctx, []memory.TResponseInputItem{
+		userMessage("remind me to water the plants"),
+		toolCall("call_1"),
+		toolOutput("call_1"),
+		userMessage("book a table for dinner on friday"),
+	}))
+
+	other, err := store.CreateSession(ctx, "other-user")
+	require.NoError(t, err)
+	require.NoError(t, other.AddItems(ctx, []memory.TResponseInputItem{
+		userMessage("book a table for dinner on friday night"),
+	}))
+
+	// Searches must be for a user
+	_, err = store.SearchSessionsSemantic(ctx, "dinner reservation friday", 5)
+	assert.ErrorIs(t, err, ErrSearchUserRequired)
+
+	ctx = agent.WithUserID(ctx, "user")
+
+	// Messages are found by meaning; tool calls and other users' messages aren't returned
+	transcripts, err := store.SearchSessionsSemantic(ctx, "dinner reservation friday", 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, transcripts)
+	assert.Equal(t, "user: book a table for dinner on friday", transcripts[0].Data)
+	id, _ := sessionInfo(sess)
+	assert.Equal(t, id, transcripts[0].SessionID)
+	otherID, _ := sessionInfo(other)
+	for _, transcript := range transcripts {
+		assert.NotContains(t, transcript.Data, "tool")
+		assert.NotEqual(t, otherID, transcript.SessionID)
+	}
+
+	// The other user only finds their own messages
+	transcripts, err = store.SearchSessionsSemantic(agent.WithUserID(context.Background(), "other-user"), "dinner reservation friday", 5)
+	require.NoError(t, err)
+	require.Len(t, transcripts, 1)
+	assert.Equal(t, otherID, transcripts[0].SessionID)
+
+	// Deleting the session removes its messages from the index
+	require.NoError(t, store.DeleteSession(ctx, id))
+	transcripts, err = store.SearchSessionsSemantic(ctx, "dinner reservation friday", 5)
+	require.NoError(t, err)
+	assert.Empty(t, transcripts)
+}
+
+func TestStoreSearchSessionTranscripts(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			testSearchSessionTranscripts(t, store)
+		})
+	}
+}
+
+func testSearchSessionTranscripts(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	sess, err := store.CreateSession(ctx, "user-a")
+	require.NoError(t, err)
+	require.NoError(t, sess.AddItems(ctx, []memory.TResponseInputItem{
+		userMessage("book a table for dinner on friday"),
+	}))
+	id, _ := sessionInfo(sess)
+
+	other, err := store.CreateSession(ctx, "user-b")
+	require.NoError(t, err)
+	require.NoError(t, other.AddItems(ctx, []memory.TResponseInputItem{
+		userMessage("dinner with the team on friday"),
+	}))
+	otherID, _ := sessionInfo(other)
+
+	// Each user only finds their own transcripts
+	transcripts, err := store.SearchSessionTranscripts(ctx, "user-a", "dinner")
+	require.NoError(t, err)
+	require.Len(t, transcripts, 1)
+	assert.Equal(t, id, transcripts[0].SessionID)
+
+	transcripts, err = store.SearchSessionTranscripts(ctx, "user-b", "dinner")
+	require.NoError(t, err)
+	require.Len(t, transcripts, 1)
+	assert.Equal(t, otherID, transcripts[0].SessionID)
+
+	// Unknown users find nothing
+	transcripts, err = store.SearchSessionTranscripts(ctx, "user-c", "dinner")
+	require.NoError(t, err)
+	assert.Empty(t, transcripts)
+}
+
+func TestStoreDeleteSessionSqlIndex(t *testing.T) {
+	ctx := agent.WithUserID(context.Background(), "user")
+
+	// The index shares the store's database, as it does in the API
+	store := storetest.New(t, NewMySqlStore)
+	index, err := vector.NewSqlIndex(store.GetDB(), vector.NewHashEmbedder(vector.DEFAULT_HASH_DIMENSIONS))
+	require.NoError(t, err)
+	store.SetIndex(index)
+
+	sess, err := store.CreateSession(ctx, "user")
+	require.NoError(t, err)
+	require.NoError(t, sess.AddItems(ctx, []memory.TResponseInputItem{
+		userMessage("book a table for dinner on friday"),
+	}))
+	id, _ := sessionInfo(sess)
+
+	transcripts, err := store.SearchSessionsSemantic(ctx, "dinner reservation friday", 5)
+	require.NoError(t, err)
+	require.Len(t, transcripts, 1)
+
+	// Deleting doesn't wait on the connection held by its own transaction
+	done := make(chan error, 1)
+	go func() { done <- store.DeleteSession(ctx, id) }()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("DeleteSession did not return")
+	}
+
+	transcripts, err = store.SearchSessionsSemantic(ctx, "dinner reservation friday", 5)
+	require.NoError(t, err)
+	assert.Empty(t, transcripts)
+}
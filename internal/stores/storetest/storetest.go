@@ -0,0 +1,21 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// MEMORY_DATABASE_URL is the database URL stores are created with in tests
+const MEMORY_DATABASE_URL = "sqlite://:memory:"
+
+// New creates a store backed by an in-memory SQLite database, closing it once the test finishes
+func New[S interface{ Close() error }](t testing.TB, newStore func(databaseURL string) (S, error)) S {
+	t.Helper()
+
+	store, err := newStore(MEMORY_DATABASE_URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
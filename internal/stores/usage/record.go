@@ -0,0 +1,39 @@
+package usage
+
+import "time"
+
+// Record is the token usage of one model during one agent run
+type Record struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;index"`
+
+	UserID    string `json:"user_id" gorm:"column:user_id;size:255;index;default:''"` // User that owns the session the run was for
+	SessionID string `json:"session_id" gorm:"column:session_id;size:36;index"`       // Session the run was for
+	Model     string `json:"model" gorm:"column:model;size:255;not null"`             // Model the tokens were used on
+
+	Requests     uint64 `json:"requests" gorm:"column:requests"`           // Number of model calls
+	InputTokens  uint64 `json:"input_tokens" gorm:"column:input_tokens"`   // Tokens sent to the model
+	OutputTokens uint64 `json:"output_tokens" gorm:"column:output_tokens"` // Tokens received from the model
+	TotalTokens  uint64 `json:"total_tokens" gorm:"column:total_tokens"`   // Tokens sent and received
+}
+
+// TableName sets the table name for GORM
+func (Record) TableName() string {
+	return "agent_usage"
+}
+
+// Totals is the summed usage of a model
+type Totals struct {
+	Model        string `json:"model"`
+	Requests     uint64 `json:"requests"`
+	InputTokens  uint64 `json:"input_tokens"`
+	OutputTokens uint64 `json:"output_tokens"`
+	TotalTokens  uint64 `json:"total_tokens"`
+}
+
+// Filter limits the records that usage is summed over. Zero values don't filter
+type Filter struct {
+	UserID string
+	Since  time.Time // Inclusive
+	Until  time.Time // Exclusive
+}
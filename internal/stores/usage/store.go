@@ -0,0 +1,107 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/database"
+	"gorm.io/gorm"
+)
+
+// Store handles token usage persistence using GORM
+type Store struct {
+	db *gorm.DB
+}
+
+// NewStore creates a new usage store connected to the database URL (see database.ParseURL)
+func NewStore(databaseURL string) (*Store, error) {
+	db, err := database.Open(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{db: db}
+
+	// Auto-migrate tables
+	if err := store.db.AutoMigrate(&Record{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate tables: %w", err)
+	}
+
+	return store, nil
+}
+
+// Record stores the usage of a run. Records without any requests are skipped
+func (s *Store) Record(ctx context.Context, records ...*Record) error {
+	var used []*Record
+	for _, record := range records {
+		if record.Requests > 0 || record.TotalTokens > 0 {
+			used = append(used, record)
+		}
+	}
+	if len(used) == 0 {
+		return nil
+	}
+
+	if err := s.db.WithContext(ctx).Create(&used).Error; err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+	return nil
+}
+
+// Summarize sums the usage matching a filter per model, ordered by model name
+func (s *Store) Summarize(ctx context.Context, filter Filter) ([]Totals, error) {
+	var totals []Totals
+	err := s.filter(ctx, filter).
+		Select("model, SUM(requests) AS requests, SUM(input_tokens) AS input_tokens, SUM(output_tokens) AS output_tokens, SUM(total_tokens) AS total_tokens").
+		Group("model").
+		Order("model ASC").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize usage: %w", err)
+	}
+
+	return totals, nil
+}
+
+// TotalTokens returns the number of tokens a user has used since a time
+func (s *Store) TotalTokens(ctx context.Context, userID string, since time.Time) (uint64, error) {
+	var total uint64
+	err := s.filter(ctx, Filter{UserID: userID, Since: since}).
+		Select("COALESCE(SUM(total_tokens), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+
+	return total, nil
+}
+
+// filter is a helper to build a query for the records matching a filter
+func (s *Store) filter(ctx context.Context, filter Filter) *gorm.DB {
+	query := s.db.WithContext(ctx).Model(&Record{})
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until.UTC())
+	}
+	return query
+}
+
+// GetDB returns the underlying GORM database connection
+func (s *Store) GetDB() *gorm.DB {
+	return s.db
+}
+
+// Close closes the database connection
+func (s *Store) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sql.DB from gorm.DB: %w", err)
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,52 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/assistant/internal/stores/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreUsage(t *testing.T) {
+	ctx := context.Background()
+	store := storetest.New(t, NewStore)
+
+	require.NoError(t, store.Record(ctx,
+		&Record{UserID: "user-a", SessionID: "s1", Model: "gpt-4.1", Requests: 2, InputTokens: 100, OutputTokens: 20, TotalTokens: 120},
+		&Record{UserID: "user-a", SessionID: "s1", Model: "gpt-4.1-mini", Requests: 1, InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+		&Record{UserID: "user-a", SessionID: "s1", Model: "unused"},
+	))
+	require.NoError(t, store.Record(ctx,
+		&Record{UserID: "user-a", SessionID: "s2", Model: "gpt-4.1", Requests: 1, InputTokens: 50, OutputTokens: 10, TotalTokens: 60},
+		&Record{UserID: "user-b", SessionID: "s3", Model: "gpt-4.1", Requests: 1, InputTokens: 1000, OutputTokens: 1, TotalTokens: 1001},
+	))
+
+	// Usage is summed per model and records without requests are skipped
+	totals, err := store.Summarize(ctx, Filter{UserID: "user-a"})
+	require.NoError(t, err)
+	assert.Equal(t, []Totals{
+		{Model: "gpt-4.1", Requests: 3, InputTokens: 150, OutputTokens: 30, TotalTokens: 180},
+		{Model: "gpt-4.1-mini", Requests: 1, InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+	}, totals)
+
+	total, err := store.TotalTokens(ctx, "user-a", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(195), total)
+
+	// Periods starting in another time zone count the same records
+	total, err = store.TotalTokens(ctx, "user-a", time.Now().Add(-time.Hour).In(time.FixedZone("UTC+10", 10*60*60)))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(195), total)
+
+	// Nothing is counted outside the period
+	total, err = store.TotalTokens(ctx, "user-a", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	totals, err = store.Summarize(ctx, Filter{Until: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, totals)
+}
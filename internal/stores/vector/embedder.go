@@ -0,0 +1,155 @@
+package vector
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/packages/param"
+)
+
+// DEFAULT_HASH_DIMENSIONS is the number of dimensions used by the hashed bag-of-words embedder
+const DEFAULT_HASH_DIMENSIONS = 512
+
+// Embedder turns text into vectors that can be compared with cosine similarity
+type Embedder interface {
+	// Name identifies the embedder and model, so vectors from different embedders are never compared
+	Name() string
+
+	// Embed returns the vector for a piece of text
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// NewEmbedder creates an embedder by kind ("openai" or "hash"). The model is only used by the OpenAI embedder.
+func NewEmbedder(kind, model string) (Embedder, error) {
+	switch kind {
+	case "openai":
+		return NewOpenAIEmbedder(model), nil
+	case "hash":
+		return NewHashEmbedder(DEFAULT_HASH_DIMENSIONS), nil
+	default:
+		return nil, fmt.Errorf("unknown embedder '%s'", kind)
+	}
+}
+
+// HashEmbedder is a deterministic, offline embedder that hashes words into a fixed number of buckets
+type HashEmbedder struct {
+	dims int
+}
+
+// NewHashEmbedder creates a hashed bag-of-words embedder with the given number of dimensions
+func NewHashEmbedder(dims int) *HashEmbedder {
+	if dims <= 0 {
+		dims = DEFAULT_HASH_DIMENSIONS
+	}
+	return &HashEmbedder{dims: dims}
+}
+
+// Name identifies the embedder
+func (e *HashEmbedder) Name() string {
+	return fmt.Sprintf("hash-%d", e.dims)
+}
+
+// Embed hashes each word of the text into a bucket and normalizes the counts
+func (e *HashEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	vec := make([]float32, e.dims)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		vec[h.Sum32()%uint32(e.dims)]++
+	}
+
+	return normalize(vec), nil
+}
+
+// OpenAIEmbedder embeds text with the OpenAI embeddings API
+type OpenAIEmbedder struct {
+	client openai.Client
+	model  string
+}
+
+// NewOpenAIEmbedder creates an embedder for the given OpenAI model, using OPENAI_API_KEY from the environment
+func NewOpenAIEmbedder(model string) *OpenAIEmbedder {
+	if model == "" {
+		model = string(openai.EmbeddingModelTextEmbedding3Small)
+	}
+
+	return &OpenAIEmbedder{
+		client: openai.NewClient(),
+		model:  model,
+	}
+}
+
+// Name identifies the embedder
+func (e *OpenAIEmbedder) Name() string {
+	return "openai-" + e.model
+}
+
+// Embed requests the vector for the text from OpenAI
+func (e *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	resp, err := e.client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		Input: openai.EmbeddingNewParamsInputUnion{OfString: param.NewOpt(text)},
+		Model: openai.EmbeddingModel(e.model),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding: %w", err)
+	}
+
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("no embedding returned")
+	}
+
+	vec := make([]float32, len(resp.Data[0].Embedding))
+	for i, v := range resp.Data[0].Embedding {
+		vec[i] = float32(v)
+	}
+
+	return normalize(vec), nil
+}
+
+// Cosine returns the cosine similarity of two vectors, or 0 if they can't be compared
+func Cosine(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
+
+// Helper function to scale a vector to unit length
+func normalize(vec []float32) []float32 {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+
+	if sum == 0 {
+		return vec
+	}
+
+	norm := float32(math.Sqrt(sum))
+	for i := range vec {
+		vec[i] /= norm
+	}
+
+	return vec
+}
@@ -0,0 +1,320 @@
+package vector
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Sources of indexed documents
+const (
+	SOURCE_FACT         = "fact"
+	SOURCE_SESSION_ITEM = "session_item"
+)
+
+// DEFAULT_SEARCH_LIMIT is the number of matches returned when no limit is given
+const DEFAULT_SEARCH_LIMIT = 5
+
+// Index stores document vectors and searches them by cosine similarity
+type Index interface {
+	Upsert(ctx context.Context, doc Document) error
+	Delete(ctx context.Context, source, userID, sourceID string) error
+	DeleteSession(ctx context.Context, sessionID string) error
+	Search(ctx context.Context, source, userID, query string, limit int) ([]Match, error)
+}
+
+// Document is a piece of text to index
+type Document struct {
+	Source    string // Kind of document (SOURCE_FACT, SOURCE_SESSION_ITEM)
+	SourceID  string // ID of the document within its source and user
+	UserID    string // User that owns the document
+	SessionID string // Session the document belongs to, if any
+	Content   string // Text that is embedded
+}
+
+// Match is a document found by a search
+type Match struct {
+	Document
+	Score     float32   // Cosine similarity to the query
+	UpdatedAt time.Time // When the document was last indexed
+}
+
+// Embedding is the stored vector for a document
+type Embedding struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+
+	Source    string     `json:"source" gorm:"size:32;not null;uniqueIndex:idx_embedding_source"`
+	UserID    string     `json:"user_id" gorm:"size:255;not null;default:'';uniqueIndex:idx_embedding_source"`
+	SourceID  string     `json:"source_id" gorm:"size:255;not null;uniqueIndex:idx_embedding_source"`
+	SessionID string     `json:"session_id,omitempty" gorm:"size:36;index"`
+	Content   string     `json:"content" gorm:"type:text;not null"`
+	Model     string     `json:"model" gorm:"size:255;not null;index"`
+	Vector    VectorData `json:"-" gorm:"type:mediumtext;not null"`
+}
+
+// TableName specifies the database table name for GORM
+func (*Embedding) TableName() string {
+	return "embeddings"
+}
+
+// VectorData is a wrapper type that implements database serialization
+type VectorData []float32
+
+// Value implements the driver.Valuer interface for database storage
+func (v VectorData) Value() (driver.Value, error) {
+	b, err := json.Marshal([]float32(v))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (v *VectorData) Scan(value any) error {
+	var bytes []byte
+	switch val := value.(type) {
+	case []byte:
+		bytes = val
+	case string:
+		bytes = []byte(val)
+	case nil:
+		*v = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into VectorData", value)
+	}
+
+	return json.Unmarshal(bytes, (*[]float32)(v))
+}
+
+// SqlIndex stores vectors in the 'embeddings' table and searches them in memory
+type SqlIndex struct {
+	db       *gorm.DB
+	embedder Embedder
+}
+
+// NewSqlIndex creates an index on an existing database connection
+func NewSqlIndex(db *gorm.DB, embedder Embedder) (*SqlIndex, error) {
+	if err := db.AutoMigrate(&Embedding{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate tables: %w", err)
+	}
+
+	return &SqlIndex{db: db, embedder: embedder}, nil
+}
+
+// Upsert embeds and stores a document, skipping the embedder if the content hasn't changed
+func (i *SqlIndex) Upsert(ctx context.Context, doc Document) error {
+	var existing Embedding
+	err := i.db.WithContext(ctx).Where("source = ? AND user_id = ? AND source_id = ?", doc.Source, doc.UserID, doc.SourceID).First(&existing).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return fmt.Errorf("failed to check existing embedding: %w", err)
+	}
+
+	if err == nil && existing.Content == doc.Content && existing.Model == i.embedder.Name() {
+		return nil
+	}
+
+	vec, err := i.embedder.Embed(ctx, doc.Content)
+	if err != nil {
+		return err
+	}
+
+	existing.Source = doc.Source
+	existing.UserID = doc.UserID
+	existing.SourceID = doc.SourceID
+	existing.SessionID = doc.SessionID
+	existing.Content = doc.Content
+	existing.Model = i.embedder.Name()
+	existing.Vector = vec
+
+	if err := i.db.WithContext(ctx).Save(&existing).Error; err != nil {
+		return fmt.Errorf("failed to save embedding: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a document from the index
+func (i *SqlIndex) Delete(ctx context.Context, source, userID, sourceID string) error {
+	if err := i.db.WithContext(ctx).Where("source = ? AND user_id = ? AND source_id = ?", source, userID, sourceID).Delete(&Embedding{}).Error; err != nil {
+		return fmt.Errorf("failed to delete embedding: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSession removes every document belonging to a session from the index
+func (i *SqlIndex) DeleteSession(ctx context.Context, sessionID string) error {
+	if err := i.db.WithContext(ctx).Where("session_id = ?", sessionID).Delete(&Embedding{}).Error; err != nil {
+		return fmt.Errorf("failed to delete session embeddings: %w", err)
+	}
+
+	return nil
+}
+
+// Search returns the documents from a source most similar to the query. If userID is empty, documents from every user are searched.
+func (i *SqlIndex) Search(ctx context.Context, source, userID, query string, limit int) ([]Match, error) {
+	vec, err := i.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only vectors from the current embedder can be compared
+	db := i.db.WithContext(ctx).Where("source = ? AND model = ?", source, i.embedder.Name())
+	if userID != "" {
+		db = db.Where("user_id = ?", userID)
+	}
+
+	var embeddings []*Embedding
+	if err := db.Find(&embeddings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load embeddings: %w", err)
+	}
+
+	return rank(vec, embeddings, limit), nil
+}
+
+// InMemoryIndex keeps vectors in memory (for one-off operations and tests)
+type InMemoryIndex struct {
+	embedder   Embedder
+	embeddings map[string]*Embedding // source/userID/sourceID -> embedding
+	mu         sync.RWMutex
+}
+
+// NewInMemoryIndex creates an empty in-memory index
+func NewInMemoryIndex(embedder Embedder) *InMemoryIndex {
+	return &InMemoryIndex{
+		embedder:   embedder,
+		embeddings: make(map[string]*Embedding),
+	}
+}
+
+// Upsert embeds and stores a document
+func (i *InMemoryIndex) Upsert(ctx context.Context, doc Document) error {
+	vec, err := i.embedder.Embed(ctx, doc.Content)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	now := time.Now().UTC()
+	i.embeddings[embeddingKey(doc.Source, doc.UserID, doc.SourceID)] = &Embedding{
+		CreatedAt: now,
+		UpdatedAt: now,
+		Source:    doc.Source,
+		UserID:    doc.UserID,
+		SourceID:  doc.SourceID,
+		SessionID: doc.SessionID,
+		Content:   doc.Content,
+		Model:     i.embedder.Name(),
+		Vector:    vec,
+	}
+
+	return nil
+}
+
+// Delete removes a document from the index
+func (i *InMemoryIndex) Delete(ctx context.Context, source, userID, sourceID string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.embeddings, embeddingKey(source, userID, sourceID))
+	return nil
+}
+
+// DeleteSession removes every document belonging to a session from the index
+func (i *InMemoryIndex) DeleteSession(ctx context.Context, sessionID string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for key, embedding := range i.embeddings {
+		if embedding.SessionID == sessionID {
+			delete(i.embeddings, key)
+		}
+	}
+	return nil
+}
+
+// Search returns the documents from a source most similar to the query. If userID is empty, documents from every user are searched.
+func (i *InMemoryIndex) Search(ctx context.Context, source, userID, query string, limit int) ([]Match, error) {
+	vec, err := i.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var embeddings []*Embedding
+	for _, embedding := range i.embeddings {
+		if embedding.Source == source && (userID == "" || embedding.UserID == userID) {
+			embeddings = append(embeddings, embedding)
+		}
+	}
+
+	return rank(vec, embeddings, limit), nil
+}
+
+// Helper function to build the in-memory key for a document
+func embeddingKey(source, userID, sourceID string) string {
+	return source + "/" + userID + "/" + sourceID
+}
+
+// Helper function to score embeddings against a query vector and keep the best matches
+func rank(query []float32, embeddings []*Embedding, limit int) []Match {
+	if limit <= 0 {
+		limit = DEFAULT_SEARCH_LIMIT
+	}
+
+	matches := []Match{}
+	for _, embedding := range embeddings {
+		score := Cosine(query, embedding.Vector)
+		if score <= 0 {
+			continue
+		}
+
+		matches = append(matches, Match{
+			Document: Document{
+				Source:    embedding.Source,
+				SourceID:  embedding.SourceID,
+				UserID:    embedding.UserID,
+				SessionID: embedding.SessionID,
+				Content:   embedding.Content,
+			},
+			Score:     score,
+			UpdatedAt: embedding.UpdatedAt,
+		})
+	}
+
+	// Highest score first, ties broken by source ID so results are stable
+	slices.SortFunc(matches, func(a, b Match) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		if a.SourceID < b.SourceID {
+			return -1
+		}
+		if a.SourceID > b.SourceID {
+			return 1
+		}
+		return 0
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
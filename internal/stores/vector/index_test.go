@@ -0,0 +1,106 @@
+package vector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethanbaker/assistant/internal/stores/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashEmbedder(t *testing.T) {
+	ctx := context.Background()
+	embedder := NewHashEmbedder(64)
+
+	a, err := embedder.Embed(ctx, "My favorite color is blue")
+	require.NoError(t, err)
+	b, err := embedder.Embed(ctx, "my FAVORITE color is blue!")
+	require.NoError(t, err)
+	c, err := embedder.Embed(ctx, "dentist appointment tomorrow")
+	require.NoError(t, err)
+
+	// Embeddings are deterministic and ignore case and punctuation
+	assert.Len(t, a, 64)
+	assert.Equal(t, a, b)
+	assert.InDelta(t, 1.0, Cosine(a, b), 1e-6)
+
+	// Unrelated text is less similar
+	assert.Less(t, Cosine(a, c), Cosine(a, b))
+}
+
+func TestCosine(t *testing.T) {
+	assert.InDelta(t, 1.0, Cosine([]float32{1, 0}, []float32{2, 0}), 1e-6)
+	assert.InDelta(t, 0.0, Cosine([]float32{1, 0}, []float32{0, 1}), 1e-6)
+	assert.InDelta(t, -1.0, Cosine([]float32{1, 0}, []float32{-1, 0}), 1e-6)
+
+	// Vectors that can't be compared
+	assert.Equal(t, float32(0), Cosine([]float32{1}, []float32{1, 0}))
+	assert.Equal(t, float32(0), Cosine([]float32{0, 0}, []float32{1, 0}))
+}
+
+func TestIndexSearch(t *testing.T) {
+	embedder := NewHashEmbedder(DEFAULT_HASH_DIMENSIONS)
+
+	db, err := database.Open("sqlite://:memory:")
+	require.NoError(t, err)
+	sqlIndex, err := NewSqlIndex(db, embedder)
+	require.NoError(t, err)
+
+	indexes := map[string]Index{
+		"in-memory": NewInMemoryIndex(embedder),
+		"sql":       sqlIndex,
+	}
+	for name, index := range indexes {
+		t.Run(name, func(t *testing.T) {
+			testIndexSearch(t, index)
+		})
+	}
+}
+
+func testIndexSearch(t *testing.T, index Index) {
+	ctx := context.Background()
+
+	docs := []Document{
+		{Source: SOURCE_FACT, UserID: "alice", SourceID: "favorite_color", Content: "favorite_color: my favorite color is blue"},
+		{Source: SOURCE_FACT, UserID: "alice", SourceID: "dentist", Content: "dentist: dentist appointment on friday"},
+		{Source: SOURCE_FACT, UserID: "bob", SourceID: "favorite_color", Content: "favorite_color: my favorite color is green"},
+		{Source: SOURCE_SESSION_ITEM, UserID: "alice", SourceID: "1", SessionID: "session", Content: "user: what color should I paint the room"},
+	}
+	for _, doc := range docs {
+		require.NoError(t, index.Upsert(ctx, doc))
+	}
+
+	// Best match first, limited to the requested source and user
+	matches, err := index.Search(ctx, SOURCE_FACT, "alice", "which color do I like", 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "favorite_color", matches[0].SourceID)
+	assert.Contains(t, matches[0].Content, "blue")
+	for _, match := range matches {
+		assert.Equal(t, SOURCE_FACT, match.Source)
+		assert.Equal(t, "alice", match.UserID)
+	}
+
+	// Searching without a user includes every user's documents
+	matches, err = index.Search(ctx, SOURCE_FACT, "", "favorite color", 5)
+	require.NoError(t, err)
+	assert.Len(t, matches, 2)
+
+	// Deleted documents are no longer found, and other users' documents are untouched
+	require.NoError(t, index.Delete(ctx, SOURCE_FACT, "alice", "favorite_color"))
+	matches, err = index.Search(ctx, SOURCE_FACT, "alice", "which color do I like", 5)
+	require.NoError(t, err)
+	for _, match := range matches {
+		assert.NotEqual(t, "favorite_color", match.SourceID)
+	}
+	matches, err = index.Search(ctx, SOURCE_FACT, "bob", "which color do I like", 5)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+
+	// Deleting a session removes its documents
+	require.NoError(t, index.DeleteSession(ctx, "session"))
+	matches, err = index.Search(ctx, SOURCE_SESSION_ITEM, "", "paint the room", 5)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
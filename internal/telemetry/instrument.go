@@ -0,0 +1,92 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// InstrumentAgent wraps an agent's function tools and handoffs so every call is traced, its latency and errors
+// are recorded under the agent's ID, and it is reported to the context's Observer. It should be called once per
+// agent, after its tools and handoffs are set.
+func InstrumentAgent(agent *agents.Agent, agentID string) {
+	for i, tool := range agent.Tools {
+		switch t := tool.(type) {
+		case agents.FunctionTool:
+			agent.Tools[i] = instrumentTool(t, agentID)
+		case *agents.FunctionTool:
+			wrapped := instrumentTool(*t, agentID)
+			agent.Tools[i] = &wrapped
+		}
+	}
+
+	for i, handoff := range agent.Handoffs {
+		agent.Handoffs[i] = instrumentHandoff(handoff, agentID)
+	}
+}
+
+// Helper function to wrap a function tool's invocation with a span and metrics
+func instrumentTool(tool agents.FunctionTool, agentID string) agents.FunctionTool {
+	invoke := tool.OnInvokeTool
+	if invoke == nil {
+		return tool
+	}
+
+	tool.OnInvokeTool = func(ctx context.Context, arguments string) (any, error) {
+		ctx, span := StartSpan(ctx, "agent.tool",
+			attribute.String("agent.id", agentID),
+			attribute.String("tool.name", tool.Name),
+		)
+
+		start := time.Now()
+		result, err := invoke(ctx, arguments)
+		duration := time.Since(start)
+
+		ObserveTool(agentID, tool.Name, duration, err)
+		EndSpan(span, err)
+
+		if observer := observerFrom(ctx); observer != nil {
+			observer.ObserveToolCall(ToolCall{
+				AgentID:   agentID,
+				Tool:      tool.Name,
+				Arguments: arguments,
+				Result:    result,
+				Error:     err,
+				StartedAt: start,
+				Duration:  duration,
+			})
+		}
+
+		return result, err
+	}
+
+	return tool
+}
+
+// Helper function to wrap a handoff's invocation with a span
+func instrumentHandoff(handoff agents.Handoff, agentID string) agents.Handoff {
+	invoke := handoff.OnInvokeHandoff
+	if invoke == nil {
+		return handoff
+	}
+
+	handoff.OnInvokeHandoff = func(ctx context.Context, arguments string) (*agents.Agent, error) {
+		ctx, span := StartSpan(ctx, "agent.handoff",
+			attribute.String("agent.id", agentID),
+			attribute.String("handoff.target", handoff.AgentName),
+		)
+
+		target, err := invoke(ctx, arguments)
+		EndSpan(span, err)
+
+		if observer := observerFrom(ctx); observer != nil && err == nil {
+			observer.ObserveHandoff(agentID, handoff.AgentName)
+		}
+
+		return target, err
+	}
+
+	return handoff
+}
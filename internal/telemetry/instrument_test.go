@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentAgent(t *testing.T) {
+	failure := errors.New("tool failed")
+	target := agents.New("target-agent")
+
+	agent := agents.New("test-agent").
+		WithTools(
+			agents.FunctionTool{
+				Name: "ok_tool",
+				OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
+					return "ok: " + arguments, nil
+				},
+			},
+			agents.FunctionTool{
+				Name: "failing_tool",
+				OnInvokeTool: func(ctx context.Context, arguments string) (any, error) {
+					return nil, failure
+				},
+			},
+		).
+		WithHandoffs(agents.HandoffFromAgent(agents.HandoffFromAgentParams{Agent: target}))
+
+	InstrumentAgent(agent, "test-agent")
+
+	t.Run("successful tool", func(t *testing.T) {
+		result, err := agent.Tools[0].(agents.FunctionTool).OnInvokeTool(context.Background(), "args")
+		require.NoError(t, err)
+		assert.Equal(t, "ok: args", result)
+		assert.Equal(t, float64(0), testutil.ToFloat64(toolErrors.WithLabelValues("test-agent", "ok_tool")))
+	})
+
+	t.Run("failing tool", func(t *testing.T) {
+		_, err := agent.Tools[1].(agents.FunctionTool).OnInvokeTool(context.Background(), "")
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, float64(1), testutil.ToFloat64(toolErrors.WithLabelValues("test-agent", "failing_tool")))
+	})
+
+	t.Run("handoff", func(t *testing.T) {
+		next, err := agent.Handoffs[0].OnInvokeHandoff(context.Background(), "")
+		require.NoError(t, err)
+		assert.Same(t, target, next)
+	})
+}
@@ -0,0 +1,64 @@
+package telemetry
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Outcomes a run is labelled with
+const (
+	STATUS_OK    = "ok"
+	STATUS_ERROR = "error"
+)
+
+var (
+	runDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "assistant",
+		Subsystem: "agent",
+		Name:      "run_duration_seconds",
+		Help:      "Duration of agent runs, from the user's message to the final output",
+		Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120, 300},
+	}, []string{"agent", "status"})
+
+	toolDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "assistant",
+		Subsystem: "agent",
+		Name:      "tool_duration_seconds",
+		Help:      "Duration of agent tool calls",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"agent", "tool"})
+
+	toolErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "assistant",
+		Subsystem: "agent",
+		Name:      "tool_errors_total",
+		Help:      "Number of agent tool calls that returned an error",
+	}, []string{"agent", "tool"})
+)
+
+// ObserveRun records the duration and outcome of an agent run
+func ObserveRun(agentID string, duration time.Duration, err error) {
+	status := STATUS_OK
+	if err != nil {
+		status = STATUS_ERROR
+	}
+
+	runDuration.WithLabelValues(agentID, status).Observe(duration.Seconds())
+}
+
+// ObserveTool records the duration of a tool call, counting it as an error if err is set
+func ObserveTool(agentID, tool string, duration time.Duration, err error) {
+	toolDuration.WithLabelValues(agentID, tool).Observe(duration.Seconds())
+	if err != nil {
+		toolErrors.WithLabelValues(agentID, tool).Inc()
+	}
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,38 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+)
+
+// observerKey is the context key an Observer is stored under
+type observerKey struct{}
+
+// ToolCall describes a finished call to an instrumented function tool
+type ToolCall struct {
+	AgentID   string
+	Tool      string
+	Arguments string
+	Result    any
+	Error     error
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// Observer is told about the tool calls and handoffs made by instrumented agents while its context is in use.
+// Tools may run concurrently, so implementations must be safe for concurrent use
+type Observer interface {
+	ObserveToolCall(call ToolCall)
+	ObserveHandoff(fromAgentID, toAgentID string)
+}
+
+// WithObserver returns a context that reports the calls made with it to an observer
+func WithObserver(ctx context.Context, observer Observer) context.Context {
+	return context.WithValue(ctx, observerKey{}, observer)
+}
+
+// observerFrom is a helper to get the observer of a context, if any
+func observerFrom(ctx context.Context) Observer {
+	observer, _ := ctx.Value(observerKey{}).(Observer)
+	return observer
+}
@@ -0,0 +1,78 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TRACER_NAME is the instrumentation name every span is created under
+const TRACER_NAME = "github.com/ethanbaker/assistant"
+
+// InitTracing exports spans over OTLP/HTTP when OTEL_ENABLED is set. Otherwise the global no-op tracer
+// provider is kept and spans are dropped. The returned function flushes and stops the exporter.
+func InitTracing(ctx context.Context, cfg *utils.Config) (func(context.Context) error, error) {
+	// Trace context is propagated to outgoing requests either way
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if !cfg.GetBoolWithDefault("OTEL_ENABLED", false) {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// The exporter reads the standard OTEL_EXPORTER_OTLP_* environment variables; an endpoint in the
+	// config takes precedence
+	var opts []otlptracehttp.Option
+	if endpoint := cfg.Get("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.GetWithDefault("OTEL_SERVICE_NAME", "assistant")),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer used for the assistant's spans
+func Tracer() trace.Tracer {
+	return otel.Tracer(TRACER_NAME)
+}
+
+// StartSpan starts a span with the given attributes
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan marks a span as failed if err is set, then ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,45 @@
+package agent
+
+import "context"
+
+// contextKey is the type of keys for values agents read from the run context
+type contextKey string
+
+const (
+	userIDKey    contextKey = "user_id"    // ID of the user an agent is acting for
+	sessionIDKey contextKey = "session_id" // ID of the session an agent is running in
+	clientIDKey  contextKey = "client_id"  // ID of the outreach implementation the user is talking through
+)
+
+// WithUserID returns a context carrying the ID of the user an agent is acting for
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext returns the ID of the user an agent is acting for, if one is set
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDKey).(string)
+	return userID, ok && userID != ""
+}
+
+// WithSessionID returns a context carrying the ID of the session an agent is running in
+func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDKey, sessionID)
+}
+
+// SessionIDFromContext returns the ID of the session an agent is running in, if one is set
+func SessionIDFromContext(ctx context.Context) (string, bool) {
+	sessionID, ok := ctx.Value(sessionIDKey).(string)
+	return sessionID, ok && sessionID != ""
+}
+
+// WithClientID returns a context carrying the ID of the outreach implementation the user is talking through
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDKey, clientID)
+}
+
+// ClientIDFromContext returns the ID of the outreach implementation the user is talking through, if one is set
+func ClientIDFromContext(ctx context.Context) (string, bool) {
+	clientID, ok := ctx.Value(clientIDKey).(string)
+	return clientID, ok && clientID != ""
+}
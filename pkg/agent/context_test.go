@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test reading the acting user from the run context
+func TestUserIDFromContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "user set",
+			ctx:    WithUserID(context.Background(), "user-1"),
+			want:   "user-1",
+			wantOk: true,
+		},
+		{
+			name:   "no user",
+			ctx:    context.Background(),
+			want:   "",
+			wantOk: false,
+		},
+		{
+			name:   "empty user",
+			ctx:    WithUserID(context.Background(), ""),
+			want:   "",
+			wantOk: false,
+		},
+		{
+			name:   "plain string key is ignored",
+			ctx:    context.WithValue(context.Background(), "user_id", "user-1"),
+			want:   "",
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := UserIDFromContext(tt.ctx)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+}
+
+// Test reading the current session from the run context
+func TestSessionIDFromContext(t *testing.T) {
+	ctx := WithSessionID(context.Background(), "session-1")
+	got, ok := SessionIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "session-1", got)
+
+	// User and session values don't collide
+	_, ok = UserIDFromContext(ctx)
+	assert.False(t, ok)
+
+	_, ok = SessionIDFromContext(context.Background())
+	assert.False(t, ok)
+}
@@ -1,5 +1,7 @@
 package outreach
 
+import "time"
+
 /** Cadence handles scheduling repeat tasks */
 
 // CadenceType represents the type of scheduling cadence for a task
@@ -9,17 +11,35 @@ const (
 	// CronCadence schedules tasks using cron expressions
 	CronCadence CadenceType = "cron"
 
-	// SunriseCadence schedules tasks to run at local sunrise
+	// SunriseCadence schedules tasks to run at local sunrise. Optional params are 'offset' (e.g. "-30m"),
+	// 'timezone', and 'latitude'/'longitude' overriding the manager's options
 	SunriseCadence CadenceType = "sunrise"
 
-	// SunsetCadence schedules tasks to run at local sunset
+	// SunsetCadence schedules tasks to run at local sunset, taking the same params as SunriseCadence
 	SunsetCadence CadenceType = "sunset"
+
+	// OnceCadence runs a task a single time at the RFC 3339 'at' param, then removes it
+	OnceCadence CadenceType = "once"
+
+	// IntervalCadence runs a task every 'every' param (e.g. "2h"), with an optional random 'jitter'
+	IntervalCadence CadenceType = "interval"
+
+	// RRuleCadence runs a task on an RFC 5545 recurrence 'rule' param (e.g. "FREQ=WEEKLY;BYDAY=MO;BYHOUR=9"),
+	// with an optional 'timezone'
+	RRuleCadence CadenceType = "rrule"
+
+	// BeforeCalendarEventCadence runs a task 'before' (e.g. "10m") calendar events, optionally limited to a
+	// 'calendar' and to events whose title contains 'filter'
+	BeforeCalendarEventCadence CadenceType = "before_calendar_event"
 )
 
+// MIN_TASK_INTERVAL is the shortest interval an interval task can run at
+const MIN_TASK_INTERVAL = time.Minute
+
 // ValidateCadenceType checks if the given cadence type is valid
 func ValidateCadenceType(cadence CadenceType) bool {
 	switch cadence {
-	case CronCadence, SunriseCadence, SunsetCadence:
+	case CronCadence, SunriseCadence, SunsetCadence, OnceCadence, IntervalCadence, RRuleCadence, BeforeCalendarEventCadence:
 		return true
 	default:
 		return false
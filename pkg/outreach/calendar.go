@@ -0,0 +1,104 @@
+package outreach
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// CALENDAR_REFRESH_INTERVAL is how far ahead calendar-relative tasks look for events each time they check the calendar
+	CALENDAR_REFRESH_INTERVAL = 15 * time.Minute
+
+	// CALENDAR_REQUEST_TIMEOUT bounds each calendar lookup
+	CALENDAR_REQUEST_TIMEOUT = 30 * time.Second
+
+	// DEFAULT_CALENDAR_EVENT_LEAD is how long before an event a task runs if no 'before' parameter is given
+	DEFAULT_CALENDAR_EVENT_LEAD = 15 * time.Minute
+)
+
+// CalendarEvent is a timed calendar event that tasks can be scheduled relative to
+type CalendarEvent struct {
+	ID    string
+	Title string
+	Start time.Time
+}
+
+// CalendarSource lists calendar events for the before_calendar_event cadence
+type CalendarSource interface {
+	// ListEvents returns the timed events overlapping a time range. If calendarName is empty, all calendars are searched
+	ListEvents(ctx context.Context, start, end time.Time, calendarName string) ([]CalendarEvent, error)
+}
+
+// calendarSchedule runs a task a fixed time before calendar events that match a filter
+type calendarSchedule struct {
+	ctx      context.Context
+	source   CalendarSource
+	before   time.Duration // How long before each event to run
+	calendar string        // Calendar to search, or all calendars if empty
+	filter   string        // Case-insensitive text the event title must contain, or any event if empty
+}
+
+// newCalendarSchedule parses a calendar-relative task's parameters: 'before' (a duration or minutes),
+// and the optional 'calendar' name and 'filter' text matched against event titles
+func newCalendarSchedule(ctx context.Context, task *Task, opts *ManagerOptions) (*calendarSchedule, error) {
+	if opts.Calendar == nil {
+		return nil, fmt.Errorf("no calendar is configured for calendar event tasks")
+	}
+
+	before, err := durationParam(task.CadenceParams, "before", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if _, set := task.CadenceParams["before"]; !set {
+		before = DEFAULT_CALENDAR_EVENT_LEAD
+	}
+	if before < 0 {
+		return nil, fmt.Errorf("'before' cannot be negative")
+	}
+
+	schedule := &calendarSchedule{
+		ctx:    ctx,
+		source: opts.Calendar,
+		before: before,
+	}
+	schedule.calendar, _ = task.CadenceParams["calendar"].(string)
+	schedule.filter, _ = task.CadenceParams["filter"].(string)
+
+	return schedule, nil
+}
+
+// next looks for matching events whose run time falls in the next refresh interval.
+// If there are none, it asks to check the calendar again at the end of the interval.
+func (s *calendarSchedule) next(after time.Time) (time.Time, bool) {
+	end := after.Add(CALENDAR_REFRESH_INTERVAL)
+
+	ctx, cancel := context.WithTimeout(s.ctx, CALENDAR_REQUEST_TIMEOUT)
+	defer cancel()
+
+	// The range end is exclusive, so reach just past it to include runs at the end of the interval
+	events, err := s.source.ListEvents(ctx, after.Add(s.before), end.Add(s.before+time.Second), s.calendar)
+	if err != nil {
+		log.Printf("[OUTREACH]: Failed to list calendar events: %v", err)
+		return end, false
+	}
+
+	var best time.Time
+	for _, event := range events {
+		if s.filter != "" && !strings.Contains(strings.ToLower(event.Title), strings.ToLower(s.filter)) {
+			continue
+		}
+
+		run := event.Start.Add(-s.before)
+		if run.After(after) && !run.After(end) && (best.IsZero() || run.Before(best)) {
+			best = run
+		}
+	}
+
+	if best.IsZero() {
+		return end, false
+	}
+	return best, true
+}
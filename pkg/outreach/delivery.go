@@ -0,0 +1,99 @@
+package outreach
+
+import (
+	"errors"
+	"time"
+)
+
+/** Delivery tracks sending task responses to implementations */
+
+// ErrDeliveryNotFound is returned when a delivery doesn't exist
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
+// ErrDeliveryInProgress is returned when replaying a delivery that is still being sent
+var ErrDeliveryInProgress = errors.New("delivery is still in progress")
+
+// DeliveryStatus represents where a response is in the delivery process
+type DeliveryStatus string
+
+const (
+	// DeliveryPending responses are waiting for their first attempt
+	DeliveryPending DeliveryStatus = "pending"
+
+	// DeliveryRetrying responses failed at least once and are waiting to be retried
+	DeliveryRetrying DeliveryStatus = "retrying"
+
+	// DeliveryDelivered responses were accepted by one of their clients
+	DeliveryDelivered DeliveryStatus = "delivered"
+
+	// DeliveryDead responses ran out of retries and were moved to the dead-letter table
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// ValidateDeliveryStatus checks if the given delivery status is valid
+func ValidateDeliveryStatus(status DeliveryStatus) bool {
+	switch status {
+	case DeliveryPending, DeliveryRetrying, DeliveryDelivered, DeliveryDead:
+		return true
+	default:
+		return false
+	}
+}
+
+// Delivery is a persisted response and the state of sending it to its clients
+type Delivery struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Response *Response      `json:"response"`
+	Status   DeliveryStatus `json:"status"`
+
+	Rounds        int        `json:"rounds"`          // Rounds through the client list since the delivery was created or replayed
+	Attempts      int        `json:"attempts"`        // Total attempts made, across all clients and replays
+	NextAttemptAt *time.Time `json:"next_attempt_at"` // When the next round is due, if the delivery is pending or retrying
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	DeliveredTo   string     `json:"delivered_to"` // Client ID that accepted the response
+	LastError     string     `json:"last_error"`
+}
+
+// DeliveryAttempt records a single try at sending a response to one client
+type DeliveryAttempt struct {
+	ID         uint          `json:"id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	DeliveryID uint          `json:"delivery_id"`
+	ClientID   string        `json:"client_id"`
+	StatusCode int           `json:"status_code"` // 0 if no response was received
+	Error      string        `json:"error"`
+	Latency    time.Duration `json:"latency"`
+}
+
+// DeadLetter records a delivery that ran out of retries
+type DeadLetter struct {
+	ID         uint       `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DeliveryID uint       `json:"delivery_id"`
+	Reason     string     `json:"reason"`
+	ReplayedAt *time.Time `json:"replayed_at"` // Set once the delivery has been replayed
+}
+
+// DeliveryFilter narrows the deliveries returned by ListDeliveries
+type DeliveryFilter struct {
+	Status   DeliveryStatus // Optional status to match
+	ClientID string         // Optional client the response must be sent to
+	Limit    int            // Maximum number of deliveries, 0 for no limit
+	Offset   int
+}
+
+// DeliveryStoreInterface defines the storage operations for response deliveries
+type DeliveryStoreInterface interface {
+	CreateDelivery(response *Response) (*Delivery, error)
+	GetDelivery(id uint) (*Delivery, error)
+	ListDeliveries(filter DeliveryFilter) ([]*Delivery, error)
+	UpdateDelivery(delivery *Delivery) error
+	RecordDeliveryAttempt(attempt *DeliveryAttempt) error
+	ListDeliveryAttempts(deliveryID uint) ([]*DeliveryAttempt, error)
+	DeadLetterDelivery(id uint, reason string) error
+	ListDeadLetters(deliveryID uint) ([]*DeadLetter, error)
+	ReplayDelivery(id uint) (*Delivery, error)
+}
@@ -2,16 +2,24 @@ package outreach
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"runtime/debug"
 	"sync"
 	"time"
 
 	"github.com/ethanbaker/assistant/pkg/utils"
-	"github.com/nathan-osman/go-sunrise"
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer creates the spans for scheduled task runs
+var tracer = otel.Tracer("github.com/ethanbaker/assistant/pkg/outreach")
+
 // Manager handles scheduling and execution of outreach tasks
 type Manager struct {
 	// Implementations and tasks
@@ -21,22 +29,32 @@ type Manager struct {
 	cfg         *utils.Config
 
 	// Concurrency
-	mutex  sync.RWMutex
-	ctx    context.Context
-	cancel context.CancelFunc
+	mutex    sync.RWMutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	inFlight sync.WaitGroup // Task runs that may still send to responsesCh
 
 	// Scheduling
-	sunTicker *time.Ticker
-	cron      *cron.Cron
-	opts      *ManagerOptions
+	timers      map[string]*taskTimer // Pending timer for each scheduled non-cron task, by task key
+	cron        *cron.Cron
+	cronEntries map[string]cron.EntryID // Cron entry for each scheduled cron task, by task key
+	opts        *ManagerOptions
+
+	// Run history
+	runs   map[string][]*TaskRun // Most recent runs by task key, oldest first
+	runSeq uint64
 }
 
 // ManagerOptions contains configuration options for the Manager
 type ManagerOptions struct {
-	Store StoreInterface `json:"-" yaml:"-"`
+	Store         StoreInterface             `json:"-" yaml:"-"`
+	TaskFunctions map[string]TaskRunFunction `json:"-" yaml:"-"` // Run functions by task type
 
 	Latitude  float64 `json:"latitude" yaml:"latitude"`   // Latitude for sunrise/sunset calculations
 	Longitude float64 `json:"longitude" yaml:"longitude"` // Longitude for sunrise/sunset calculations
+	Timezone  string  `json:"timezone" yaml:"timezone"`   // IANA timezone for sun and rrule tasks (defaults to the server's)
+
+	Calendar CalendarSource `json:"-" yaml:"-"` // Calendar for before_calendar_event tasks
 }
 
 // NewManager creates a new outreach manager
@@ -60,7 +78,9 @@ func NewManager(cfg *utils.Config, opts *ManagerOptions) (*Manager, error) {
 		ctx:         ctx,
 		cancel:      cancel,
 		cron:        cron.New(),
-		sunTicker:   time.NewTicker(1 * time.Minute),
+		cronEntries: make(map[string]cron.EntryID),
+		runs:        make(map[string][]*TaskRun),
+		timers:      make(map[string]*taskTimer),
 		opts:        opts,
 		cfg:         cfg,
 	}
@@ -74,31 +94,50 @@ func NewManager(cfg *utils.Config, opts *ManagerOptions) (*Manager, error) {
 // start begins the manager's background operations
 func (m *Manager) start() {
 	m.cron.Start()
-
-	// Start sunrise/sunset ticker in a goroutine
-	go m.handleSunEvents()
 }
 
-// Stop gracefully stops the manager
+// Stop gracefully stops the manager. Runs that have already started are waited on before the response channel is closed.
 func (m *Manager) Stop() {
 	m.cancel()
-	m.cron.Stop()
-	m.sunTicker.Stop()
+	<-m.cron.Stop().Done()
+
+	// No run can start once the context is cancelled and the mutex has been held
+	m.mutex.Lock()
+	for key := range m.timers {
+		m.unscheduleTask(key)
+	}
+	m.mutex.Unlock()
+
+	m.inFlight.Wait()
 	close(m.responsesCh)
 }
 
+// beginRun registers a task run with the manager, failing if the manager has been stopped
+func (m *Manager) beginRun() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.ctx.Err() != nil {
+		return false
+	}
+
+	m.inFlight.Add(1)
+	return true
+}
+
 // GetResponseChannel returns the channel for receiving task responses
 func (m *Manager) GetResponseChannel() <-chan *Response {
 	return m.responsesCh
 }
 
-// RegisterImplementation registers a new implementation with the manager
-func (m *Manager) RegisterImplementation(req *RegisterRequest) error {
+// RegisterImplementation registers a new implementation with the manager.
+// The returned implementation holds the signing key issued for this registration.
+func (m *Manager) RegisterImplementation(req *RegisterRequest) (*Implementation, error) {
 	if req.ClientId == "" {
-		return fmt.Errorf("client_id cannot be empty")
+		return nil, fmt.Errorf("client_id cannot be empty")
 	}
 	if req.CallbackUrl == "" {
-		return fmt.Errorf("callback_url cannot be empty")
+		return nil, fmt.Errorf("callback_url cannot be empty")
 	}
 
 	impl := &Implementation{
@@ -108,7 +147,11 @@ func (m *Manager) RegisterImplementation(req *RegisterRequest) error {
 		Active:       true,
 	}
 
-	return m.store.SaveImplementation(impl)
+	if err := m.store.SaveImplementation(impl); err != nil {
+		return nil, err
+	}
+
+	return m.store.GetImplementation(req.ClientId)
 }
 
 // UnregisterImplementation removes an implementation from the manager
@@ -132,7 +175,23 @@ func (m *Manager) AuthenticateImplementation(clientID, clientSecret string) (*Im
 	return m.store.AuthenticateImplementation(clientID, clientSecret)
 }
 
-// LoadTasks registers a list of tasks with the manager
+// RotateImplementationSecret replaces an implementation's client secret, accepting the old secret until the grace period ends
+func (m *Manager) RotateImplementationSecret(clientID, newSecret string, gracePeriod time.Duration) (*Implementation, error) {
+	if clientID == "" {
+		return nil, fmt.Errorf("client_id cannot be empty")
+	}
+	if newSecret == "" {
+		return nil, fmt.Errorf("new client_secret cannot be empty")
+	}
+	if gracePeriod < 0 {
+		return nil, fmt.Errorf("grace period cannot be negative")
+	}
+
+	return m.store.RotateSecret(clientID, newSecret, gracePeriod)
+}
+
+// LoadTasks registers a list of tasks with the manager without saving them to the store.
+// Tasks replace any loaded task with the same key.
 func (m *Manager) LoadTasks(tasks []*Task) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -146,101 +205,340 @@ func (m *Manager) LoadTasks(tasks []*Task) error {
 	return nil
 }
 
-// loadTask registers a single task (called with mutex held)
+// LoadStoredTasks registers the tasks saved in the store, replacing loaded tasks with the same key
+func (m *Manager) LoadStoredTasks() (int, error) {
+	tasks, err := m.store.ListTasks()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list stored tasks: %w", err)
+	}
+
+	if err := m.LoadTasks(tasks); err != nil {
+		return 0, err
+	}
+
+	return len(tasks), nil
+}
+
+// AddTask registers a new task and saves it to the store
+func (m *Manager) AddTask(task *Task) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.tasks[task.Key]; exists {
+		return fmt.Errorf("task '%s': %w", task.Key, ErrTaskExists)
+	}
+
+	return m.saveTask(task.Clone())
+}
+
+// UpdateTask replaces a loaded task and saves it to the store
+func (m *Manager) UpdateTask(task *Task) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.tasks[task.Key]; !exists {
+		return fmt.Errorf("task '%s': %w", task.Key, ErrTaskNotFound)
+	}
+
+	return m.saveTask(task.Clone())
+}
+
+// RemoveTask unschedules a task and deletes it from the store.
+// Tasks from the configuration file are loaded again on the next restart.
+func (m *Manager) RemoveTask(key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.tasks[key]; !exists {
+		return fmt.Errorf("task '%s': %w", key, ErrTaskNotFound)
+	}
+
+	if err := m.store.DeleteTask(key); err != nil && !errors.Is(err, ErrTaskNotFound) {
+		return fmt.Errorf("failed to delete task '%s': %w", key, err)
+	}
+
+	m.unscheduleTask(key)
+	delete(m.tasks, key)
+	delete(m.runs, key)
+	return nil
+}
+
+// PauseTask stops a task from running until it is resumed
+func (m *Manager) PauseTask(key string) error {
+	return m.setTaskPaused(key, true)
+}
+
+// ResumeTask schedules a paused task again
+func (m *Manager) ResumeTask(key string) error {
+	return m.setTaskPaused(key, false)
+}
+
+// GetTask returns a copy of a loaded task
+func (m *Manager) GetTask(key string) (*Task, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	task, exists := m.tasks[key]
+	if !exists {
+		return nil, fmt.Errorf("task '%s': %w", key, ErrTaskNotFound)
+	}
+
+	return task.Clone(), nil
+}
+
+// setTaskPaused pauses or resumes a task and saves it to the store
+func (m *Manager) setTaskPaused(key string, paused bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	task, exists := m.tasks[key]
+	if !exists {
+		return fmt.Errorf("task '%s': %w", key, ErrTaskNotFound)
+	}
+
+	updated := task.Clone()
+	updated.Paused = paused
+	return m.saveTask(updated)
+}
+
+// saveTask loads a task and saves it to the store, restoring the previous task if saving fails (called with mutex held)
+func (m *Manager) saveTask(task *Task) error {
+	previous := m.tasks[task.Key]
+
+	if err := m.loadTask(task); err != nil {
+		m.restoreTask(task.Key, previous)
+		return err
+	}
+
+	if err := m.store.SaveTask(task); err != nil {
+		m.restoreTask(task.Key, previous)
+		return fmt.Errorf("failed to save task '%s': %w", task.Key, err)
+	}
+
+	return nil
+}
+
+// restoreTask puts back the task that was loaded before a failed change (called with mutex held)
+func (m *Manager) restoreTask(key string, previous *Task) {
+	m.unscheduleTask(key)
+	delete(m.tasks, key)
+
+	if previous != nil {
+		if err := m.loadTask(previous); err != nil {
+			log.Printf("[OUTREACH]: Failed to restore task '%s': %v", key, err)
+		}
+	}
+}
+
+// loadTask registers a single task, replacing any task with the same key (called with mutex held)
 func (m *Manager) loadTask(task *Task) error {
 	if task.Key == "" {
 		return fmt.Errorf("task key cannot be empty")
 	}
 
-	// Store the task
+	// Find the task's run function
+	if task.Run == nil {
+		runFunc, exists := m.opts.TaskFunctions[task.RunType()]
+		if !exists {
+			return fmt.Errorf("no run function found for task type '%s'", task.RunType())
+		}
+		task.Run = runFunc
+	}
+
+	// Validate the cadence before touching the loaded task
+	var schedule taskSchedule
+	switch task.Cadence {
+	case CronCadence:
+		if cronSpec(task) == "" {
+			return fmt.Errorf("cron tasks require 'spec' parameter")
+		}
+		if _, err := cronParser.Parse(cronSpec(task)); err != nil {
+			return fmt.Errorf("invalid cron spec: %w", err)
+		}
+
+	default:
+		if !ValidateCadenceType(task.Cadence) {
+			return fmt.Errorf("unsupported cadence type: %s", task.Cadence)
+		}
+
+		var err error
+		if schedule, err = m.newTaskSchedule(task); err != nil {
+			return fmt.Errorf("invalid %s cadence: %w", task.Cadence, err)
+		}
+	}
+
+	// Store the task, replacing any previous schedule
+	m.unscheduleTask(task.Key)
 	m.tasks[task.Key] = task
 
+	// Paused tasks stay loaded but aren't scheduled
+	if task.Paused {
+		return nil
+	}
+
 	switch task.Cadence {
 	case CronCadence: // Cron-based tasks are added to the cron instance
 		return m.loadCronTask(task)
 
-	case SunriseCadence, SunsetCadence: // Sunrise/Sunset tasks get added automatically; logic for running is handled in ticker
-		if m.opts == nil {
-			return fmt.Errorf("latitude and longitude must be set for sunrise/sunset tasks")
-		}
-		m.tasks[task.Key] = task
+	default: // Other tasks get a timer for their next run
+		m.scheduleTimerTask(task, schedule)
 		return nil
-
-	default:
-		return fmt.Errorf("unsupported cadence type: %s", task.Cadence)
 	}
 }
 
 // loadCronTask schedules a cron-based task
 func (m *Manager) loadCronTask(task *Task) error {
-	cronSpec, ok := task.CadenceParams["spec"].(string)
-	if !ok || cronSpec == "" {
-		return fmt.Errorf("cron tasks require 'spec' parameter")
-	}
-
-	_, err := m.cron.AddFunc(cronSpec, func() {
+	id, err := m.cron.AddFunc(cronSpec(task), func() {
 		m.executeTask(task)
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	m.cronEntries[task.Key] = id
+	return nil
+}
+
+// unscheduleTask removes a task's cron entry or timer, if it has one (called with mutex held)
+func (m *Manager) unscheduleTask(key string) {
+	if id, exists := m.cronEntries[key]; exists {
+		m.cron.Remove(id)
+		delete(m.cronEntries, key)
+	}
+
+	if timer, exists := m.timers[key]; exists {
+		timer.timer.Stop()
+		delete(m.timers, key)
+	}
 }
 
-// executeTask runs a task and sends the response to the channel
+// cronParser parses cron specs the same way as the manager's cron instance
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// cronSpec returns a cron task's 'spec' parameter
+func cronSpec(task *Task) string {
+	spec, _ := task.CadenceParams["spec"].(string)
+	return spec
+}
+
+// TriggerOptions control a manually triggered task run
+type TriggerOptions struct {
+	Preview bool // Return the task's output without delivering it to clients
+}
+
+// TriggerTask runs a loaded task immediately, even if it is paused, and returns its output.
+// A nil output means the task had nothing to send.
+func (m *Manager) TriggerTask(key string, opts TriggerOptions) (*TaskReturn, error) {
+	m.mutex.RLock()
+	task, exists := m.tasks[key]
+	m.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("task '%s': %w", key, ErrTaskNotFound)
+	}
+
+	return m.runTask(task, ManualRun, opts.Preview)
+}
+
+// executeTask runs a scheduled task and sends the response to the channel
 func (m *Manager) executeTask(task *Task) {
-	//log.Printf("[OUTREACH]: Executing task: %s", task.Key)
+	_, span := tracer.Start(m.ctx, "outreach.task", trace.WithAttributes(
+		attribute.String("task.key", task.Key),
+		attribute.String("task.type", task.RunType()),
+	))
+	defer span.End()
+
+	if _, err := m.runTask(task, ScheduledRun, false); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Printf("[OUTREACH]: %v", err)
+	}
+}
+
+// runTask runs a task's function and, unless previewing, sends the response to the channel.
+// Every run is recorded in the task's run history.
+func (m *Manager) runTask(task *Task, trigger RunTrigger, preview bool) (output *TaskReturn, err error) {
+	if !m.beginRun() {
+		return nil, fmt.Errorf("manager is stopped, not running task '%s'", task.Key)
+	}
+	defer m.inFlight.Done()
 
-	// Get client IDs from task params
-	if len(task.ClientIds) == 0 {
-		log.Printf("[OUTREACH]: Task '%s' has no client_ids specified", task.Key)
-		return
+	run := &TaskRun{
+		Key:       task.Key,
+		Trigger:   trigger,
+		StartedAt: time.Now(),
+		Outcome:   RunFailed,
 	}
+	defer func() {
+		if run.Duration == 0 {
+			run.Duration = time.Since(run.StartedAt)
+		}
+		if err != nil {
+			run.Error = err.Error()
+		}
+		m.recordRun(run)
+	}()
 
-	// Convert to string slice and validate implementations exist
+	// Find the clients to deliver to; previews aren't delivered so they don't need any
 	var clients []struct {
 		Id          string `json:"id"`
 		CallbackUrl string `json:"callback_url"`
 	}
 
-	for _, clientId := range task.ClientIds {
-		// Get implementation details
-		impl, err := m.store.GetImplementation(clientId)
-		if err != nil {
-			log.Printf("[OUTREACH]: Implementation '%s' not found for task '%s': %v", clientId, task.Key, err)
-			continue
+	if !preview {
+		if len(task.ClientIds) == 0 {
+			return nil, fmt.Errorf("task '%s' has no client_ids specified", task.Key)
 		}
 
-		if impl == nil || !impl.Active {
-			log.Printf("[OUTREACH]: Implementation '%s' is inactive for task '%s'", clientId, task.Key)
-			continue
-		}
+		for _, clientId := range task.ClientIds {
+			// Get implementation details
+			impl, err := m.store.GetImplementation(clientId)
+			if err != nil {
+				log.Printf("[OUTREACH]: Implementation '%s' not found for task '%s': %v", clientId, task.Key, err)
+				continue
+			}
 
-		// Add to clients list
-		clients = append(clients, struct {
-			Id          string `json:"id"`
-			CallbackUrl string `json:"callback_url"`
-		}{
-			Id:          impl.ClientID,
-			CallbackUrl: impl.CallbackURL,
-		})
-	}
+			if impl == nil || !impl.Active {
+				log.Printf("[OUTREACH]: Implementation '%s' is inactive for task '%s'", clientId, task.Key)
+				continue
+			}
 
-	// If no valid clients, skip
-	if len(clients) == 0 {
-		log.Printf("[OUTREACH]: No valid clients found for task '%s'", task.Key)
-		return
+			// Add to clients list
+			clients = append(clients, struct {
+				Id          string `json:"id"`
+				CallbackUrl string `json:"callback_url"`
+			}{
+				Id:          impl.ClientID,
+				CallbackUrl: impl.CallbackURL,
+			})
+		}
+
+		// If no valid clients, skip
+		if len(clients) == 0 {
+			return nil, fmt.Errorf("no valid clients found for task '%s'", task.Key)
+		}
 	}
 
 	// Run the task's function if defined
 	if task.Run == nil {
-		log.Printf("[OUTREACH]: Task '%s' has no run function defined", task.Key)
-		return
+		return nil, fmt.Errorf("task '%s' has no run function defined", task.Key)
 	}
-	output := task.Run(m.cfg)
 
-	// If output is nil, skip
-	if output == nil {
-		//log.Printf("[OUTREACH]: Task '%s' returned nil output", task.Key)
-		return
+	output, err = m.callTask(task)
+	run.Duration = time.Since(run.StartedAt)
+	if err != nil {
+		run.Outcome = RunPanicked
+		return nil, err
+	}
+
+	// If output is empty or we're previewing, there's nothing to send
+	if output == nil || (output.Content == "" && output.Data == nil) {
+		run.Outcome = RunEmpty
+		return output, nil
+	}
+	if preview {
+		run.Outcome = RunPreview
+		return output, nil
 	}
 
 	// Create response
@@ -258,59 +556,31 @@ func (m *Manager) executeTask(task *Task) {
 	select {
 	case m.responsesCh <- response:
 		log.Printf("[OUTREACH]: Task '%s' response sent to channel", task.Key)
+		run.Outcome = RunQueued
+		run.IdempotencyId = response.IdempotencyId
+		return output, nil
 	case <-m.ctx.Done():
-		log.Printf("[OUTREACH]: Manager context cancelled, dropping task '%s' response", task.Key)
+		return output, fmt.Errorf("manager context cancelled, dropping task '%s' response", task.Key)
 	default:
-		log.Printf("[OUTREACH]: Response channel full, dropping task '%s' response", task.Key)
+		return output, fmt.Errorf("response channel full, dropping task '%s' response", task.Key)
 	}
 }
 
-// handleSunEvents processes sunrise and sunset events
-func (m *Manager) handleSunEvents() {
-	for {
-		select {
-		case <-m.ctx.Done():
-			return
-		case <-m.sunTicker.C:
-			m.checkSunEvents()
+// callTask calls a task's run function, turning a panic into an error
+func (m *Manager) callTask(task *Task) (output *TaskReturn, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[OUTREACH]: Task '%s' panicked: %v\n%s", task.Key, r, debug.Stack())
+			err = fmt.Errorf("task '%s' panicked: %v", task.Key, r)
 		}
-	}
-}
-
-// checkSunEvents checks if it's time for sunrise or sunset tasks
-func (m *Manager) checkSunEvents() {
-	now := time.Now()
+	}()
 
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	// Find sunrise/sunset
-	lat, long := m.opts.Latitude, m.opts.Longitude
-	year, month, day := now.Date()
-	sunriseTime, sunsetTime := sunrise.SunriseSunset(lat, long, year, month, day)
-
-	// Check if we're within a minute of sunrise (6:00 AM for simplicity)
-	if m.isTimeForEvent(now, sunriseTime) {
-		for _, task := range m.tasks {
-			if task.Cadence == SunriseCadence {
-				go m.executeTask(task)
-			}
-		}
-	}
-
-	// Check if we're within a minute of sunset (6:00 PM for simplicity)
-	if m.isTimeForEvent(now, sunsetTime) {
-		for _, task := range m.tasks {
-			if task.Cadence == SunsetCadence {
-				go m.executeTask(task)
-			}
-		}
-	}
+	return task.Run(m.cfg, task.Params), nil
 }
 
-// isTimeForEvent checks if the current time matches the target hour and minute
-func (m *Manager) isTimeForEvent(now time.Time, target time.Time) bool {
-	return now.Hour() == target.Hour() && now.Minute() == target.Minute()
+// GetImplementation returns a registered implementation by client ID
+func (m *Manager) GetImplementation(clientID string) (*Implementation, error) {
+	return m.store.GetImplementation(clientID)
 }
 
 // GetImplementations returns all registered implementations
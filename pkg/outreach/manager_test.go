@@ -0,0 +1,382 @@
+package outreach_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	outreach_store "github.com/ethanbaker/assistant/internal/stores/outreach"
+	"github.com/ethanbaker/assistant/pkg/outreach"
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestManager creates a manager backed by an in-memory store with "echo", "empty", "panic", and reminder task types
+func newTestManager(t *testing.T) (*outreach.Manager, outreach.StoreInterface) {
+	store := outreach_store.NewInMemoryStore()
+	manager, err := outreach.NewManager(utils.NewConfig(map[string]string{}), &outreach.ManagerOptions{
+		Store: store,
+		TaskFunctions: map[string]outreach.TaskRunFunction{
+			"echo": func(cfg *utils.Config, params map[string]any) *outreach.TaskReturn {
+				return &outreach.TaskReturn{Content: "echo"}
+			},
+			"empty": func(cfg *utils.Config, params map[string]any) *outreach.TaskReturn {
+				return nil
+			},
+			"panic": func(cfg *utils.Config, params map[string]any) *outreach.TaskReturn {
+				panic("boom")
+			},
+			outreach.REMINDER_TASK_TYPE: outreach.RunReminder,
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(manager.Stop)
+
+	return manager, store
+}
+
+// Helper function to register the "discord" implementation, checking it was issued a signing key
+func registerTestImplementation(t *testing.T, manager *outreach.Manager) {
+	impl, err := manager.RegisterImplementation(&outreach.RegisterRequest{
+		ClientId:     "discord",
+		CallbackUrl:  "http://localhost:8081/outreach",
+		ClientSecret: "secret",
+	})
+	require.NoError(t, err)
+	require.Len(t, impl.Credentials.SigningKey, outreach.SIGNING_KEY_LENGTH)
+}
+
+func TestManagerTasks(t *testing.T) {
+	manager, store := newTestManager(t)
+
+	task := &outreach.Task{
+		Key:           "reminder",
+		Type:          "echo",
+		ClientIds:     []string{"discord"},
+		Cadence:       outreach.CronCadence,
+		CadenceParams: map[string]any{"spec": "0 8 * * *"},
+	}
+	require.NoError(t, manager.AddTask(task))
+	assert.ErrorIs(t, manager.AddTask(task), outreach.ErrTaskExists)
+
+	// Added tasks are saved to the store
+	stored, err := store.GetTask("reminder")
+	require.NoError(t, err)
+	assert.Equal(t, "0 8 * * *", stored.CadenceParams["spec"])
+
+	// Invalid tasks are rejected and leave the loaded task alone
+	assert.Error(t, manager.UpdateTask(&outreach.Task{Key: "reminder", Type: "echo", Cadence: outreach.CronCadence, CadenceParams: map[string]any{"spec": "not a spec"}}))
+	assert.Error(t, manager.UpdateTask(&outreach.Task{Key: "reminder", Type: "missing", Cadence: outreach.SunsetCadence}))
+	loaded, err := manager.GetTask("reminder")
+	require.NoError(t, err)
+	assert.Equal(t, outreach.CronCadence, loaded.Cadence)
+
+	// Updating replaces the task
+	task.CadenceParams = map[string]any{"spec": "0 9 * * *"}
+	require.NoError(t, manager.UpdateTask(task))
+	stored, err = store.GetTask("reminder")
+	require.NoError(t, err)
+	assert.Equal(t, "0 9 * * *", stored.CadenceParams["spec"])
+	assert.ErrorIs(t, manager.UpdateTask(&outreach.Task{Key: "missing"}), outreach.ErrTaskNotFound)
+
+	// Pausing and resuming is saved
+	require.NoError(t, manager.PauseTask("reminder"))
+	stored, err = store.GetTask("reminder")
+	require.NoError(t, err)
+	assert.True(t, stored.Paused)
+
+	require.NoError(t, manager.ResumeTask("reminder"))
+	loaded, err = manager.GetTask("reminder")
+	require.NoError(t, err)
+	assert.False(t, loaded.Paused)
+
+	// Removing deletes the task everywhere
+	require.NoError(t, manager.RemoveTask("reminder"))
+	assert.Empty(t, manager.GetTasks())
+	_, err = store.GetTask("reminder")
+	assert.ErrorIs(t, err, outreach.ErrTaskNotFound)
+	assert.ErrorIs(t, manager.RemoveTask("reminder"), outreach.ErrTaskNotFound)
+}
+
+func TestManagerLoadStoredTasks(t *testing.T) {
+	manager, store := newTestManager(t)
+
+	// Config tasks are replaced by stored tasks with the same key
+	require.NoError(t, manager.LoadTasks([]*outreach.Task{{Key: "echo", Cadence: outreach.SunriseCadence}}))
+	require.NoError(t, store.SaveTask(&outreach.Task{Key: "echo", Cadence: outreach.SunsetCadence, Paused: true}))
+
+	count, err := manager.LoadStoredTasks()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	task, err := manager.GetTask("echo")
+	require.NoError(t, err)
+	assert.Equal(t, outreach.SunsetCadence, task.Cadence)
+	assert.True(t, task.Paused)
+}
+
+func TestManagerTriggerTask(t *testing.T) {
+	manager, _ := newTestManager(t)
+
+	require.NoError(t, manager.AddTask(&outreach.Task{
+		Key:       "reminder",
+		Type:      "echo",
+		ClientIds: []string{"discord"},
+		Cadence:   outreach.SunriseCadence,
+		Paused:    true,
+	}))
+
+	_, err := manager.TriggerTask("missing", outreach.TriggerOptions{})
+	assert.ErrorIs(t, err, outreach.ErrTaskNotFound)
+
+	// Previews run without any clients and aren't delivered
+	output, err := manager.TriggerTask("reminder", outreach.TriggerOptions{Preview: true})
+	require.NoError(t, err)
+	assert.Equal(t, "echo", output.Content)
+	assert.Empty(t, manager.GetResponseChannel())
+
+	// Real runs need an active client
+	_, err = manager.TriggerTask("reminder", outreach.TriggerOptions{})
+	assert.Error(t, err)
+
+	registerTestImplementation(t, manager)
+
+	// Paused tasks can still be run by hand
+	output, err = manager.TriggerTask("reminder", outreach.TriggerOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "echo", output.Content)
+
+	response := <-manager.GetResponseChannel()
+	assert.Equal(t, "reminder", response.Key)
+	assert.Equal(t, "echo", response.Content)
+	require.Len(t, response.Clients, 1)
+	assert.Equal(t, "discord", response.Clients[0].Id)
+}
+
+func TestManagerStopWaitsForRuns(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	manager, err := outreach.NewManager(utils.NewConfig(map[string]string{}), &outreach.ManagerOptions{
+		Store: outreach_store.NewInMemoryStore(),
+		TaskFunctions: map[string]outreach.TaskRunFunction{
+			"slow": func(cfg *utils.Config, params map[string]any) *outreach.TaskReturn {
+				close(started)
+				<-release
+				return &outreach.TaskReturn{Content: "done"}
+			},
+		},
+	})
+	require.NoError(t, err)
+	registerTestImplementation(t, manager)
+
+	require.NoError(t, manager.AddTask(&outreach.Task{
+		Key:       "slow",
+		Type:      "slow",
+		ClientIds: []string{"discord"},
+		Cadence:   outreach.SunriseCadence,
+		Paused:    true,
+	}))
+
+	// Stop the manager while a run is in progress
+	go manager.TriggerTask("slow", outreach.TriggerOptions{})
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		manager.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned before the running task finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The response channel is only closed once the run is done with it
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after the running task finished")
+	}
+	for range manager.GetResponseChannel() {
+	}
+
+	// Runs after stopping are refused
+	_, err = manager.TriggerTask("slow", outreach.TriggerOptions{})
+	assert.Error(t, err)
+}
+
+func TestManagerTaskRuns(t *testing.T) {
+	manager, _ := newTestManager(t)
+	registerTestImplementation(t, manager)
+
+	for _, key := range []string{"echo", "empty", "panic"} {
+		require.NoError(t, manager.AddTask(&outreach.Task{
+			Key:           key,
+			ClientIds:     []string{"discord"},
+			Cadence:       outreach.CronCadence,
+			CadenceParams: map[string]any{"spec": "0 8 * * *"},
+		}))
+	}
+
+	// Panics inside a task are recovered and recorded
+	_, err := manager.TriggerTask("panic", outreach.TriggerOptions{})
+	assert.ErrorContains(t, err, "boom")
+	_, err = manager.TriggerTask("empty", outreach.TriggerOptions{})
+	require.NoError(t, err)
+	_, err = manager.TriggerTask("echo", outreach.TriggerOptions{Preview: true})
+	require.NoError(t, err)
+	_, err = manager.TriggerTask("echo", outreach.TriggerOptions{})
+	require.NoError(t, err)
+
+	runs, err := manager.GetTaskRuns("panic")
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, outreach.RunPanicked, runs[0].Outcome)
+	assert.Equal(t, outreach.ManualRun, runs[0].Trigger)
+
+	runs, err = manager.GetTaskRuns("empty")
+	require.NoError(t, err)
+	assert.Equal(t, outreach.RunEmpty, runs[0].Outcome)
+
+	// Delivery outcomes update the run that produced the response
+	runs, err = manager.GetTaskRuns("echo")
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, outreach.RunQueued, runs[0].Outcome)
+	assert.Equal(t, outreach.RunPreview, runs[1].Outcome)
+
+	manager.RecordDeliveryOutcome(runs[0].IdempotencyId, false, "gave up")
+	runs, err = manager.GetTaskRuns("echo")
+	require.NoError(t, err)
+	assert.Equal(t, outreach.RunUndelivered, runs[0].Outcome)
+	assert.Equal(t, "gave up", runs[0].Error)
+
+	// Statuses summarize the runs and the next scheduled run
+	for _, status := range manager.GetTaskStatuses() {
+		require.NotNil(t, status.NextRun, status.Key)
+		require.NotNil(t, status.LastRun, status.Key)
+		switch status.Key {
+		case "echo":
+			assert.Equal(t, 2, status.Runs)
+			assert.Equal(t, 1, status.Failures)
+		case "panic":
+			assert.Equal(t, 1, status.Failures)
+		}
+	}
+
+	_, err = manager.GetTaskRuns("missing")
+	assert.ErrorIs(t, err, outreach.ErrTaskNotFound)
+	assert.True(t, manager.Running())
+}
+
+func TestManagerSunTasks(t *testing.T) {
+	manager, _ := newTestManager(t)
+
+	// Sun tasks are scheduled for their next event, and unscheduled while paused
+	require.NoError(t, manager.AddTask(&outreach.Task{
+		Key:           "sunset",
+		Type:          "echo",
+		Cadence:       outreach.SunsetCadence,
+		CadenceParams: map[string]any{"offset": "-1h", "latitude": 51.5, "longitude": -0.12, "timezone": "Europe/London"},
+	}))
+
+	statuses := manager.GetTaskStatuses()
+	require.Len(t, statuses, 1)
+	require.NotNil(t, statuses[0].NextRun)
+	assert.True(t, statuses[0].NextRun.After(time.Now()))
+	assert.True(t, statuses[0].NextRun.Before(time.Now().Add(48*time.Hour)))
+
+	require.NoError(t, manager.PauseTask("sunset"))
+	assert.Nil(t, manager.GetTaskStatuses()[0].NextRun)
+
+	// Invalid sun params are rejected
+	assert.Error(t, manager.AddTask(&outreach.Task{
+		Key:           "sunrise",
+		Type:          "echo",
+		Cadence:       outreach.SunriseCadence,
+		CadenceParams: map[string]any{"timezone": "Nowhere/City"},
+	}))
+}
+
+func TestManagerOnceTask(t *testing.T) {
+	manager, store := newTestManager(t)
+	registerTestImplementation(t, manager)
+
+	require.NoError(t, manager.AddTask(&outreach.Task{
+		Key:           "reminder",
+		Type:          "echo",
+		ClientIds:     []string{"discord"},
+		Cadence:       outreach.OnceCadence,
+		CadenceParams: map[string]any{"at": time.Now().Add(50 * time.Millisecond).Format(time.RFC3339Nano)},
+	}))
+
+	// One-shot tasks run once and then remove themselves
+	select {
+	case response := <-manager.GetResponseChannel():
+		assert.Equal(t, "reminder", response.Key)
+	case <-time.After(5 * time.Second):
+		t.Fatal("one-shot task did not run")
+	}
+
+	require.Eventually(t, func() bool {
+		_, err := manager.GetTask("reminder")
+		return errors.Is(err, outreach.ErrTaskNotFound)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err := store.GetTask("reminder")
+	assert.ErrorIs(t, err, outreach.ErrTaskNotFound)
+
+	// Unknown cadences and calendar tasks without a calendar are rejected
+	assert.Error(t, manager.AddTask(&outreach.Task{Key: "bad", Type: "echo", Cadence: "sometimes"}))
+	assert.Error(t, manager.AddTask(&outreach.Task{Key: "meeting", Type: "echo", Cadence: outreach.BeforeCalendarEventCadence}))
+}
+
+func TestManagerReminders(t *testing.T) {
+	manager, _ := newTestManager(t)
+	at := time.Now().Add(time.Hour)
+
+	// Reminders need an implementation to deliver them
+	_, err := manager.AddReminder("ethan", "discord", "Call mom", at)
+	assert.Error(t, err)
+
+	registerTestImplementation(t, manager)
+
+	_, err = manager.AddReminder("ethan", "discord", "Too late", time.Now().Add(-time.Minute))
+	assert.Error(t, err)
+
+	reminder, err := manager.AddReminder("ethan", "discord", "Call mom", at)
+	require.NoError(t, err)
+	_, err = manager.AddReminder("ethan", "discord", "Water plants", at.Add(-30*time.Minute))
+	require.NoError(t, err)
+	_, err = manager.AddReminder("sam", "discord", "Stretch", at)
+	require.NoError(t, err)
+
+	// Reminders are one-shot tasks for the user's implementation
+	task, err := manager.GetTask(reminder.ID)
+	require.NoError(t, err)
+	assert.Equal(t, outreach.OnceCadence, task.Cadence)
+	assert.Equal(t, []string{"discord"}, task.ClientIds)
+
+	// Users only see their own reminders, soonest first
+	reminders := manager.ListReminders("ethan")
+	require.Len(t, reminders, 2)
+	assert.Equal(t, "Water plants", reminders[0].Message)
+	assert.Equal(t, "Call mom", reminders[1].Message)
+	assert.True(t, at.Truncate(time.Second).Equal(reminders[1].At))
+
+	// Users can't cancel each other's reminders
+	assert.ErrorIs(t, manager.CancelReminder("sam", reminder.ID), outreach.ErrReminderNotFound)
+	require.NoError(t, manager.CancelReminder("ethan", reminder.ID))
+	assert.ErrorIs(t, manager.CancelReminder("ethan", reminder.ID), outreach.ErrReminderNotFound)
+	assert.Len(t, manager.ListReminders("ethan"), 1)
+
+	// The run function delivers the message
+	output, err := manager.TriggerTask(reminders[0].ID, outreach.TriggerOptions{Preview: true})
+	require.NoError(t, err)
+	assert.Contains(t, output.Content, "Water plants")
+}
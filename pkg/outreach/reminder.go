@@ -0,0 +1,153 @@
+package outreach
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ethanbaker/assistant/pkg/utils"
+	"github.com/google/uuid"
+)
+
+/** Reminders are one-shot tasks that users schedule through the assistant */
+
+// REMINDER_TASK_TYPE is the task type of reminders, which must be registered with RunReminder
+const REMINDER_TASK_TYPE = "reminder"
+
+// MAX_REMINDER_LENGTH is the longest reminder message allowed
+const MAX_REMINDER_LENGTH = 1000
+
+// ErrReminderNotFound is returned when a reminder doesn't exist or belongs to another user
+var ErrReminderNotFound = errors.New("reminder not found")
+
+// Reminder is a message delivered to a user once at a set time
+type Reminder struct {
+	ID       string    `json:"id"`        // Key of the reminder's task
+	UserID   string    `json:"user_id"`   // User the reminder belongs to
+	ClientID string    `json:"client_id"` // Implementation that delivers the reminder
+	Message  string    `json:"message"`   // What to remind the user of
+	At       time.Time `json:"at"`        // When the reminder is delivered
+}
+
+// RunReminder is the run function of reminder tasks, returning the reminder's message
+func RunReminder(cfg *utils.Config, params map[string]any) *TaskReturn {
+	message, _ := params["message"].(string)
+	if message == "" {
+		return nil
+	}
+
+	return &TaskReturn{
+		Content: fmt.Sprintf("<STRONG>Reminder:</STRONG> %s", message),
+		Data:    nil,
+	}
+}
+
+// AddReminder schedules a reminder for a user, delivered through the given implementation
+func (m *Manager) AddReminder(userID, clientID, message string, at time.Time) (*Reminder, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("reminders require a user")
+	}
+	if message == "" {
+		return nil, fmt.Errorf("reminder message cannot be empty")
+	}
+	if len(message) > MAX_REMINDER_LENGTH {
+		return nil, fmt.Errorf("reminder message cannot be longer than %d characters", MAX_REMINDER_LENGTH)
+	}
+	if !at.After(time.Now()) {
+		return nil, fmt.Errorf("reminder time must be in the future")
+	}
+
+	// Make sure the reminder can be delivered
+	impl, err := m.GetImplementation(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("no implementation to deliver the reminder: %w", err)
+	}
+	if !impl.Active {
+		return nil, fmt.Errorf("implementation '%s' is not active", clientID)
+	}
+
+	reminder := &Reminder{
+		ID:       "reminder-" + uuid.NewString(),
+		UserID:   userID,
+		ClientID: clientID,
+		Message:  message,
+		At:       at,
+	}
+
+	task := &Task{
+		Key:       reminder.ID,
+		Type:      REMINDER_TASK_TYPE,
+		ClientIds: []string{clientID},
+		Params: map[string]any{
+			"user_id": userID,
+			"message": message,
+		},
+		Cadence:       OnceCadence,
+		CadenceParams: map[string]any{"at": at.Format(time.RFC3339)},
+	}
+	if err := m.AddTask(task); err != nil {
+		return nil, err
+	}
+
+	return reminder, nil
+}
+
+// ListReminders returns a user's pending reminders, soonest first
+func (m *Manager) ListReminders(userID string) []Reminder {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	reminders := []Reminder{}
+	for _, task := range m.tasks {
+		if reminder, ok := toReminder(task); ok && reminder.UserID == userID {
+			reminders = append(reminders, reminder)
+		}
+	}
+
+	sort.Slice(reminders, func(i, j int) bool {
+		return reminders[i].At.Before(reminders[j].At)
+	})
+
+	return reminders
+}
+
+// CancelReminder removes one of a user's pending reminders
+func (m *Manager) CancelReminder(userID, id string) error {
+	m.mutex.RLock()
+	task, exists := m.tasks[id]
+	m.mutex.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("reminder '%s': %w", id, ErrReminderNotFound)
+	}
+	if reminder, ok := toReminder(task); !ok || reminder.UserID != userID {
+		return fmt.Errorf("reminder '%s': %w", id, ErrReminderNotFound)
+	}
+
+	if err := m.RemoveTask(id); err != nil {
+		if errors.Is(err, ErrTaskNotFound) {
+			return fmt.Errorf("reminder '%s': %w", id, ErrReminderNotFound)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Helper function to read a reminder from its task
+func toReminder(task *Task) (Reminder, bool) {
+	if task.Type != REMINDER_TASK_TYPE || task.Cadence != OnceCadence {
+		return Reminder{}, false
+	}
+
+	reminder := Reminder{ID: task.Key}
+	reminder.UserID, _ = task.Params["user_id"].(string)
+	reminder.Message, _ = task.Params["message"].(string)
+	if len(task.ClientIds) > 0 {
+		reminder.ClientID = task.ClientIds[0]
+	}
+	reminder.At, _ = timeParam(task.CadenceParams, "at")
+
+	return reminder, true
+}
@@ -0,0 +1,190 @@
+package outreach
+
+import (
+	"fmt"
+	"time"
+)
+
+// MAX_TASK_RUNS is the number of runs kept in memory for each task
+const MAX_TASK_RUNS = 50
+
+// RunTrigger represents what started a task run
+type RunTrigger string
+
+const (
+	// ScheduledRun runs were started by the task's cadence
+	ScheduledRun RunTrigger = "schedule"
+
+	// ManualRun runs were started through TriggerTask
+	ManualRun RunTrigger = "manual"
+)
+
+// RunOutcome represents how a task run ended
+type RunOutcome string
+
+const (
+	// RunQueued runs produced a response that is waiting to be delivered
+	RunQueued RunOutcome = "queued"
+
+	// RunDelivered runs had their response accepted by a client
+	RunDelivered RunOutcome = "delivered"
+
+	// RunUndelivered runs had their response dead-lettered after running out of retries
+	RunUndelivered RunOutcome = "undelivered"
+
+	// RunEmpty runs returned a nil or empty output, so there was nothing to send
+	RunEmpty RunOutcome = "empty"
+
+	// RunPreview runs returned their output to the caller instead of delivering it
+	RunPreview RunOutcome = "preview"
+
+	// RunFailed runs stopped with an error before producing a response
+	RunFailed RunOutcome = "failed"
+
+	// RunPanicked runs panicked inside the task's run function
+	RunPanicked RunOutcome = "panicked"
+)
+
+// TaskRun records a single run of a task
+type TaskRun struct {
+	ID            uint64        `json:"id"`
+	Key           string        `json:"key"`
+	Trigger       RunTrigger    `json:"trigger"`
+	StartedAt     time.Time     `json:"started_at"`
+	Duration      time.Duration `json:"duration"` // Time spent running the task, not including delivery
+	Outcome       RunOutcome    `json:"outcome"`
+	Error         string        `json:"error,omitempty"`          // Why the run or its delivery failed
+	IdempotencyId string        `json:"idempotency_id,omitempty"` // ID of the response the run produced
+}
+
+// TaskStatus summarizes a task's schedule and recent runs
+type TaskStatus struct {
+	Key      string      `json:"key"`
+	Cadence  CadenceType `json:"cadence"`
+	Paused   bool        `json:"paused"`
+	NextRun  *time.Time  `json:"next_run"` // nil if the task is paused or its next run can't be found
+	LastRun  *TaskRun    `json:"last_run"` // nil if the task hasn't run since the manager started
+	Runs     int         `json:"runs"`     // Runs kept in memory
+	Failures int         `json:"failures"` // Kept runs that failed, panicked, or weren't delivered
+}
+
+// GetTaskRuns returns a task's most recent runs, newest first
+func (m *Manager) GetTaskRuns(key string) ([]TaskRun, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if _, exists := m.tasks[key]; !exists {
+		return nil, fmt.Errorf("task '%s': %w", key, ErrTaskNotFound)
+	}
+
+	runs := m.runs[key]
+	result := make([]TaskRun, len(runs))
+	for i, run := range runs {
+		result[len(runs)-1-i] = *run
+	}
+
+	return result, nil
+}
+
+// GetTaskStatuses returns the schedule and run summary of every loaded task
+func (m *Manager) GetTaskStatuses() []TaskStatus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	statuses := make([]TaskStatus, 0, len(m.tasks))
+	for key, task := range m.tasks {
+		status := TaskStatus{
+			Key:     key,
+			Cadence: task.Cadence,
+			Paused:  task.Paused,
+			Runs:    len(m.runs[key]),
+		}
+
+		if !task.Paused {
+			status.NextRun = m.nextRun(task)
+		}
+
+		for _, run := range m.runs[key] {
+			if run.Outcome == RunFailed || run.Outcome == RunPanicked || run.Outcome == RunUndelivered {
+				status.Failures++
+			}
+		}
+		if runs := m.runs[key]; len(runs) > 0 {
+			last := *runs[len(runs)-1]
+			status.LastRun = &last
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// RecordDeliveryOutcome updates the run that produced a response once its delivery has finished
+func (m *Manager) RecordDeliveryOutcome(idempotencyId string, delivered bool, reason string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, runs := range m.runs {
+		for _, run := range runs {
+			if run.IdempotencyId != idempotencyId {
+				continue
+			}
+
+			if delivered {
+				run.Outcome = RunDelivered
+				run.Error = ""
+			} else {
+				run.Outcome = RunUndelivered
+				run.Error = reason
+			}
+			return
+		}
+	}
+}
+
+// Running checks if the manager has been started and not stopped
+func (m *Manager) Running() bool {
+	return m.ctx.Err() == nil
+}
+
+// recordRun adds a finished run to its task's history, dropping the oldest runs past MAX_TASK_RUNS
+func (m *Manager) recordRun(run *TaskRun) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.runSeq++
+	run.ID = m.runSeq
+
+	runs := append(m.runs[run.Key], run)
+	if len(runs) > MAX_TASK_RUNS {
+		runs = runs[len(runs)-MAX_TASK_RUNS:]
+	}
+	m.runs[run.Key] = runs
+}
+
+// nextRun finds when a task is next scheduled to run (called with mutex held)
+func (m *Manager) nextRun(task *Task) *time.Time {
+	switch task.Cadence {
+	case CronCadence:
+		id, exists := m.cronEntries[task.Key]
+		if !exists {
+			return nil
+		}
+
+		next := m.cron.Entry(id).Next
+		if next.IsZero() {
+			return nil
+		}
+		return &next
+
+	default:
+		timer, exists := m.timers[task.Key]
+		if !exists || !timer.run {
+			return nil
+		}
+
+		next := timer.at
+		return &next
+	}
+}
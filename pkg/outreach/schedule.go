@@ -0,0 +1,330 @@
+package outreach
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// taskSchedule computes when a timer-based task runs. Every cadence except cron uses one.
+type taskSchedule interface {
+	// next returns the first time after the given one that the task should run. If run is false the time
+	// is only when to check the schedule again, and a zero time means the task has no more runs.
+	next(after time.Time) (at time.Time, run bool)
+}
+
+// taskTimer is the pending timer of a timer-based task
+type taskTimer struct {
+	timer    *time.Timer
+	schedule taskSchedule
+	due      time.Time // When the schedule asked for the timer to fire, before any jitter
+	at       time.Time // When the timer actually fires
+	run      bool      // Whether the task runs when the timer fires, or the schedule is only checked again
+}
+
+// newTaskSchedule parses the cadence parameters of a timer-based task
+func (m *Manager) newTaskSchedule(task *Task) (taskSchedule, error) {
+	switch task.Cadence {
+	case SunriseCadence, SunsetCadence:
+		return newSunSchedule(task, m.opts)
+	case OnceCadence:
+		return newOnceSchedule(task)
+	case IntervalCadence:
+		return newIntervalSchedule(task)
+	case RRuleCadence:
+		return newRRuleSchedule(task, m.opts)
+	case BeforeCalendarEventCadence:
+		return newCalendarSchedule(m.ctx, task, m.opts)
+	default:
+		return nil, fmt.Errorf("unsupported cadence type: %s", task.Cadence)
+	}
+}
+
+// scheduleTimerTask sets a timer for a task's next run (called with mutex held)
+func (m *Manager) scheduleTimerTask(task *Task, schedule taskSchedule) {
+	// Calendar lookups can be slow, so the first one happens in the timer rather than while holding the mutex
+	if _, remote := schedule.(*calendarSchedule); remote {
+		m.setTaskTimer(task, schedule, time.Now(), false)
+		return
+	}
+
+	at, run := schedule.next(time.Now())
+	m.setTaskTimer(task, schedule, at, run)
+}
+
+// setTaskTimer starts a task's timer for the given time (called with mutex held)
+func (m *Manager) setTaskTimer(task *Task, schedule taskSchedule, due time.Time, run bool) {
+	if due.IsZero() {
+		log.Printf("[OUTREACH]: Task '%s' has no more runs scheduled", task.Key)
+		return
+	}
+
+	t := &taskTimer{
+		schedule: schedule,
+		due:      due,
+		at:       due,
+		run:      run,
+	}
+
+	if interval, ok := schedule.(*intervalSchedule); ok && run && interval.jitter > 0 {
+		t.at = due.Add(rand.N(interval.jitter))
+	}
+
+	t.timer = time.AfterFunc(time.Until(t.at), func() { m.fireTimerTask(task, t) })
+	m.timers[task.Key] = t
+}
+
+// fireTimerTask schedules a timer-based task's next run and then runs it.
+// The next run is found from the time this one was due rather than the current time,
+// so a timer that fires late never skips the following run.
+func (m *Manager) fireTimerTask(task *Task, t *taskTimer) {
+	if !m.currentTimer(task, t) {
+		return
+	}
+
+	// Work out the next run outside the mutex, since calendar schedules make requests
+	var next time.Time
+	var run bool
+	if task.Cadence != OnceCadence || !t.run {
+		next, run = t.schedule.next(t.due)
+	}
+
+	m.mutex.Lock()
+	if m.timers[task.Key] != t || m.tasks[task.Key] != task {
+		// The task was changed while the next run was found
+		m.mutex.Unlock()
+		return
+	}
+
+	delete(m.timers, task.Key)
+	if task.Cadence != OnceCadence || !t.run {
+		m.setTaskTimer(task, t.schedule, next, run)
+	}
+	m.mutex.Unlock()
+
+	if !t.run || m.ctx.Err() != nil {
+		return
+	}
+
+	m.executeTask(task)
+
+	// One-shot tasks are removed once they have run
+	if task.Cadence == OnceCadence {
+		m.removeFiredTask(task)
+	}
+}
+
+// currentTimer checks that a timer still belongs to a loaded, unpaused task
+func (m *Manager) currentTimer(task *Task, t *taskTimer) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.timers[task.Key] == t && m.tasks[task.Key] == task && !task.Paused
+}
+
+// removeFiredTask removes a one-shot task after it runs, unless it has been replaced since
+func (m *Manager) removeFiredTask(task *Task) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.tasks[task.Key] != task {
+		return
+	}
+
+	if err := m.store.DeleteTask(task.Key); err != nil && !errors.Is(err, ErrTaskNotFound) {
+		log.Printf("[OUTREACH]: Failed to remove one-shot task '%s': %v", task.Key, err)
+		return
+	}
+
+	m.unscheduleTask(task.Key)
+	delete(m.tasks, task.Key)
+	delete(m.runs, task.Key)
+}
+
+/** ---- SCHEDULES ---- */
+
+// onceSchedule runs a task a single time
+type onceSchedule struct {
+	at time.Time
+}
+
+// newOnceSchedule parses a one-shot task's 'at' parameter, an RFC 3339 timestamp
+func newOnceSchedule(task *Task) (*onceSchedule, error) {
+	at, err := timeParam(task.CadenceParams, "at")
+	if err != nil {
+		return nil, err
+	}
+	if at.IsZero() {
+		return nil, fmt.Errorf("once tasks require 'at' parameter")
+	}
+
+	return &onceSchedule{at: at}, nil
+}
+
+// next returns the fire time even if it has passed, so a task missed while the manager was down still runs
+func (s *onceSchedule) next(after time.Time) (time.Time, bool) {
+	return s.at, true
+}
+
+// intervalSchedule runs a task every fixed duration
+type intervalSchedule struct {
+	every  time.Duration
+	jitter time.Duration // Random delay of up to this long added to each run
+}
+
+// newIntervalSchedule parses an interval task's 'every' and optional 'jitter' parameters (durations or minutes)
+func newIntervalSchedule(task *Task) (*intervalSchedule, error) {
+	every, err := durationParam(task.CadenceParams, "every", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if every < MIN_TASK_INTERVAL {
+		return nil, fmt.Errorf("interval tasks require an 'every' parameter of at least %s", MIN_TASK_INTERVAL)
+	}
+
+	jitter, err := durationParam(task.CadenceParams, "jitter", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if jitter < 0 || jitter >= every {
+		return nil, fmt.Errorf("'jitter' must be between 0 and 'every'")
+	}
+
+	return &intervalSchedule{every: every, jitter: jitter}, nil
+}
+
+// next returns one interval after the given time. Intervals missed entirely are skipped, so a late
+// timer runs the task once rather than catching up on every run it missed.
+func (s *intervalSchedule) next(after time.Time) (time.Time, bool) {
+	next := after.Add(s.every)
+	if now := time.Now(); next.Before(now) {
+		next = next.Add(now.Sub(next).Truncate(s.every))
+	}
+
+	return next, true
+}
+
+// rruleSchedule runs a task on the occurrences of an RFC 5545 recurrence rule
+type rruleSchedule struct {
+	set *rrule.Set
+}
+
+// newRRuleSchedule parses an rrule task's 'rule' parameter and optional 'timezone'.
+// Rules without a DTSTART start from midnight today, so times default to the top of the hour.
+func newRRuleSchedule(task *Task, opts *ManagerOptions) (*rruleSchedule, error) {
+	rule, _ := task.CadenceParams["rule"].(string)
+	if strings.TrimSpace(rule) == "" {
+		return nil, fmt.Errorf("rrule tasks require 'rule' parameter")
+	}
+
+	location, err := locationParam(task.CadenceParams, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accept bare rules as well as RRULE: properties
+	lines := strings.Split(strings.TrimSpace(rule), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+		if strings.HasPrefix(strings.ToUpper(lines[i]), "FREQ=") {
+			lines[i] = "RRULE:" + lines[i]
+		}
+	}
+
+	set, err := rrule.StrSliceToRRuleSetInLoc(lines, location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'rule': %w", err)
+	}
+	if set.GetRRule() == nil {
+		return nil, fmt.Errorf("invalid 'rule': no RRULE given")
+	}
+
+	if set.GetDTStart().IsZero() {
+		year, month, day := time.Now().In(location).Date()
+		set.DTStart(time.Date(year, month, day, 0, 0, 0, 0, location))
+	}
+
+	return &rruleSchedule{set: set}, nil
+}
+
+// next returns the rule's first occurrence after the given time
+func (s *rruleSchedule) next(after time.Time) (time.Time, bool) {
+	next := s.set.After(after, false)
+	return next, !next.IsZero()
+}
+
+/** ---- PARAMS ---- */
+
+// durationParam reads a duration parameter given as a duration string or as a number of units
+func durationParam(params map[string]any, key string, unit time.Duration) (time.Duration, error) {
+	switch v := params[key].(type) {
+	case nil:
+		return 0, nil
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid '%s': %w", key, err)
+		}
+		return d, nil
+	case int:
+		return time.Duration(v) * unit, nil
+	case float64:
+		return time.Duration(v * float64(unit)), nil
+	default:
+		return 0, fmt.Errorf("'%s' must be a duration string or a number", key)
+	}
+}
+
+// floatParam reads an optional number parameter
+func floatParam(params map[string]any, key string) (float64, bool, error) {
+	switch v := params[key].(type) {
+	case nil:
+		return 0, false, nil
+	case int:
+		return float64(v), true, nil
+	case float64:
+		return v, true, nil
+	default:
+		return 0, false, fmt.Errorf("'%s' must be a number", key)
+	}
+}
+
+// timeParam reads an optional timestamp parameter, given as an RFC 3339 string (or a time when loaded from YAML)
+func timeParam(params map[string]any, key string) (time.Time, error) {
+	switch v := params[key].(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return v, nil
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid '%s': %w", key, err)
+		}
+		return t, nil
+	default:
+		return time.Time{}, fmt.Errorf("'%s' must be an RFC 3339 timestamp", key)
+	}
+}
+
+// locationParam reads a task's 'timezone' parameter, defaulting to the manager's timezone and then the server's
+func locationParam(params map[string]any, opts *ManagerOptions) (*time.Location, error) {
+	timezone := opts.Timezone
+	if tz, ok := params["timezone"].(string); ok && tz != "" {
+		timezone = tz
+	}
+	if timezone == "" {
+		return time.Local, nil
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'timezone': %w", err)
+	}
+	return location, nil
+}
@@ -0,0 +1,136 @@
+package outreach
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCalendar returns a fixed list of events that overlap the requested range
+type fakeCalendar struct {
+	events []CalendarEvent
+}
+
+func (c *fakeCalendar) ListEvents(ctx context.Context, start, end time.Time, calendarName string) ([]CalendarEvent, error) {
+	events := []CalendarEvent{}
+	for _, event := range c.events {
+		if !event.Start.Before(start) && event.Start.Before(end) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func TestIntervalSchedule(t *testing.T) {
+	schedule, err := newIntervalSchedule(&Task{CadenceParams: map[string]any{"every": "2h", "jitter": 10}})
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, schedule.every)
+	assert.Equal(t, 10*time.Minute, schedule.jitter)
+
+	now := time.Now()
+	next, run := schedule.next(now)
+	assert.True(t, run)
+	assert.Equal(t, now.Add(2*time.Hour), next)
+
+	// Missed intervals are skipped rather than caught up
+	next, _ = schedule.next(now.Add(-7 * time.Hour))
+	assert.Equal(t, now.Add(-time.Hour), next)
+
+	for _, params := range []map[string]any{
+		{},
+		{"every": "10s"},
+		{"every": "1h", "jitter": "2h"},
+	} {
+		_, err := newIntervalSchedule(&Task{CadenceParams: params})
+		assert.Error(t, err, params)
+	}
+}
+
+func TestRRuleSchedule(t *testing.T) {
+	opts := &ManagerOptions{Timezone: "America/New_York"}
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Rules without a DTSTART start at midnight in the task's timezone
+	schedule, err := newRRuleSchedule(&Task{CadenceParams: map[string]any{"rule": "FREQ=WEEKLY;BYDAY=MO;BYHOUR=9"}}, opts)
+	require.NoError(t, err)
+
+	next, run := schedule.next(time.Now())
+	require.True(t, run)
+	next = next.In(newYork)
+	assert.Equal(t, time.Monday, next.Weekday())
+	assert.Equal(t, 9, next.Hour())
+	assert.Equal(t, 0, next.Minute())
+
+	// Rules with a DTSTART and COUNT run out
+	schedule, err = newRRuleSchedule(&Task{CadenceParams: map[string]any{
+		"rule": "DTSTART:20250101T080000Z\nRRULE:FREQ=DAILY;COUNT=2",
+	}}, opts)
+	require.NoError(t, err)
+
+	next, run = schedule.next(time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC))
+	require.True(t, run)
+	assert.Equal(t, time.Date(2025, time.January, 2, 8, 0, 0, 0, time.UTC), next.UTC())
+
+	next, _ = schedule.next(next)
+	assert.True(t, next.IsZero())
+
+	for _, params := range []map[string]any{
+		{},
+		{"rule": "FREQ=SOMETIMES"},
+		{"rule": "FREQ=DAILY", "timezone": "Nowhere/City"},
+	} {
+		_, err := newRRuleSchedule(&Task{CadenceParams: params}, opts)
+		assert.Error(t, err, params)
+	}
+}
+
+func TestOnceSchedule(t *testing.T) {
+	at := time.Date(2025, time.March, 1, 9, 30, 0, 0, time.UTC)
+	schedule, err := newOnceSchedule(&Task{CadenceParams: map[string]any{"at": at.Format(time.RFC3339)}})
+	require.NoError(t, err)
+
+	// Passed fire times are still returned so missed tasks run
+	next, run := schedule.next(at.Add(time.Hour))
+	assert.True(t, run)
+	assert.True(t, at.Equal(next))
+
+	_, err = newOnceSchedule(&Task{})
+	assert.Error(t, err)
+	_, err = newOnceSchedule(&Task{CadenceParams: map[string]any{"at": "tomorrow"}})
+	assert.Error(t, err)
+}
+
+func TestCalendarSchedule(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+	calendar := &fakeCalendar{events: []CalendarEvent{
+		{ID: "1", Title: "Lunch", Start: now.Add(20 * time.Minute)},
+		{ID: "2", Title: "Team Standup", Start: now.Add(25 * time.Minute)},
+		{ID: "3", Title: "Standup", Start: now.Add(3 * time.Hour)},
+	}}
+	opts := &ManagerOptions{Calendar: calendar}
+
+	schedule, err := newCalendarSchedule(context.Background(), &Task{CadenceParams: map[string]any{"before": "10m", "filter": "standup"}}, opts)
+	require.NoError(t, err)
+
+	// Only matching events are used
+	next, run := schedule.next(now)
+	require.True(t, run)
+	assert.Equal(t, now.Add(15*time.Minute), next)
+
+	// Without a matching event in range, the calendar is checked again later
+	next, run = schedule.next(next)
+	assert.False(t, run)
+	assert.Equal(t, now.Add(15*time.Minute+CALENDAR_REFRESH_INTERVAL), next)
+
+	// The lead time defaults when it isn't given
+	schedule, err = newCalendarSchedule(context.Background(), &Task{}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, DEFAULT_CALENDAR_EVENT_LEAD, schedule.before)
+
+	_, err = newCalendarSchedule(context.Background(), &Task{}, &ManagerOptions{})
+	assert.Error(t, err)
+}
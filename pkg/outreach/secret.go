@@ -0,0 +1,156 @@
+package outreach
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MAX_CLIENT_SECRET_LENGTH is the longest client secret that can be hashed (a bcrypt limit)
+const MAX_CLIENT_SECRET_LENGTH = 72
+
+// SIGNING_KEY_LENGTH is the number of random bytes in a signing key
+const SIGNING_KEY_LENGTH = 32
+
+// DEFAULT_SECRET_GRACE_PERIOD is how long a rotated secret keeps working when no grace period is given
+const DEFAULT_SECRET_GRACE_PERIOD = 24 * time.Hour
+
+// SecretCredentials holds the hashed form of an implementation's client secret and the key requests are signed with.
+// The plaintext secret is never stored. The signing key is random and is only given to the client when it registers or rotates its secret.
+type SecretCredentials struct {
+	SecretHash              string     `json:"-"`
+	SigningKey              []byte     `json:"-"`
+	PreviousSecretHash      string     `json:"-"` // Hash of the secret replaced by the last rotation
+	PreviousSigningKey      []byte     `json:"-"`
+	PreviousSecretExpiresAt *time.Time `json:"-"` // When the previous secret stops being accepted
+}
+
+// NewSecretCredentials hashes a client secret and generates a new signing key
+func NewSecretCredentials(secret string) (SecretCredentials, error) {
+	hash, err := HashSecret(secret)
+	if err != nil {
+		return SecretCredentials{}, err
+	}
+
+	signingKey, err := NewSigningKey()
+	if err != nil {
+		return SecretCredentials{}, err
+	}
+
+	return SecretCredentials{
+		SecretHash: hash,
+		SigningKey: signingKey,
+	}, nil
+}
+
+// ResetSigningKey replaces the current signing key with a new random one
+func (c *SecretCredentials) ResetSigningKey() error {
+	signingKey, err := NewSigningKey()
+	if err != nil {
+		return err
+	}
+
+	c.SigningKey = signingKey
+	return nil
+}
+
+// Rotate replaces the current secret with a new one, accepting the current secret until the grace period ends
+func (c *SecretCredentials) Rotate(newSecret string, gracePeriod time.Duration, now time.Time) error {
+	next, err := NewSecretCredentials(newSecret)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := now.Add(gracePeriod)
+	next.PreviousSecretHash = c.SecretHash
+	next.PreviousSigningKey = c.SigningKey
+	next.PreviousSecretExpiresAt = &expiresAt
+
+	*c = next
+	return nil
+}
+
+// SecretMatches checks a client secret against the current secret and, until it expires, the secret it was rotated from
+func (c *SecretCredentials) SecretMatches(secret string, now time.Time) bool {
+	// Always compare against both hashes so the time taken doesn't reveal which one matched
+	previousHash := ""
+	if c.inGracePeriod(now) {
+		previousHash = c.PreviousSecretHash
+	}
+
+	current := CompareSecret(c.SecretHash, secret)
+	previous := CompareSecret(previousHash, secret)
+
+	return current || previous
+}
+
+// SigningKeys returns the keys outreach requests are signed with, newest first
+func (c *SecretCredentials) SigningKeys(now time.Time) [][]byte {
+	keys := [][]byte{}
+	if len(c.SigningKey) > 0 {
+		keys = append(keys, c.SigningKey)
+	}
+	if len(c.PreviousSigningKey) > 0 && c.inGracePeriod(now) {
+		keys = append(keys, c.PreviousSigningKey)
+	}
+
+	return keys
+}
+
+// inGracePeriod checks if the previous secret is still accepted
+func (c *SecretCredentials) inGracePeriod(now time.Time) bool {
+	return c.PreviousSecretExpiresAt != nil && now.Before(*c.PreviousSecretExpiresAt)
+}
+
+// dummySecretHash is compared against when there is no hash, so missing clients take as long to reject as wrong secrets
+var dummySecretHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("assistant-outreach-dummy-secret"), bcrypt.DefaultCost)
+	return hash
+})
+
+// NewSigningKey returns a random key to sign outreach requests with
+func NewSigningKey() ([]byte, error) {
+	key := make([]byte, SIGNING_KEY_LENGTH)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	return key, nil
+}
+
+// HashSecret returns a salted bcrypt hash of a client secret
+func HashSecret(secret string) (string, error) {
+	if secret == "" {
+		return "", fmt.Errorf("client_secret cannot be empty")
+	}
+	if len(secret) > MAX_CLIENT_SECRET_LENGTH {
+		return "", fmt.Errorf("client_secret cannot be longer than %d bytes", MAX_CLIENT_SECRET_LENGTH)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash client secret: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+// IsHashedSecret checks if a stored secret is already a bcrypt hash
+func IsHashedSecret(stored string) bool {
+	_, err := bcrypt.Cost([]byte(stored))
+	return err == nil
+}
+
+// CompareSecret checks a client secret against a stored hash in constant time.
+// An empty hash never matches, but takes as long to check as a real one.
+func CompareSecret(hash, secret string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummySecretHash(), []byte(secret))
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(secret)) == nil
+}
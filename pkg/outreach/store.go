@@ -1,11 +1,16 @@
 package outreach
 
-// Implementation represents a registered outreach implementation
+import "time"
+
+// Implementation represents a registered outreach implementation.
+// ClientSecret is only set when saving an implementation; stores keep the hashed Credentials instead.
 type Implementation struct {
 	ClientID     string `json:"client_id"`
 	CallbackURL  string `json:"callback_url"`
-	ClientSecret string `json:"client_secret"`
+	ClientSecret string `json:"-"`
 	Active       bool   `json:"active"`
+
+	Credentials SecretCredentials `json:"-"`
 }
 
 // Store defines the interface for outreach storage operations
@@ -16,4 +21,8 @@ type StoreInterface interface {
 	ListImplementations() []*Implementation
 	Exists(clientID string) bool
 	AuthenticateImplementation(clientID, clientSecret string) (*Implementation, error)
+	RotateSecret(clientID, newSecret string, gracePeriod time.Duration) (*Implementation, error)
+
+	DeliveryStoreInterface
+	TaskStoreInterface
 }
@@ -0,0 +1,104 @@
+package outreach
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nathan-osman/go-sunrise"
+)
+
+// MAX_SUN_OFFSET is the largest offset allowed from a sunrise or sunset
+const MAX_SUN_OFFSET = 12 * time.Hour
+
+// sunSearchDays is how many days ahead to look for a sunrise or sunset, which may not happen near the poles
+const sunSearchDays = 7
+
+// sunSchedule holds the parsed cadence parameters of a sunrise or sunset task
+type sunSchedule struct {
+	event     CadenceType    // SunriseCadence or SunsetCadence
+	offset    time.Duration  // Time from the event to run at, negative to run before it
+	location  *time.Location // Timezone whose calendar days the events belong to
+	latitude  float64
+	longitude float64
+}
+
+// newSunSchedule parses a sunrise or sunset task's cadence parameters, falling back to the manager's options.
+// Supported parameters are 'offset' (a duration like "-30m", or minutes), 'timezone' (an IANA name),
+// and 'latitude'/'longitude' overrides.
+func newSunSchedule(task *Task, opts *ManagerOptions) (*sunSchedule, error) {
+	schedule := &sunSchedule{
+		event:     task.Cadence,
+		latitude:  opts.Latitude,
+		longitude: opts.Longitude,
+	}
+
+	// Offset from the event
+	offset, err := durationParam(task.CadenceParams, "offset", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if offset > MAX_SUN_OFFSET || offset < -MAX_SUN_OFFSET {
+		return nil, fmt.Errorf("'offset' must be within %s of the event", MAX_SUN_OFFSET)
+	}
+	schedule.offset = offset
+
+	// Timezone, defaulting to the manager's
+	if schedule.location, err = locationParam(task.CadenceParams, opts); err != nil {
+		return nil, err
+	}
+
+	// Coordinate overrides
+	if lat, ok, err := floatParam(task.CadenceParams, "latitude"); err != nil {
+		return nil, err
+	} else if ok {
+		schedule.latitude = lat
+	}
+	if long, ok, err := floatParam(task.CadenceParams, "longitude"); err != nil {
+		return nil, err
+	} else if ok {
+		schedule.longitude = long
+	}
+
+	if schedule.latitude < -90 || schedule.latitude > 90 {
+		return nil, fmt.Errorf("'latitude' must be between -90 and 90")
+	}
+	if schedule.longitude < -180 || schedule.longitude > 180 {
+		return nil, fmt.Errorf("'longitude' must be between -180 and 180")
+	}
+
+	return schedule, nil
+}
+
+// next returns the first run time after the given time. Near the poles there may be no event
+// in the coming days, in which case it asks to be checked again tomorrow.
+func (s *sunSchedule) next(after time.Time) (time.Time, bool) {
+	local := after.In(s.location)
+
+	// Check each calendar day from yesterday on, since offsets can move an event into a neighbouring day
+	var best time.Time
+	for days := -1; days <= sunSearchDays; days++ {
+		year, month, day := local.AddDate(0, 0, days).Date()
+		rise, set := sunrise.SunriseSunset(s.latitude, s.longitude, year, month, day)
+
+		event := rise
+		if s.event == SunsetCadence {
+			event = set
+		}
+		if event.IsZero() {
+			continue
+		}
+
+		run := event.Add(s.offset).In(s.location)
+		if run.After(after) && (best.IsZero() || run.Before(best)) {
+			best = run
+		}
+	}
+
+	if best.IsZero() {
+		log.Printf("[OUTREACH]: No %s found in the %d days after %s", s.event, sunSearchDays, after.Format(time.RFC3339))
+		return after.Add(24 * time.Hour), false
+	}
+
+	return best, true
+}
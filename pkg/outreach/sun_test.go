@@ -0,0 +1,85 @@
+package outreach
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSunScheduleNext(t *testing.T) {
+	opts := &ManagerOptions{Latitude: 51.5, Longitude: -0.12, Timezone: "Europe/London"}
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	sunrise, err := newSunSchedule(&Task{Cadence: SunriseCadence}, opts)
+	require.NoError(t, err)
+
+	// Midsummer sunrise in London is just before 5am local time
+	after := time.Date(2025, time.June, 21, 0, 0, 0, 0, london)
+	next, ok := sunrise.next(after)
+	require.True(t, ok)
+	assert.Equal(t, london, next.Location())
+	assert.Equal(t, 21, next.Day())
+	assert.Equal(t, 4, next.Hour())
+
+	// Once today's event has passed, the next one is tomorrow
+	following, ok := sunrise.next(next)
+	require.True(t, ok)
+	assert.Equal(t, 22, following.Day())
+
+	// Offsets move the run time
+	early, err := newSunSchedule(&Task{Cadence: SunriseCadence, CadenceParams: map[string]any{"offset": "-30m"}}, opts)
+	require.NoError(t, err)
+	earlyNext, ok := early.next(after)
+	require.True(t, ok)
+	assert.Equal(t, next.Add(-30*time.Minute), earlyNext)
+
+	// Offsets in minutes are supported as well
+	late, err := newSunSchedule(&Task{Cadence: SunsetCadence, CadenceParams: map[string]any{"offset": 15}}, opts)
+	require.NoError(t, err)
+	sunset, err := newSunSchedule(&Task{Cadence: SunsetCadence}, opts)
+	require.NoError(t, err)
+	lateNext, _ := late.next(after)
+	sunsetNext, _ := sunset.next(after)
+	assert.Equal(t, sunsetNext.Add(15*time.Minute), lateNext)
+	assert.Equal(t, 21, sunsetNext.Hour())
+}
+
+func TestSunScheduleOverrides(t *testing.T) {
+	opts := &ManagerOptions{Latitude: 51.5, Longitude: -0.12}
+
+	// Coordinates and timezone can be overridden per task
+	schedule, err := newSunSchedule(&Task{Cadence: SunriseCadence, CadenceParams: map[string]any{
+		"latitude":  40.71,
+		"longitude": -74,
+		"timezone":  "America/New_York",
+	}}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 40.71, schedule.latitude)
+	assert.Equal(t, -74.0, schedule.longitude)
+
+	next, ok := schedule.next(time.Date(2025, time.June, 21, 0, 0, 0, 0, schedule.location))
+	require.True(t, ok)
+	assert.Equal(t, "America/New_York", next.Location().String())
+	assert.Equal(t, 5, next.Hour())
+
+	// Polar night has no sunrise
+	polar, err := newSunSchedule(&Task{Cadence: SunriseCadence, CadenceParams: map[string]any{"latitude": 89.9}}, opts)
+	require.NoError(t, err)
+	_, ok = polar.next(time.Date(2025, time.December, 21, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+
+	// Invalid params are rejected
+	for _, params := range []map[string]any{
+		{"offset": "soon"},
+		{"offset": "13h"},
+		{"timezone": "Nowhere/City"},
+		{"latitude": "north"},
+		{"longitude": 200},
+	} {
+		_, err := newSunSchedule(&Task{Cadence: SunriseCadence, CadenceParams: params}, opts)
+		assert.Error(t, err, params)
+	}
+}
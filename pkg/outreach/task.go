@@ -0,0 +1,38 @@
+package outreach
+
+import (
+	"errors"
+	"maps"
+	"slices"
+)
+
+// ErrTaskNotFound is returned when a task doesn't exist
+var ErrTaskNotFound = errors.New("task not found")
+
+// ErrTaskExists is returned when adding a task whose key is already taken
+var ErrTaskExists = errors.New("task already exists")
+
+// TaskStoreInterface defines the storage operations for tasks added through the manager
+type TaskStoreInterface interface {
+	SaveTask(task *Task) error
+	GetTask(key string) (*Task, error)
+	ListTasks() ([]*Task, error)
+	DeleteTask(key string) error
+}
+
+// RunType returns the name of the task's run function, which defaults to its key
+func (t *Task) RunType() string {
+	if t.Type != "" {
+		return t.Type
+	}
+	return t.Key
+}
+
+// Clone returns a copy of the task that shares no slices or maps with the original
+func (t *Task) Clone() *Task {
+	clone := *t
+	clone.ClientIds = slices.Clone(t.ClientIds)
+	clone.Params = maps.Clone(t.Params)
+	clone.CadenceParams = maps.Clone(t.CadenceParams)
+	return &clone
+}
@@ -10,16 +10,18 @@ type TaskReturn struct {
 	Data    any    `json:"data,omitempty"` // Optional extra data from the outreach task
 }
 
-// Task function type
-type TaskRunFunction func(cfg *utils.Config) *TaskReturn
+// Task function type, given the task's params
+type TaskRunFunction func(cfg *utils.Config, params map[string]any) *TaskReturn
 
 // Task represents a single outreach task that can be scheduled and executed
 type Task struct {
 	Key           string         `json:"key" yaml:"key"`                       // Unique identifier for the outreach task
+	Type          string         `json:"type,omitempty" yaml:"type"`           // Run function to use (defaults to the key)
 	ClientIds     []string       `json:"client_ids" yaml:"client_ids"`         // List of client IDs to use for this task
 	Params        map[string]any `json:"params" yaml:"params"`                 // Parameters for the outreach task (must include 'client_ids' array)
-	Cadence       CadenceType    `json:"cadence" yaml:"cadence"`               // Cadence type for scheduling (cron, sunrise, sunset)
+	Cadence       CadenceType    `json:"cadence" yaml:"cadence"`               // Cadence type for scheduling (cron, sunrise, sunset, once, interval, rrule, before_calendar_event)
 	CadenceParams map[string]any `json:"cadence_params" yaml:"cadence_params"` // Parameters specific to the cadence type
+	Paused        bool           `json:"paused" yaml:"paused"`                 // Paused tasks stay loaded but are not scheduled
 
 	Run TaskRunFunction `json:"-" yaml:"-"` // Internal function to execute the task (set when loaded)
 }
@@ -52,3 +54,12 @@ type Response struct {
 		CallbackUrl string `json:"callback_url"` // Callback URL of the client implementation
 	} `json:"clients"` // List of clients, in priority order, to send the response to
 }
+
+// ClientIds returns the IDs of the clients the response is sent to, in priority order
+func (r *Response) ClientIds() []string {
+	ids := make([]string, len(r.Clients))
+	for i, client := range r.Clients {
+		ids[i] = client.Id
+	}
+	return ids
+}
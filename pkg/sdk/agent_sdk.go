@@ -1,9 +1,15 @@
 package sdk
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ethanbaker/api/pkg/api_types"
 )
@@ -45,6 +51,55 @@ func (c *Client) GetSession(ctx context.Context, uuid string) (*Session, error)
 	return &out.Data, nil
 }
 
+// List sessions matching the request filters. Pass the returned NextCursor back in to get the next page
+func (c *Client) ListSessions(ctx context.Context, req *ListSessionsRequest) (*ListSessionsResponse, error) {
+	path := "/api/agent/sessions"
+
+	// Build query parameters from the provided filters
+	query := url.Values{}
+	if req != nil {
+		if req.UserID != "" {
+			query.Set("user_id", req.UserID)
+		}
+		if !req.CreatedAfter.IsZero() {
+			query.Set("created_after", req.CreatedAfter.Format(time.RFC3339))
+		}
+		if !req.CreatedBefore.IsZero() {
+			query.Set("created_before", req.CreatedBefore.Format(time.RFC3339))
+		}
+		if !req.UpdatedAfter.IsZero() {
+			query.Set("updated_after", req.UpdatedAfter.Format(time.RFC3339))
+		}
+		if !req.UpdatedBefore.IsZero() {
+			query.Set("updated_before", req.UpdatedBefore.Format(time.RFC3339))
+		}
+		if req.Cursor != "" {
+			query.Set("cursor", req.Cursor)
+		}
+		if req.Limit > 0 {
+			query.Set("limit", strconv.Itoa(req.Limit))
+		}
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var out ApiResponse[ListSessionsResponse]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to list sessions: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error listing sessions (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
 // Send a message to a session provided by UUID
 func (c *Client) SendMessage(ctx context.Context, uuid string, msg *PostMessageRequest) (*PostMessageResponse, error) {
 	path := fmt.Sprintf("/api/agent/sessions/%s/message", uuid)
@@ -57,6 +112,151 @@ func (c *Client) SendMessage(ctx context.Context, uuid string, msg *PostMessageR
 	return &out.Data, nil
 }
 
+// Send a message to a session provided by UUID and stream the agent's events as they happen.
+// The events channel is closed when the run finishes; any error is sent on the error channel before it closes
+func (c *Client) StreamMessage(ctx context.Context, uuid string, msg *PostMessageRequest) (<-chan StreamEvent, <-chan error, error) {
+	path := fmt.Sprintf("/api/agent/sessions/%s/message/stream", uuid)
+
+	body, err := c.NewRequest(ctx, http.MethodPost, path, msg, nil).WithApiKey(c.apiKey).doStream()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events := make(chan StreamEvent)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(events)
+		defer body.Close()
+
+		// Read server-sent events, which are separated by blank lines
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+		var data strings.Builder
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			// Collect data lines until the event is complete
+			if value, ok := strings.CutPrefix(line, "data:"); ok {
+				data.WriteString(strings.TrimPrefix(value, " "))
+				continue
+			}
+			if line != "" || data.Len() == 0 {
+				continue
+			}
+
+			// Decode the completed event
+			var event StreamEvent
+			if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+				errs <- fmt.Errorf("failed to decode stream event: %w", err)
+				return
+			}
+			data.Reset()
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+
+			// Surface run failures as errors
+			if event.Type == StreamEventError {
+				errs <- fmt.Errorf("error streaming message: %s", event.Error)
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			errs <- err
+		}
+	}()
+
+	return events, errs, nil
+}
+
+// Get the token usage over a period, per model
+func (c *Client) GetUsage(ctx context.Context, req *UsageRequest) (*UsageResponse, error) {
+	path := "/api/agent/usage"
+
+	// Build query parameters from the provided filters
+	query := url.Values{}
+	if req != nil {
+		if req.UserID != "" {
+			query.Set("user_id", req.UserID)
+		}
+		if !req.Since.IsZero() {
+			query.Set("since", req.Since.Format(time.RFC3339))
+		}
+		if !req.Until.IsZero() {
+			query.Set("until", req.Until.Format(time.RFC3339))
+		}
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var out ApiResponse[UsageResponse]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to get usage: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error getting usage (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// List the recorded runs of a session, newest first
+func (c *Client) ListRuns(ctx context.Context, uuid string, req *ListRunsRequest) ([]Run, error) {
+	path := fmt.Sprintf("/api/agent/sessions/%s/runs", uuid)
+	if req != nil && req.Limit > 0 {
+		path += "?" + url.Values{"limit": {strconv.Itoa(req.Limit)}}.Encode()
+	}
+
+	var out ApiResponse[[]Run]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to list runs: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error listing runs (%s): %v", out.Message, out.Error)
+	}
+
+	return out.Data, nil
+}
+
+// Get the record of a single run by ID
+func (c *Client) GetRun(ctx context.Context, id string) (*Run, error) {
+	path := fmt.Sprintf("/api/agent/runs/%s", id)
+
+	var out ApiResponse[Run]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to get run: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error getting run (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
 // Delete an existing session by UUID
 func (c *Client) DeleteSession(ctx context.Context, uuid string) error {
 	path := fmt.Sprintf("/api/agent/sessions/%s", uuid)
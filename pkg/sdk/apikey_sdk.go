@@ -0,0 +1,72 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ethanbaker/api/pkg/api_types"
+)
+
+// Mint a new API key. The returned key can't be retrieved again
+func (c *Client) CreateApiKey(ctx context.Context, req *CreateApiKeyRequest) (*CreateApiKeyResponse, error) {
+	path := "/api/keys"
+
+	var out ApiResponse[CreateApiKeyResponse]
+	if err := c.NewRequest(ctx, http.MethodPost, path, req, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to create API key: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error creating API key (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// List API keys, optionally including revoked ones
+func (c *Client) ListApiKeys(ctx context.Context, includeRevoked bool) ([]ApiKey, error) {
+	path := "/api/keys"
+	if includeRevoked {
+		path += "?include_revoked=true"
+	}
+
+	var out ApiResponse[[]ApiKey]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to list API keys: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error listing API keys (%s): %v", out.Message, out.Error)
+	}
+
+	return out.Data, nil
+}
+
+// Revoke an API key by ID
+func (c *Client) RevokeApiKey(ctx context.Context, id uint) (*ApiKey, error) {
+	path := fmt.Sprintf("/api/keys/%d", id)
+
+	var out ApiResponse[ApiKey]
+	if err := c.NewRequest(ctx, http.MethodDelete, path, nil, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to revoke API key: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error revoking API key (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
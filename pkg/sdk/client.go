@@ -39,14 +39,14 @@ func (rb *RequestBuilder) WithClientCredentials(id, secret string) *RequestBuild
 	return rb
 }
 
-// doJSON is a helper to perform JSON requests to the backend
-func (rb *RequestBuilder) doJSON() error {
+// newHTTPRequest is a helper to build the underlying HTTP request with body and authentication headers
+func (rb *RequestBuilder) newHTTPRequest() (*http.Request, error) {
 	// Create request body if input is provided
 	var body io.Reader
 	if rb.in != nil {
 		b, err := json.Marshal(rb.in)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		body = bytes.NewBuffer(b)
 	}
@@ -54,7 +54,7 @@ func (rb *RequestBuilder) doJSON() error {
 	// Create the request
 	req, err := http.NewRequestWithContext(rb.ctx, rb.method, rb.client.baseURL+rb.path, body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
@@ -62,11 +62,24 @@ func (rb *RequestBuilder) doJSON() error {
 	if rb.apiKey != "" {
 		req.Header.Set("X-API-KEY", rb.apiKey)
 	}
+	if rb.client.userID != "" {
+		req.Header.Set("X-USER-ID", rb.client.userID)
+	}
 	if rb.clientID != "" && rb.clientSecret != "" {
 		req.Header.Set("X-CLIENT-ID", rb.clientID)
 		req.Header.Set("X-CLIENT-SECRET", rb.clientSecret)
 	}
 
+	return req, nil
+}
+
+// doJSON is a helper to perform JSON requests to the backend
+func (rb *RequestBuilder) doJSON() error {
+	req, err := rb.newHTTPRequest()
+	if err != nil {
+		return err
+	}
+
 	// Perform the request
 	resp, err := rb.client.httpClient.Do(req)
 	if err != nil {
@@ -90,22 +103,56 @@ func (rb *RequestBuilder) doJSON() error {
 	return dec.Decode(rb.out)
 }
 
+// doStream is a helper to perform requests that return server-sent events. The caller must close the returned body
+func (rb *RequestBuilder) doStream() (io.ReadCloser, error) {
+	req, err := rb.newHTTPRequest()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// Perform the request without the client timeout, streams are bounded by the request context instead
+	resp, err := rb.client.streamClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// On error, read body and return error
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("[BACKEND]: backend '%s %s' failed: %d: %s", rb.method, rb.path, resp.StatusCode, string(b))
+	}
+
+	return resp.Body, nil
+}
+
 // Client wraps calls to the AI assistant backend
 type Client struct {
-	baseURL    string
-	apiKey     string
-	httpClient *http.Client
+	baseURL      string
+	apiKey       string
+	userID       string // User the client acts for, if any
+	httpClient   *http.Client
+	streamClient *http.Client
 }
 
 // NewClient creates a new client for the AI assistant backend
 func NewClient(baseURL, apiKey string) *Client {
 	return &Client{
-		baseURL:    baseURL,
-		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: 120 * time.Second},
+		baseURL:      baseURL,
+		apiKey:       apiKey,
+		httpClient:   &http.Client{Timeout: 120 * time.Second},
+		streamClient: &http.Client{},
 	}
 }
 
+// AsUser returns a copy of the client that acts for a user. The backend only lets it access that user's sessions
+func (c *Client) AsUser(userID string) *Client {
+	clone := *c
+	clone.userID = userID
+	return &clone
+}
+
 // NewRequest creates a new request builder using a provided client
 func (c *Client) NewRequest(ctx context.Context, method, path string, in, out any) *RequestBuilder {
 	return &RequestBuilder{
@@ -66,6 +66,23 @@ type CreateSessionRequest struct {
 	UserID string `json:"user_id" binding:"required"`
 }
 
+// ListSessionsRequest represents the query parameters for listing sessions
+type ListSessionsRequest struct {
+	UserID        string    `json:"user_id,omitempty" form:"user_id"`               // Only include sessions owned by this user
+	CreatedAfter  time.Time `json:"created_after,omitempty" form:"created_after"`   // Only include sessions created at or after this time (RFC3339)
+	CreatedBefore time.Time `json:"created_before,omitempty" form:"created_before"` // Only include sessions created before this time (RFC3339)
+	UpdatedAfter  time.Time `json:"updated_after,omitempty" form:"updated_after"`   // Only include sessions updated at or after this time (RFC3339)
+	UpdatedBefore time.Time `json:"updated_before,omitempty" form:"updated_before"` // Only include sessions updated before this time (RFC3339)
+	Cursor        string    `json:"cursor,omitempty" form:"cursor"`                 // Cursor returned by a previous page
+	Limit         int       `json:"limit,omitempty" form:"limit"`                   // Maximum number of sessions to return
+}
+
+// ListSessionsResponse represents a page of sessions, ordered by most recently updated
+type ListSessionsResponse struct {
+	Sessions   []Session `json:"sessions"`
+	NextCursor string    `json:"next_cursor,omitempty"` // Empty when there are no more sessions
+}
+
 // PostMessageRequest represents the request body for adding a message to a session
 type PostMessageRequest struct {
 	Content string `json:"content" binding:"required"`
@@ -78,6 +95,112 @@ type PostMessageResponse struct {
 	FinalOutput string `json:"final_output"`
 }
 
+// StreamEventType represents the kind of event sent while streaming a message
+type StreamEventType string
+
+const (
+	StreamEventTextDelta   StreamEventType = "text_delta"   // Partial text output from the current agent
+	StreamEventToolCall    StreamEventType = "tool_call"    // An agent called a tool
+	StreamEventToolOutput  StreamEventType = "tool_output"  // A tool returned its output
+	StreamEventHandoff     StreamEventType = "handoff"      // An agent handed off to another agent
+	StreamEventFinalOutput StreamEventType = "final_output" // The run finished with a final output
+	StreamEventError       StreamEventType = "error"        // The run failed
+)
+
+// StreamEvent represents a single server-sent event emitted while streaming a message
+type StreamEvent struct {
+	Type  StreamEventType `json:"type"`            // Type of the event
+	Agent string          `json:"agent,omitempty"` // Agent that produced the event
+
+	Delta string `json:"delta,omitempty"` // Text delta (text_delta)
+
+	ToolName  string `json:"tool_name,omitempty"` // Name of the called tool (tool_call)
+	CallID    string `json:"call_id,omitempty"`   // Tool call ID (tool_call, tool_output)
+	Arguments string `json:"arguments,omitempty"` // JSON arguments passed to the tool (tool_call)
+	Output    string `json:"output,omitempty"`    // Output returned by the tool (tool_output)
+
+	FromAgent string `json:"from_agent,omitempty"` // Agent handing off (handoff)
+	ToAgent   string `json:"to_agent,omitempty"`   // Agent receiving the handoff (handoff)
+
+	FinalOutput string `json:"final_output,omitempty"` // Final output of the run (final_output)
+	Error       string `json:"error,omitempty"`        // Error message (error)
+}
+
+// LimitError describes the rate limit or token budget a request exceeded. It is the error of 429 responses
+type LimitError struct {
+	Limit   string    `json:"limit"`          // One of rate_limit_key, rate_limit_user, daily_budget or monthly_budget
+	Max     uint64    `json:"max"`            // Requests per minute for rate limits, or tokens per period for budgets
+	Used    uint64    `json:"used,omitempty"` // Tokens used in the current period (budgets only)
+	RetryAt time.Time `json:"retry_at"`       // When the request can be retried
+}
+
+// UsageRequest represents the query parameters for reporting token usage
+type UsageRequest struct {
+	UserID string    `json:"user_id,omitempty" form:"user_id"` // Only include usage of this user
+	Since  time.Time `json:"since,omitempty" form:"since"`     // Only include usage at or after this time (RFC3339, defaults to the start of the month)
+	Until  time.Time `json:"until,omitempty" form:"until"`     // Only include usage before this time (RFC3339)
+}
+
+// ModelUsage represents the tokens used on a model
+type ModelUsage struct {
+	Model        string `json:"model,omitempty"`
+	Requests     uint64 `json:"requests"`
+	InputTokens  uint64 `json:"input_tokens"`
+	OutputTokens uint64 `json:"output_tokens"`
+	TotalTokens  uint64 `json:"total_tokens"`
+}
+
+// BudgetUsage represents how much of a token budget a user has used
+type BudgetUsage struct {
+	Period  string    `json:"period"` // daily or monthly
+	Limit   uint64    `json:"limit"`
+	Used    uint64    `json:"used"`
+	ResetAt time.Time `json:"reset_at"`
+}
+
+// UsageResponse represents the token usage over a period
+type UsageResponse struct {
+	UserID  string        `json:"user_id,omitempty"`
+	Since   time.Time     `json:"since"`
+	Until   time.Time     `json:"until"`
+	Models  []ModelUsage  `json:"models"`            // Usage per model
+	Total   ModelUsage    `json:"total"`             // Usage across every model
+	Budgets []BudgetUsage `json:"budgets,omitempty"` // Configured budgets of the user
+}
+
+// ListRunsRequest represents the query parameters for listing the runs of a session
+type ListRunsRequest struct {
+	Limit int `json:"limit,omitempty" form:"limit"` // Most runs to return, newest first (0 returns every run)
+}
+
+// Run represents the record of a single agent run on a session
+type Run struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id,omitempty"`
+
+	AgentChain []string   `json:"agent_chain"`     // Agents in the order they were handed off to
+	Model      string     `json:"model,omitempty"` // Models used, comma separated if there were several
+	Usage      ModelUsage `json:"usage"`           // Tokens used across the run
+
+	DurationMs int64         `json:"duration_ms"`
+	Error      string        `json:"error,omitempty"` // Why the run failed, if it did
+	ToolCalls  []RunToolCall `json:"tool_calls"`
+}
+
+// RunToolCall represents a tool call made during an agent run
+type RunToolCall struct {
+	Sequence   int       `json:"sequence"` // Position of the call in the run
+	AgentID    string    `json:"agent_id"` // Agent that called the tool
+	Tool       string    `json:"tool"`
+	Arguments  string    `json:"arguments"`
+	Result     string    `json:"result"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
 // Session represents a user session
 type Session struct {
 	ID        string         `json:"id"`
@@ -180,7 +303,8 @@ type OutreachRegisterRequest struct {
 
 // OutreachRegisterResponse represents the successful registration response
 type OutreachRegisterResponse struct {
-	ClientId string `json:"client_id"` // The registered client ID
+	ClientId   string `json:"client_id"`   // The registered client ID
+	SigningKey []byte `json:"signing_key"` // Key to verify outreach requests with, only returned here
 }
 
 // OutreachUnregisterRequest represents the request to unregister an implementation
@@ -188,6 +312,19 @@ type OutreachUnregisterRequest struct {
 	ClientId string `json:"client_id" binding:"required"` // Client ID to unregister
 }
 
+// OutreachRotateSecretRequest represents the request to rotate an implementation's client secret
+type OutreachRotateSecretRequest struct {
+	NewClientSecret    string `json:"new_client_secret" binding:"required"` // Secret that replaces the current one
+	GracePeriodSeconds *int   `json:"grace_period_seconds,omitempty"`       // How long the current secret keeps working (defaults to 24 hours)
+}
+
+// OutreachRotateSecretResponse represents the successful rotation response
+type OutreachRotateSecretResponse struct {
+	ClientId                string    `json:"client_id"`                  // The rotated client ID
+	SigningKey              []byte    `json:"signing_key"`                // New key to verify outreach requests with, only returned here
+	PreviousSecretExpiresAt time.Time `json:"previous_secret_expires_at"` // When the old secret and signing key stop being accepted
+}
+
 // OutreachImplementation represents an implementation in API responses
 type OutreachImplementation struct {
 	ClientId    string `json:"client_id"`    // Unique identifier for the implementation
@@ -202,7 +339,31 @@ type OutreachListImplementationsResponse struct {
 
 // OutreachTaskStatus represents the status of task operations
 type OutreachTaskStatus struct {
-	Loaded int `json:"loaded"` // Number of tasks loaded
+	Loaded int                 `json:"loaded"` // Number of tasks loaded
+	Paused int                 `json:"paused"` // Number of loaded tasks that are paused
+	Tasks  []OutreachTaskState `json:"tasks"`  // Schedule and recent runs of each task
+}
+
+// OutreachTaskState represents a task's schedule and a summary of its recent runs
+type OutreachTaskState struct {
+	Key      string           `json:"key"`
+	Cadence  string           `json:"cadence"`
+	Paused   bool             `json:"paused"`
+	NextRun  *time.Time       `json:"next_run"` // nil if the task is paused or its next run can't be found
+	LastRun  *OutreachTaskRun `json:"last_run"` // nil if the task hasn't run since the service started
+	Runs     int              `json:"runs"`     // Number of recent runs kept
+	Failures int              `json:"failures"` // Recent runs that failed, panicked, or weren't delivered
+}
+
+// OutreachTaskRun represents a single run of an outreach task
+type OutreachTaskRun struct {
+	Id            uint64    `json:"id"`
+	Trigger       string    `json:"trigger"` // schedule or manual
+	StartedAt     time.Time `json:"started_at"`
+	DurationMs    int64     `json:"duration_ms"`              // Time spent running the task, not including delivery
+	Outcome       string    `json:"outcome"`                  // queued, delivered, undelivered, empty, preview, failed, or panicked
+	Error         string    `json:"error,omitempty"`          // Why the run or its delivery failed
+	IdempotencyId string    `json:"idempotency_id,omitempty"` // ID of the response the run produced
 }
 
 // OutreachStatusResponse represents the overall status of the outreach service
@@ -224,3 +385,216 @@ type OutreachRequest struct {
 	Content string `json:"content"`        // Content to be sent out (generated by the task)
 	Data    any    `json:"data,omitempty"` // Extra data for the request
 }
+
+// OutreachTask represents a scheduled outreach task
+type OutreachTask struct {
+	Key           string         `json:"key"`                      // Unique identifier for the task
+	Type          string         `json:"type,omitempty"`           // Run function to use (defaults to the key)
+	ClientIds     []string       `json:"client_ids"`               // Implementations to deliver to, in priority order
+	Params        map[string]any `json:"params,omitempty"`         // Parameters for the task
+	Cadence       string         `json:"cadence"`                  // Cadence type (cron, sunrise, sunset, once, interval, rrule, before_calendar_event)
+	CadenceParams map[string]any `json:"cadence_params,omitempty"` // Parameters for the cadence, such as the cron 'spec'
+	Paused        bool           `json:"paused"`                   // Paused tasks are not scheduled
+}
+
+// CreateOutreachTaskRequest represents the request body for adding a task
+type CreateOutreachTaskRequest struct {
+	Key           string         `json:"key" binding:"required"`
+	Type          string         `json:"type,omitempty"`
+	ClientIds     []string       `json:"client_ids" binding:"required"`
+	Params        map[string]any `json:"params,omitempty"`
+	Cadence       string         `json:"cadence" binding:"required"`
+	CadenceParams map[string]any `json:"cadence_params,omitempty"`
+	Paused        bool           `json:"paused,omitempty"`
+}
+
+// UpdateOutreachTaskRequest represents the request body for replacing a task
+type UpdateOutreachTaskRequest struct {
+	Type          string         `json:"type,omitempty"`
+	ClientIds     []string       `json:"client_ids" binding:"required"`
+	Params        map[string]any `json:"params,omitempty"`
+	Cadence       string         `json:"cadence" binding:"required"`
+	CadenceParams map[string]any `json:"cadence_params,omitempty"`
+	Paused        bool           `json:"paused,omitempty"`
+}
+
+// RunOutreachTaskRequest represents the query parameters for running a task immediately
+type RunOutreachTaskRequest struct {
+	Preview bool `json:"preview,omitempty" form:"preview"` // Return the output without delivering it to clients
+}
+
+// RunOutreachTaskResponse represents the output of a task that was run immediately
+type RunOutreachTaskResponse struct {
+	Key       string `json:"key"`
+	Preview   bool   `json:"preview"`        // Whether the run was a preview
+	Delivered bool   `json:"delivered"`      // Whether the output was queued for delivery to clients
+	Empty     bool   `json:"empty"`          // Whether the task had nothing to send
+	Content   string `json:"content"`        // Content generated by the task
+	Data      any    `json:"data,omitempty"` // Extra data generated by the task
+}
+
+// ListOutreachDeliveriesRequest represents the query parameters for listing deliveries
+type ListOutreachDeliveriesRequest struct {
+	Status string `json:"status,omitempty" form:"status"` // Only include deliveries with this status (pending, retrying, delivered, dead)
+	Limit  int    `json:"limit,omitempty" form:"limit"`   // Maximum number of deliveries to return (defaults to 50)
+	Offset int    `json:"offset,omitempty" form:"offset"`
+}
+
+// OutreachDelivery represents a task response and the state of delivering it
+type OutreachDelivery struct {
+	Id        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	IdempotencyId string     `json:"idempotency_id"` // Idempotency ID sent to implementations
+	Key           string     `json:"key"`            // Key of the task that produced the response
+	Content       string     `json:"content"`
+	Status        string     `json:"status"`          // pending, retrying, delivered, or dead
+	Rounds        int        `json:"rounds"`          // Rounds through the client list since creation or the last replay
+	AttemptCount  int        `json:"attempt_count"`   // Total attempts across all clients and replays
+	NextAttemptAt *time.Time `json:"next_attempt_at"` // When the next round is due
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	DeliveredTo   string     `json:"delivered_to,omitempty"` // Client ID that accepted the response
+	LastError     string     `json:"last_error,omitempty"`
+
+	Attempts    []OutreachDeliveryAttempt `json:"attempts"`
+	DeadLetters []OutreachDeadLetter      `json:"dead_letters"`
+}
+
+// OutreachDeliveryAttempt represents a single try at sending a response to a client
+type OutreachDeliveryAttempt struct {
+	Id         uint      `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ClientId   string    `json:"client_id"`
+	StatusCode int       `json:"status_code"` // 0 if no response was received
+	Error      string    `json:"error,omitempty"`
+	LatencyMs  int64     `json:"latency_ms"`
+}
+
+// OutreachDeadLetter represents a time a delivery ran out of retries
+type OutreachDeadLetter struct {
+	Id         uint       `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Reason     string     `json:"reason"`
+	ReplayedAt *time.Time `json:"replayed_at"`
+}
+
+/** Memory Module DTOs */
+
+// Fact represents a key fact stored for a user
+type Fact struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID          string     `json:"user_id"`
+	Key             string     `json:"key"`
+	Value           string     `json:"value"`
+	Tags            []string   `json:"tags,omitempty"`              // Labels used to group the fact
+	SourceSessionID string     `json:"source_session_id,omitempty"` // Session the fact was stored from
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`        // When the fact will be forgotten
+
+	Score float32 `json:"score,omitempty"` // Similarity to the query (semantic search only)
+}
+
+// ListFactsRequest represents the query parameters for listing a user's facts
+type ListFactsRequest struct {
+	UserID string `json:"user_id" form:"user_id" binding:"required"` // User that owns the facts
+	Tag    string `json:"tag,omitempty" form:"tag"`                  // Only include facts with this tag
+}
+
+// CreateFactRequest represents the request body for storing a new fact
+type CreateFactRequest struct {
+	UserID    string     `json:"user_id" binding:"required"`
+	Key       string     `json:"key" binding:"required"`
+	Value     string     `json:"value" binding:"required"`
+	Tags      []string   `json:"tags,omitempty"`       // Labels used to group the fact
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // When the fact should be forgotten (must be in the future)
+}
+
+// UpdateFactRequest represents the request body for replacing an existing fact's value, tags and expiry
+type UpdateFactRequest struct {
+	UserID    string     `json:"user_id" binding:"required"`
+	Value     string     `json:"value" binding:"required"`
+	Tags      []string   `json:"tags,omitempty"`       // Labels used to group the fact
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // When the fact should be forgotten (must be in the future)
+}
+
+// SearchFactsRequest represents the query parameters for searching a user's facts
+type SearchFactsRequest struct {
+	UserID   string `json:"user_id" form:"user_id" binding:"required"` // User that owns the facts
+	Query    string `json:"query" form:"query" binding:"required"`     // Text to search for
+	Semantic bool   `json:"semantic,omitempty" form:"semantic"`        // Search by meaning instead of by text
+	Limit    int    `json:"limit,omitempty" form:"limit"`              // Maximum number of results (semantic search only)
+}
+
+// FactExport represents every fact of a user, as exported and imported in bulk
+type FactExport struct {
+	UserID     string    `json:"user_id"`
+	ExportedAt time.Time `json:"exported_at"`
+	Facts      []Fact    `json:"facts"`
+}
+
+// ImportFactsRequest represents the request body for importing facts in bulk. An export can be sent as is.
+type ImportFactsRequest struct {
+	UserID    string `json:"user_id" binding:"required"` // User the facts are imported for
+	Facts     []Fact `json:"facts" binding:"required"`   // Facts to import; only key, value, tags and expires_at are used
+	Overwrite bool   `json:"overwrite,omitempty"`        // Replace facts that already exist instead of skipping them
+}
+
+// ImportFactsResponse summarizes a bulk import
+type ImportFactsResponse struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"` // Existing facts that were kept, or facts that already expired
+}
+
+// FactRevision represents a single change to a fact
+type FactRevision struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	FactID        uint    `json:"fact_id"`
+	UserID        string  `json:"user_id"`
+	Key           string  `json:"key"`
+	Operation     string  `json:"operation"`            // set, delete, expire or revert
+	PreviousValue *string `json:"previous_value"`       // nil if the fact didn't exist before the change
+	NewValue      *string `json:"new_value"`            // nil if the change removed the fact
+	SessionID     string  `json:"session_id,omitempty"` // Session that triggered the change
+}
+
+// RevertFactRequest represents the request body for reverting a change to a fact
+type RevertFactRequest struct {
+	UserID     string `json:"user_id" binding:"required"`     // User that owns the fact
+	RevisionID uint   `json:"revision_id" binding:"required"` // Revision to undo
+}
+
+/** API Key Module DTOs */
+
+// ApiKey represents a stored API key. The key itself is only returned once, when it is created
+type ApiKey struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`              // Start of the key, to recognize it
+	Scopes     []string   `json:"scopes"`              // Scopes the key is granted, such as agent:write or memory:read
+	UserID     string     `json:"user_id,omitempty"`   // User the key is bound to, if any
+	ClientID   string     `json:"client_id,omitempty"` // Outreach implementation that delivers reminders set through the key, if any
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateApiKeyRequest represents the request body for minting a new API key
+type CreateApiKeyRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	Scopes   []string `json:"scopes" binding:"required"` // Scopes to grant the key
+	UserID   string   `json:"user_id,omitempty"`         // Bind the key to a user, so it can only act for and read the sessions of that user
+	ClientID string   `json:"client_id,omitempty"`       // Deliver reminders set through the key with this outreach implementation
+}
+
+// CreateApiKeyResponse represents a newly minted API key
+type CreateApiKeyResponse struct {
+	ApiKey
+	Key string `json:"key"` // The key itself. It can't be retrieved again
+}
@@ -0,0 +1,212 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ethanbaker/api/pkg/api_types"
+)
+
+// List a user's facts, optionally only those with a tag
+func (c *Client) ListFacts(ctx context.Context, req *ListFactsRequest) ([]Fact, error) {
+	query := url.Values{}
+	query.Set("user_id", req.UserID)
+	if req.Tag != "" {
+		query.Set("tag", req.Tag)
+	}
+	path := "/api/memory/facts?" + query.Encode()
+
+	var out ApiResponse[[]Fact]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to list facts: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error listing facts (%s): %v", out.Message, out.Error)
+	}
+
+	return out.Data, nil
+}
+
+// Store a new fact. Fails if the user already has a fact with the same key
+func (c *Client) CreateFact(ctx context.Context, req *CreateFactRequest) (*Fact, error) {
+	path := "/api/memory/facts"
+
+	var out ApiResponse[Fact]
+	if err := c.NewRequest(ctx, http.MethodPost, path, req, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to create fact: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error creating fact (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// Get a user's fact by key
+func (c *Client) GetFact(ctx context.Context, userID, key string) (*Fact, error) {
+	path := fmt.Sprintf("/api/memory/facts/%s?user_id=%s", url.PathEscape(key), url.QueryEscape(userID))
+
+	var out ApiResponse[Fact]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to get fact: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error getting fact (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// Replace an existing fact's value, tags and expiry
+func (c *Client) UpdateFact(ctx context.Context, key string, req *UpdateFactRequest) (*Fact, error) {
+	path := fmt.Sprintf("/api/memory/facts/%s", url.PathEscape(key))
+
+	var out ApiResponse[Fact]
+	if err := c.NewRequest(ctx, http.MethodPut, path, req, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to update fact: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error updating fact (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// Delete a user's fact by key
+func (c *Client) DeleteFact(ctx context.Context, userID, key string) error {
+	path := fmt.Sprintf("/api/memory/facts/%s?user_id=%s", url.PathEscape(key), url.QueryEscape(userID))
+
+	return c.NewRequest(ctx, http.MethodDelete, path, nil, nil).WithApiKey(c.apiKey).doJSON()
+}
+
+// Search a user's facts by text, or by meaning when req.Semantic is set
+func (c *Client) SearchFacts(ctx context.Context, req *SearchFactsRequest) ([]Fact, error) {
+	query := url.Values{}
+	query.Set("user_id", req.UserID)
+	query.Set("query", req.Query)
+	if req.Semantic {
+		query.Set("semantic", "true")
+	}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+	path := "/api/memory/search?" + query.Encode()
+
+	var out ApiResponse[[]Fact]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to search facts: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error searching facts (%s): %v", out.Message, out.Error)
+	}
+
+	return out.Data, nil
+}
+
+// Export all of a user's facts
+func (c *Client) ExportFacts(ctx context.Context, userID string) (*FactExport, error) {
+	path := "/api/memory/export?user_id=" + url.QueryEscape(userID)
+
+	var out ApiResponse[FactExport]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to export facts: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error exporting facts (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// Import facts in bulk, such as the facts of a previous export
+func (c *Client) ImportFacts(ctx context.Context, req *ImportFactsRequest) (*ImportFactsResponse, error) {
+	path := "/api/memory/import"
+
+	var out ApiResponse[ImportFactsResponse]
+	if err := c.NewRequest(ctx, http.MethodPost, path, req, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to import facts: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error importing facts (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// Get the revisions of a user's fact, newest first
+func (c *Client) GetFactHistory(ctx context.Context, userID, key string) ([]FactRevision, error) {
+	path := fmt.Sprintf("/api/memory/facts/%s/history?user_id=%s", url.PathEscape(key), url.QueryEscape(userID))
+
+	var out ApiResponse[[]FactRevision]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to get fact history: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error getting fact history (%s): %v", out.Message, out.Error)
+	}
+
+	return out.Data, nil
+}
+
+// Undo a revision of a user's fact. The returned fact is nil if the fact didn't exist before the revision
+func (c *Client) RevertFact(ctx context.Context, key string, req *RevertFactRequest) (*Fact, error) {
+	path := fmt.Sprintf("/api/memory/facts/%s/revert", url.PathEscape(key))
+
+	var out ApiResponse[*Fact]
+	if err := c.NewRequest(ctx, http.MethodPost, path, req, &out).WithApiKey(c.apiKey).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to revert fact: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error reverting fact (%s): %v", out.Message, out.Error)
+	}
+
+	return out.Data, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/ethanbaker/api/pkg/api_types"
 )
@@ -49,6 +51,27 @@ func (c *Client) UnregisterImplementation(ctx context.Context, clientId string,
 	return nil
 }
 
+// RotateImplementationSecret replaces an implementation's client secret.
+// The credentials used to authenticate may be the current secret or one still in its grace period.
+func (c *Client) RotateImplementationSecret(ctx context.Context, creds OutreachCredentials, req *OutreachRotateSecretRequest) (*OutreachRotateSecretResponse, error) {
+	path := "/api/outreach/implementations/rotate"
+
+	var out ApiResponse[OutreachRotateSecretResponse]
+	if err := c.NewRequest(ctx, http.MethodPost, path, req, &out).WithClientCredentials(creds.ClientId, creds.ClientSecret).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to rotate implementation secret: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error rotating implementation secret (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
 // GetImplementations retrieves all registered implementations
 func (c *Client) GetImplementations(ctx context.Context, creds OutreachCredentials) (*OutreachListImplementationsResponse, error) {
 	path := "/api/outreach/implementations"
@@ -88,3 +111,237 @@ func (c *Client) GetOutreachStatus(ctx context.Context, creds OutreachCredential
 
 	return &out.Data, nil
 }
+
+// ListOutreachDeliveries retrieves the task responses sent to the client and their delivery attempts, newest first
+func (c *Client) ListOutreachDeliveries(ctx context.Context, creds OutreachCredentials, req *ListOutreachDeliveriesRequest) ([]OutreachDelivery, error) {
+	query := url.Values{}
+	if req.Status != "" {
+		query.Set("status", req.Status)
+	}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+	if req.Offset > 0 {
+		query.Set("offset", strconv.Itoa(req.Offset))
+	}
+	path := "/api/outreach/deliveries?" + query.Encode()
+
+	var out ApiResponse[[]OutreachDelivery]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithClientCredentials(creds.ClientId, creds.ClientSecret).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to list deliveries: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error listing deliveries (%s): %v", out.Message, out.Error)
+	}
+
+	return out.Data, nil
+}
+
+// ReplayOutreachDelivery sends a delivered or dead-lettered response for the client again
+func (c *Client) ReplayOutreachDelivery(ctx context.Context, creds OutreachCredentials, id uint) (*OutreachDelivery, error) {
+	path := fmt.Sprintf("/api/outreach/deliveries/%d/replay", id)
+
+	var out ApiResponse[OutreachDelivery]
+	if err := c.NewRequest(ctx, http.MethodPost, path, nil, &out).WithClientCredentials(creds.ClientId, creds.ClientSecret).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to replay delivery: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error replaying delivery (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// ListOutreachTasks retrieves all loaded outreach tasks
+func (c *Client) ListOutreachTasks(ctx context.Context, creds OutreachCredentials) ([]OutreachTask, error) {
+	path := "/api/outreach/tasks"
+
+	var out ApiResponse[[]OutreachTask]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithClientCredentials(creds.ClientId, creds.ClientSecret).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to list tasks: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error listing tasks (%s): %v", out.Message, out.Error)
+	}
+
+	return out.Data, nil
+}
+
+// CreateOutreachTask adds and schedules a new outreach task
+func (c *Client) CreateOutreachTask(ctx context.Context, creds OutreachCredentials, req *CreateOutreachTaskRequest) (*OutreachTask, error) {
+	path := "/api/outreach/tasks"
+
+	var out ApiResponse[OutreachTask]
+	if err := c.NewRequest(ctx, http.MethodPost, path, req, &out).WithClientCredentials(creds.ClientId, creds.ClientSecret).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to create task: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error creating task (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// GetOutreachTask retrieves a single outreach task
+func (c *Client) GetOutreachTask(ctx context.Context, creds OutreachCredentials, key string) (*OutreachTask, error) {
+	path := "/api/outreach/tasks/" + url.PathEscape(key)
+
+	var out ApiResponse[OutreachTask]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithClientCredentials(creds.ClientId, creds.ClientSecret).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to get task: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error getting task (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// UpdateOutreachTask replaces and reschedules an outreach task
+func (c *Client) UpdateOutreachTask(ctx context.Context, creds OutreachCredentials, key string, req *UpdateOutreachTaskRequest) (*OutreachTask, error) {
+	path := "/api/outreach/tasks/" + url.PathEscape(key)
+
+	var out ApiResponse[OutreachTask]
+	if err := c.NewRequest(ctx, http.MethodPut, path, req, &out).WithClientCredentials(creds.ClientId, creds.ClientSecret).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to update task: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error updating task (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// DeleteOutreachTask unschedules and deletes an outreach task
+func (c *Client) DeleteOutreachTask(ctx context.Context, creds OutreachCredentials, key string) error {
+	path := "/api/outreach/tasks/" + url.PathEscape(key)
+
+	var out ApiResponse[any]
+	if err := c.NewRequest(ctx, http.MethodDelete, path, nil, &out).WithClientCredentials(creds.ClientId, creds.ClientSecret).doJSON(); err != nil {
+		return err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return fmt.Errorf("failed to delete task: %s", out.Message)
+	case api_types.StatusError:
+		return fmt.Errorf("error deleting task (%s): %v", out.Message, out.Error)
+	}
+
+	return nil
+}
+
+// PauseOutreachTask stops an outreach task from running until it is resumed
+func (c *Client) PauseOutreachTask(ctx context.Context, creds OutreachCredentials, key string) (*OutreachTask, error) {
+	path := "/api/outreach/tasks/" + url.PathEscape(key) + "/pause"
+
+	var out ApiResponse[OutreachTask]
+	if err := c.NewRequest(ctx, http.MethodPost, path, nil, &out).WithClientCredentials(creds.ClientId, creds.ClientSecret).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to pause task: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error pausing task (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// ResumeOutreachTask schedules a paused outreach task again
+func (c *Client) ResumeOutreachTask(ctx context.Context, creds OutreachCredentials, key string) (*OutreachTask, error) {
+	path := "/api/outreach/tasks/" + url.PathEscape(key) + "/resume"
+
+	var out ApiResponse[OutreachTask]
+	if err := c.NewRequest(ctx, http.MethodPost, path, nil, &out).WithClientCredentials(creds.ClientId, creds.ClientSecret).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to resume task: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error resuming task (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// RunOutreachTask runs an outreach task immediately. With preview set, the output is returned without being delivered to clients.
+func (c *Client) RunOutreachTask(ctx context.Context, creds OutreachCredentials, key string, req *RunOutreachTaskRequest) (*RunOutreachTaskResponse, error) {
+	query := url.Values{}
+	if req.Preview {
+		query.Set("preview", "true")
+	}
+	path := "/api/outreach/tasks/" + url.PathEscape(key) + "/run?" + query.Encode()
+
+	var out ApiResponse[RunOutreachTaskResponse]
+	if err := c.NewRequest(ctx, http.MethodPost, path, nil, &out).WithClientCredentials(creds.ClientId, creds.ClientSecret).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to run task: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error running task (%s): %v", out.Message, out.Error)
+	}
+
+	return &out.Data, nil
+}
+
+// GetOutreachTaskRuns retrieves an outreach task's most recent runs, newest first
+func (c *Client) GetOutreachTaskRuns(ctx context.Context, creds OutreachCredentials, key string) ([]OutreachTaskRun, error) {
+	path := "/api/outreach/tasks/" + url.PathEscape(key) + "/runs"
+
+	var out ApiResponse[[]OutreachTaskRun]
+	if err := c.NewRequest(ctx, http.MethodGet, path, nil, &out).WithClientCredentials(creds.ClientId, creds.ClientSecret).doJSON(); err != nil {
+		return nil, err
+	}
+
+	// Check for success
+	switch out.Status {
+	case api_types.StatusFail:
+		return nil, fmt.Errorf("failed to get task runs: %s", out.Message)
+	case api_types.StatusError:
+		return nil, fmt.Errorf("error getting task runs (%s): %v", out.Message, out.Error)
+	}
+
+	return out.Data, nil
+}
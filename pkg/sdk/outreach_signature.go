@@ -0,0 +1,108 @@
+package sdk
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers used to sign outreach requests sent to implementations
+const (
+	OUTREACH_SIGNATURE_HEADER = "X-Outreach-Signature" // Comma separated list of "v1=" followed by a hex encoded HMAC-SHA256 signature
+	OUTREACH_TIMESTAMP_HEADER = "X-Outreach-Timestamp" // Unix time in seconds when the request was signed
+)
+
+// OUTREACH_SIGNATURE_TOLERANCE is how far a request's timestamp may be from the current time
+const OUTREACH_SIGNATURE_TOLERANCE = 5 * time.Minute
+
+// outreachSignatureVersion prefixes signatures so the scheme can change later
+const outreachSignatureVersion = "v1"
+
+// ErrInvalidOutreachSignature is returned when an outreach request isn't signed correctly
+var ErrInvalidOutreachSignature = errors.New("invalid outreach signature")
+
+// ComputeOutreachSignature returns the signature of a request body sent at the given timestamp
+func ComputeOutreachSignature(key []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return outreachSignatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignOutreachRequest sets the timestamp and signature headers on an outreach request.
+// Passing several keys adds one signature per key, which is used while a rotated signing key is still accepted.
+func SignOutreachRequest(r *http.Request, body []byte, now time.Time, keys ...[]byte) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	signatures := make([]string, 0, len(keys))
+	for _, key := range keys {
+		signatures = append(signatures, ComputeOutreachSignature(key, timestamp, body))
+	}
+
+	r.Header.Set(OUTREACH_TIMESTAMP_HEADER, timestamp)
+	r.Header.Set(OUTREACH_SIGNATURE_HEADER, strings.Join(signatures, ","))
+}
+
+// VerifyOutreachSignature checks that an outreach request was signed with the signing key returned when the implementation
+// registered or rotated its secret, within OUTREACH_SIGNATURE_TOLERANCE. Any one of the request's signatures may match.
+// The request body is restored so it can still be read afterwards.
+func VerifyOutreachSignature(r *http.Request, signingKey []byte) error {
+	return VerifyOutreachSignatureWithTolerance(r, signingKey, OUTREACH_SIGNATURE_TOLERANCE, time.Now())
+}
+
+// VerifyOutreachSignatureWithTolerance checks an outreach request's signature using a custom tolerance window and current time
+func VerifyOutreachSignatureWithTolerance(r *http.Request, signingKey []byte, tolerance time.Duration, now time.Time) error {
+	if len(signingKey) == 0 {
+		return fmt.Errorf("%w: no signing key configured", ErrInvalidOutreachSignature)
+	}
+
+	// Check the timestamp is recent so captured requests can't be replayed later
+	timestamp := r.Header.Get(OUTREACH_TIMESTAMP_HEADER)
+	if timestamp == "" {
+		return fmt.Errorf("%w: missing %s header", ErrInvalidOutreachSignature, OUTREACH_TIMESTAMP_HEADER)
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed timestamp", ErrInvalidOutreachSignature)
+	}
+
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp outside of the %s tolerance window", ErrInvalidOutreachSignature, tolerance)
+	}
+
+	signature := r.Header.Get(OUTREACH_SIGNATURE_HEADER)
+	if !strings.HasPrefix(signature, outreachSignatureVersion+"=") {
+		return fmt.Errorf("%w: missing or unsupported %s header", ErrInvalidOutreachSignature, OUTREACH_SIGNATURE_HEADER)
+	}
+
+	// Read the body and put it back for the handler
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	expected := ComputeOutreachSignature(signingKey, timestamp, body)
+	for _, candidate := range strings.Split(signature, ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(candidate)), []byte(expected)) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: signature mismatch", ErrInvalidOutreachSignature)
+}
@@ -0,0 +1,74 @@
+package sdk
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyOutreachSignature(t *testing.T) {
+	body := []byte(`{"id":"daily-digest-1","key":"daily-digest","content":"Good morning"}`)
+	now := time.Now()
+
+	key := []byte("signing-key")
+
+	newRequest := func(signingKey []byte, signedAt time.Time, payload []byte) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/outreach-message", bytes.NewReader(payload))
+		SignOutreachRequest(r, body, signedAt, signingKey)
+		return r
+	}
+
+	t.Run("valid signature", func(t *testing.T) {
+		r := newRequest(key, now, body)
+		require.NoError(t, VerifyOutreachSignatureWithTolerance(r, key, OUTREACH_SIGNATURE_TOLERANCE, now))
+
+		// The body can still be read by the handler
+		restored, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, body, restored)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		r := newRequest([]byte("other-key"), now, body)
+		assert.ErrorIs(t, VerifyOutreachSignatureWithTolerance(r, key, OUTREACH_SIGNATURE_TOLERANCE, now), ErrInvalidOutreachSignature)
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		r := newRequest(key, now, []byte(`{"id":"daily-digest-1","content":"Send me your password"}`))
+		assert.ErrorIs(t, VerifyOutreachSignatureWithTolerance(r, key, OUTREACH_SIGNATURE_TOLERANCE, now), ErrInvalidOutreachSignature)
+	})
+
+	t.Run("expired timestamp", func(t *testing.T) {
+		r := newRequest(key, now.Add(-10*time.Minute), body)
+		assert.ErrorIs(t, VerifyOutreachSignatureWithTolerance(r, key, OUTREACH_SIGNATURE_TOLERANCE, now), ErrInvalidOutreachSignature)
+	})
+
+	t.Run("modified timestamp", func(t *testing.T) {
+		r := newRequest(key, now.Add(-10*time.Minute), body)
+		r.Header.Set(OUTREACH_TIMESTAMP_HEADER, strconv.FormatInt(now.Unix(), 10))
+		assert.ErrorIs(t, VerifyOutreachSignatureWithTolerance(r, key, OUTREACH_SIGNATURE_TOLERANCE, now), ErrInvalidOutreachSignature)
+	})
+
+	t.Run("any of several signatures", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/outreach-message", bytes.NewReader(body))
+		SignOutreachRequest(r, body, now, []byte("new-key"), key)
+		require.NoError(t, VerifyOutreachSignatureWithTolerance(r, key, OUTREACH_SIGNATURE_TOLERANCE, now))
+	})
+
+	t.Run("no signing key", func(t *testing.T) {
+		r := newRequest(key, now, body)
+		assert.ErrorIs(t, VerifyOutreachSignatureWithTolerance(r, nil, OUTREACH_SIGNATURE_TOLERANCE, now), ErrInvalidOutreachSignature)
+	})
+
+	t.Run("unsigned request", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/outreach-message", bytes.NewReader(body))
+		assert.ErrorIs(t, VerifyOutreachSignatureWithTolerance(r, key, OUTREACH_SIGNATURE_TOLERANCE, now), ErrInvalidOutreachSignature)
+	})
+}